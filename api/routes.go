@@ -1,29 +1,99 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/chai-rs/simple-bookstore/config"
 	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
 	"github.com/chai-rs/simple-bookstore/infrastructure/db"
 	"github.com/chai-rs/simple-bookstore/infrastructure/limiter"
+	"github.com/chai-rs/simple-bookstore/infrastructure/mailer"
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/chai-rs/simple-bookstore/infrastructure/oidc"
+	counter "github.com/chai-rs/simple-bookstore/infrastructure/quota"
+	"github.com/chai-rs/simple-bookstore/internal/apikey"
+	"github.com/chai-rs/simple-bookstore/internal/audit"
 	"github.com/chai-rs/simple-bookstore/internal/book"
+	"github.com/chai-rs/simple-bookstore/internal/health"
 	"github.com/chai-rs/simple-bookstore/internal/middleware"
+	"github.com/chai-rs/simple-bookstore/internal/oauth"
+	"github.com/chai-rs/simple-bookstore/internal/policy"
+	"github.com/chai-rs/simple-bookstore/internal/quota"
+	"github.com/chai-rs/simple-bookstore/internal/settings"
 	"github.com/chai-rs/simple-bookstore/internal/user"
+	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/chai-rs/simple-bookstore/pkg/password"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 )
 
-// BindRoutes registers all API routes to the given router
-func BindRoutes(router *gin.Engine, enforcer auth.AuthEnforcer) {
+// BindRoutes registers all API routes to the given router. Tokens are signed with keys and passwords
+// hashed with passwords.
+func BindRoutes(router *gin.Engine, manager *config.Manager, enforcer auth.AuthEnforcer, rdb *redis.Client, keys *auth.Keys, passwords *crypto.PasswordHasher) {
+	cfg := manager.Current()
 	api := router.Group("/api")
-	api.Use(middleware.RateLimitMiddleware(limiter.NewMemoryLimiter(config.LIMIT_RATE)))
+	api.Use(middleware.ClientInfoMiddleware())
+
+	recorder := audit.NewMeteredRecorder(audit.NewRecorder(audit.NewRepository(db.PostgreSQL())))
+	enforcer = audit.NewEnforcer(enforcer, recorder)
 
-	authorized := api.Group("", middleware.AuthMiddleware())
-	unauthorized := api.Group("")
+	// Every request is limited per IP, before authentication so that guessing tokens and API keys is
+	// limited too. Authenticated requests are also limited per user or API key, and held to the quotas
+	// of their plan. Endpoints checking credentials or sending mail get a stricter limit against
+	// guessing and spam. Both rates are reloadable.
+	limit := newLimiter(cfg, rdb, cfg.Limit.Rate)
+	strict := newLimiter(cfg, rdb, cfg.Limit.RateAuth)
+	manager.OnReload("rate limits", func(cfg *config.Config) error {
+		return errors.Join(limit.SetRate(cfg.Limit.Rate), strict.SetRate(cfg.Limit.RateAuth))
+	})
+
+	tokenManager := auth.NewTokenManager(keys)
+	quotas := newQuotaService(cfg, rdb)
+	apiKeys := apikey.NewService(apikey.NewRepository(db.PostgreSQL()), enforcer)
+	perIP := middleware.RateLimitMiddleware(limit)
+	authorized := api.Group("",
+		perIP,
+		middleware.AuthMiddleware(tokenManager, &middleware.AuthOpts{
+			Tokens:  auth.NewRedisAuth(rdb),
+			APIKeys: apiKeys,
+		}),
+		middleware.RateLimitMiddleware(limit, &middleware.RateLimitOpts{Policy: "caller", Key: middleware.KeyByCaller}),
+		middleware.QuotaMiddleware(quotas),
+	)
+	unauthorized := api.Group("", perIP)
 
 	bindBookRoutes(authorized, enforcer)
-	bindUserRoutes(authorized, unauthorized, enforcer)
+	bindUserRoutes(authorized, unauthorized, manager, enforcer, rdb, recorder, strict, keys, passwords)
+	bindOAuthRoutes(authorized, unauthorized, enforcer, rdb, strict, keys, passwords)
+	bindAPIKeyRoutes(authorized, enforcer, apiKeys)
+	bindPolicyRoutes(authorized, enforcer)
+	bindAuditRoutes(authorized, enforcer)
+	bindQuotaRoutes(authorized, enforcer, quotas)
+	bindSettingsRoutes(authorized, enforcer, manager)
+}
+
+// BindHealthRoutes registers the liveness and readiness probes at the root of the router,
+// outside /api, so probes are neither authenticated nor rate limited
+func BindHealthRoutes(router *gin.Engine, service health.Service) {
+	hdl := health.NewHandler(service)
+
+	router.GET("/healthz", hdl.Liveness)
+	router.GET("/readyz", hdl.Readiness)
+}
+
+// BindMetricsRoutes serves the Prometheus metrics at /metrics, outside /api like the health probes.
+// Keep it reachable by the scraper only, e.g. by not routing it through the public load balancer.
+func BindMetricsRoutes(router *gin.Engine) {
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 }
 
-// bindBookRoutes registers all book-related routes to the API router group
+// bindBookRoutes registers all book-related routes to the API router group.
+// Every role may read books, only editors and admins may change them.
 func bindBookRoutes(api *gin.RouterGroup, enforcer auth.AuthEnforcer) {
 	router := api.Group("/books")
 	hdl := book.NewHandler(book.NewService(book.NewRepository(db.PostgreSQL())))
@@ -36,25 +106,257 @@ func bindBookRoutes(api *gin.RouterGroup, enforcer auth.AuthEnforcer) {
 }
 
 // bindUserRoutes registers all user-related routes to the API router group
-func bindUserRoutes(authorized, unauthorized *gin.RouterGroup, enforcer auth.AuthEnforcer) {
+func bindUserRoutes(authorized, unauthorized *gin.RouterGroup, manager *config.Manager, enforcer auth.AuthEnforcer, rdb *redis.Client, recorder audit.Recorder, limit *limiter.Limiter, keys *auth.Keys, passwords *crypto.PasswordHasher) {
+	cfg := manager.Current()
 	hdl := user.NewHandler(
 		user.NewService(
 			user.NewRepository(db.PostgreSQL()),
-			auth.NewRedisAuth(db.Redis()),
-			auth.NewTokenManager(),
+			auth.NewRedisAuth(rdb),
+			auth.NewTokenManager(keys),
 			enforcer,
+			&user.ServiceOpts{
+				Mailer:                   newMailer(manager),
+				OneTimeTokens:            auth.NewRedisOneTimeTokens(rdb, keys),
+				LoginAttempts:            auth.NewRedisLoginAttempts(rdb),
+				PasswordPolicy:           newPasswordPolicy(&cfg.Password),
+				PasswordHasher:           passwords,
+				OIDCProviders:            newOIDCProviders(manager),
+				OIDCStates:               oidc.NewRedisStateStore(rdb),
+				Recorder:                 recorder,
+				AppURL:                   cfg.AppURL,
+				TOTPIssuer:               cfg.Account.TOTPIssuer,
+				RequireEmailVerification: cfg.Account.RequireEmailVerification,
+			},
 		),
 	)
 
+	strict := middleware.RateLimitMiddleware(limit, &middleware.RateLimitOpts{Policy: "auth"})
+	registrationOpen := middleware.FeatureGate(func() bool {
+		return !manager.Current().FeatureEnabled(config.ClosedRegistrationFeature)
+	}, "registration is closed")
+
 	{
 		router := unauthorized.Group("/users")
-		router.POST("/login", hdl.Login)
-		router.POST("/register", hdl.Register)
+		router.POST("/login", strict, hdl.Login)
+		router.POST("/login/totp", strict, hdl.LoginTOTP)
+		router.POST("/register", strict, registrationOpen, hdl.Register)
 		router.POST("/refresh", hdl.RefreshToken)
+		router.POST("/password/forgot", strict, hdl.ForgotPassword)
+		router.POST("/password/reset", strict, hdl.ResetPassword)
+		router.POST("/email/verify", hdl.VerifyEmail)
+		router.POST("/email/verify/resend", strict, hdl.SendEmailVerification)
+		router.POST("/email/change/confirm", hdl.ConfirmEmailChange)
+		router.GET("/oauth/:provider/login", hdl.OIDCLogin)
+		router.GET("/oauth/:provider/callback", hdl.OIDCCallback)
 	}
 
 	{
-		router := authorized.Group("/users")
+		router := authorized.Group("/users", middleware.UserTokenOnly())
 		router.POST("/logout", hdl.Logout)
+		router.POST("/totp/enroll", hdl.EnrollTOTP)
+		router.POST("/totp/activate", hdl.ActivateTOTP)
+		router.POST("/totp/disable", hdl.DisableTOTP)
+		router.GET("/me", hdl.GetProfile)
+		router.PATCH("/me", hdl.UpdateProfile)
+		router.DELETE("/me", hdl.DeleteAccount)
+		router.POST("/me/password", hdl.ChangePassword)
+		router.POST("/me/email", hdl.ChangeEmail)
+	}
+
+	{
+		router := authorized.Group("/admin/users", middleware.UserTokenOnly())
+		router.GET("", middleware.Authorize(auth.Users, auth.Read, enforcer), hdl.ListUsers)
+		router.GET("/:id", middleware.Authorize(auth.Users, auth.Read, enforcer), hdl.GetUser)
+		router.POST("/:id/disable", middleware.Authorize(auth.Users, auth.Write, enforcer), hdl.DisableUser)
+		router.POST("/:id/enable", middleware.Authorize(auth.Users, auth.Write, enforcer), hdl.EnableUser)
+		router.POST("/:id/password/reset", middleware.Authorize(auth.Users, auth.Write, enforcer), hdl.ForcePasswordReset)
+		router.GET("/:id/roles", middleware.Authorize(auth.Users, auth.Read, enforcer), hdl.GetRoles)
+		router.POST("/:id/roles", middleware.Authorize(auth.Users, auth.Write, enforcer), hdl.GrantRole)
+		router.DELETE("/:id/roles/:role", middleware.Authorize(auth.Users, auth.Write, enforcer), hdl.RevokeRole)
+	}
+}
+
+// bindOAuthRoutes registers the OAuth2 authorization server routes to the API router group.
+// The endpoints authenticating clients get the same strict limit as the login endpoints.
+func bindOAuthRoutes(authorized, unauthorized *gin.RouterGroup, enforcer auth.AuthEnforcer, rdb *redis.Client, limit *limiter.Limiter, keys *auth.Keys, passwords *crypto.PasswordHasher) {
+	hdl := oauth.NewHandler(
+		oauth.NewService(
+			oauth.NewRepository(db.PostgreSQL()),
+			auth.NewRedisAuth(rdb),
+			auth.NewTokenManager(keys),
+			enforcer,
+			&oauth.ServiceOpts{
+				AuthorizationCodes: auth.NewRedisAuthorizationCodes(rdb),
+				PasswordHasher:     passwords,
+			},
+		),
+	)
+
+	strict := middleware.RateLimitMiddleware(limit, &middleware.RateLimitOpts{Policy: "auth"})
+
+	{
+		router := unauthorized.Group("/oauth")
+		router.POST("/token", strict, hdl.Token)
+		router.POST("/introspect", strict, hdl.Introspect)
+		router.POST("/revoke", strict, hdl.Revoke)
+	}
+
+	{
+		router := authorized.Group("/oauth", middleware.UserTokenOnly())
+		router.GET("/authorize", hdl.Authorize)
+		router.POST("/authorize", hdl.Consent)
+		router.POST("/clients", hdl.RegisterClient)
+		router.GET("/clients", hdl.ListClients)
+		router.DELETE("/clients/:id", hdl.DeleteClient)
+		router.GET("/consents", hdl.ListConsents)
+		router.DELETE("/consents/:client_id", hdl.RevokeConsent)
+	}
+}
+
+// bindAPIKeyRoutes registers the personal API key routes to the API router group
+func bindAPIKeyRoutes(authorized *gin.RouterGroup, enforcer auth.AuthEnforcer, service apikey.Service) {
+	hdl := apikey.NewHandler(service)
+
+	router := authorized.Group("/api-keys", middleware.UserTokenOnly())
+	router.POST("", hdl.CreateKey)
+	router.GET("", hdl.ListKeys)
+	router.DELETE("/:id", middleware.AuthorizeResource(auth.Write, enforcer, hdl.LoadKey), hdl.RevokeKey)
+}
+
+// bindPolicyRoutes registers the policy administration routes to the API router group
+func bindPolicyRoutes(authorized *gin.RouterGroup, enforcer auth.AuthEnforcer) {
+	hdl := policy.NewHandler(policy.NewService(enforcer))
+
+	router := authorized.Group("/admin", middleware.UserTokenOnly())
+	router.GET("/policies", middleware.Authorize(auth.Policies, auth.Read, enforcer), hdl.ListPolicies)
+	router.POST("/policies", middleware.Authorize(auth.Policies, auth.Write, enforcer), hdl.AddPolicy)
+	router.DELETE("/policies", middleware.Authorize(auth.Policies, auth.Write, enforcer), hdl.RemovePolicy)
+	router.GET("/groupings", middleware.Authorize(auth.Policies, auth.Read, enforcer), hdl.ListGroupings)
+	router.POST("/groupings", middleware.Authorize(auth.Policies, auth.Write, enforcer), hdl.AddGrouping)
+	router.DELETE("/groupings", middleware.Authorize(auth.Policies, auth.Write, enforcer), hdl.RemoveGrouping)
+	router.POST("/authz/explain", middleware.Authorize(auth.Policies, auth.Read, enforcer), hdl.Explain)
+}
+
+// bindAuditRoutes registers the audit log routes to the API router group
+func bindAuditRoutes(authorized *gin.RouterGroup, enforcer auth.AuthEnforcer) {
+	hdl := audit.NewHandler(audit.NewService(audit.NewRepository(db.PostgreSQL())))
+
+	router := authorized.Group("/admin", middleware.UserTokenOnly())
+	router.GET("/audit-events", middleware.Authorize(auth.AuditEvents, auth.Read, enforcer), hdl.ListEvents)
+}
+
+// bindQuotaRoutes registers the quota usage and plan routes to the API router group
+func bindQuotaRoutes(authorized *gin.RouterGroup, enforcer auth.AuthEnforcer, service quota.Service) {
+	hdl := quota.NewHandler(service)
+
+	authorized.GET("/users/me/usage", middleware.UserTokenOnly(), hdl.GetUsage)
+	authorized.PUT("/admin/users/:id/plan", middleware.UserTokenOnly(), middleware.Authorize(auth.Users, auth.Write, enforcer), hdl.SetPlan)
+}
+
+// bindSettingsRoutes registers the configuration reload routes to the API router group
+func bindSettingsRoutes(authorized *gin.RouterGroup, enforcer auth.AuthEnforcer, manager *config.Manager) {
+	hdl := settings.NewHandler(manager)
+
+	router := authorized.Group("/admin/config", middleware.UserTokenOnly())
+	router.GET("/reloads", middleware.Authorize(auth.Settings, auth.Read, enforcer), hdl.ListReloads)
+	router.POST("/reload", middleware.Authorize(auth.Settings, auth.Write, enforcer), hdl.Reload)
+}
+
+// newQuotaService returns the quota service, counting in Redis when the limit store is redis, and starts
+// copying the counts to the database. Counts left in Redis at shutdown are copied by the next flush.
+func newQuotaService(cfg *config.Config, rdb *redis.Client) quota.Service {
+	opts := &quota.ServiceOpts{}
+	if cfg.Limit.Store == config.RedisStore {
+		opts.Counter = counter.NewRedisCounter(rdb)
+	}
+
+	service := quota.NewService(quota.NewRepository(db.PostgreSQL()), opts)
+	go service.Run(context.Background(), quota.FlushInterval)
+
+	return service
+}
+
+// newLimiter returns a limiter for rate, shared between instances through Redis when the limit store is redis
+func newLimiter(cfg *config.Config, rdb *redis.Client, rate string) *limiter.Limiter {
+	if cfg.Limit.Store == config.RedisStore {
+		return limiter.NewRedisLimiter(rdb, rate)
+	}
+
+	return limiter.NewMemoryLimiter(rate)
+}
+
+// newMailer returns an SMTP mailer when SMTP is configured, otherwise an in-memory one
+func newMailer(manager *config.Manager) mailer.Mailer {
+	cfg := &manager.Current().SMTP
+	if cfg.Host == "" {
+		log.Warn().Msg("⚠️ SMTP host is not set, emails will only be kept in memory")
+		return mailer.NewMemoryMailer()
+	}
+
+	return mailer.NewSMTPMailer(&mailer.SMTPMailerOpts{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: func() string { return manager.Current().SMTP.Password.Reveal() },
+		From:     cfg.From,
+	})
+}
+
+// newPasswordPolicy builds the password policy from config, loading the breached password list if configured
+func newPasswordPolicy(cfg *config.PasswordConfig) *password.Policy {
+	policy := &password.Policy{
+		MinLength:  cfg.MinLength,
+		MaxLength:  password.DefaultPolicy.MaxLength,
+		MinClasses: cfg.MinClasses,
+		MinScore:   cfg.MinScore,
 	}
+
+	// bcrypt rejects passwords past 72 bytes, which a password of fewer characters can reach.
+	if cfg.HashAlgorithm == crypto.Bcrypt {
+		policy.MaxBytes = crypto.BcryptMaxPasswordBytes
+	}
+
+	if cfg.BreachedFile != "" {
+		breached, err := password.LoadBreachedList(cfg.BreachedFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("💣 failed to load breached password list")
+		}
+
+		log.Info().Int("hashes", breached.Len()).Msg("🔐 loaded breached password list")
+		policy.Breached = breached
+	}
+
+	return policy
+}
+
+// newOIDCProviders discovers the configured OpenID Connect providers. Providers that fail
+// discovery are skipped so an outage at one of them does not stop the server from starting.
+func newOIDCProviders(manager *config.Manager) map[string]*oidc.Provider {
+	cfg := manager.Current()
+	providers := map[string]*oidc.Provider{}
+	for i, configured := range cfg.OIDC.Providers {
+		name := configured.Name
+		providerConfig := &oidc.ProviderConfig{
+			Name:      name,
+			IssuerURL: configured.Issuer,
+			ClientID:  configured.ClientID,
+			// Providers are only reloaded in place, so the provider keeps its index.
+			ClientSecret: func() string { return manager.Current().OIDC.Providers[i].ClientSecret.Reveal() },
+			RedirectURL:  fmt.Sprintf("%s/api/users/oauth/%s/callback", strings.TrimSuffix(cfg.AppURL, "/"), name),
+			Scopes:       configured.Scopes,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		provider, err := oidc.NewProvider(ctx, providerConfig)
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Str("provider", name).Msg("🚨 failed to setup oidc provider")
+			continue
+		}
+
+		log.Info().Str("provider", name).Msg("🔐 enabled oidc provider")
+		providers[name] = provider
+	}
+
+	return providers
 }
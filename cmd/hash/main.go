@@ -9,6 +9,11 @@ import (
 
 func main() {
 	v := flag.String("v", "", "value to hash")
+	algorithm := flag.String("algo", crypto.Argon2id, "hash algorithm (argon2id or bcrypt)")
+	memory := flag.Uint("m", uint(crypto.DefaultArgon2idParams.Memory), "argon2id memory in KiB")
+	iterations := flag.Uint("t", uint(crypto.DefaultArgon2idParams.Iterations), "argon2id iterations")
+	parallelism := flag.Uint("p", uint(crypto.DefaultArgon2idParams.Parallelism), "argon2id parallelism")
+	cost := flag.Int("cost", 10, "bcrypt cost")
 	flag.Parse()
 
 	if *v == "" {
@@ -16,7 +21,19 @@ func main() {
 		return
 	}
 
-	hashedPassword, err := crypto.HashPassword(*v)
+	hasher, err := crypto.NewHasher(*algorithm, &crypto.Argon2idParams{
+		Memory:      uint32(*memory),
+		Iterations:  uint32(*iterations),
+		Parallelism: uint8(*parallelism),
+		SaltLength:  crypto.DefaultArgon2idParams.SaltLength,
+		KeyLength:   crypto.DefaultArgon2idParams.KeyLength,
+	}, *cost)
+	if err != nil {
+		fmt.Println("failed to create hasher:", err)
+		return
+	}
+
+	hashedPassword, err := hasher.Hash(*v)
 	if err != nil {
 		fmt.Println("failed to hash password:", err)
 		return
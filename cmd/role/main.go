@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/infrastructure/db"
+	_ "github.com/chai-rs/simple-bookstore/infrastructure/logger"
+	"github.com/chai-rs/simple-bookstore/internal/audit"
+	"github.com/chai-rs/simple-bookstore/internal/user"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+)
+
+// role assigns roles from the command line, e.g. to bootstrap the first admin.
+func main() {
+	email := flag.String("email", "", "email of the user")
+	name := flag.String("role", auth.Admin.String(), "role to grant (customer, editor or admin)")
+	revoke := flag.Bool("revoke", false, "revoke the role instead of granting it")
+	flag.Parse()
+
+	if *email == "" {
+		fmt.Println("-email flag is required")
+		return
+	}
+
+	role, err := auth.ParseRole(*name)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	cfg := config.Init()
+	db.PostgreSQLConnect(
+		cfg.Postgres.Host,
+		cfg.Postgres.Port,
+		cfg.Postgres.User,
+		cfg.Postgres.DB,
+		cfg.Postgres.Password.Reveal,
+	)
+
+	u, err := user.NewRepository(db.PostgreSQL()).GetByEmail(context.Background(), *email)
+	if err != nil {
+		fmt.Println("failed to find user:", err)
+		return
+	}
+
+	// Role changes made here are audited like those made through the API.
+	ctx := utils.WithClientInfo(context.Background(), utils.ClientInfo{UserAgent: "cmd/role"})
+	recorder := audit.NewRecorder(audit.NewRepository(db.PostgreSQL()))
+	enforcer := audit.NewEnforcer(auth.NewAuthEnforcer(auth.GormAdapter(db.PostgreSQL())), recorder)
+	if *revoke {
+		err = enforcer.DeleteRoleForUser(ctx, u.ID.String(), role)
+	} else {
+		err = enforcer.AddRoleForUser(ctx, u.ID.String(), role)
+	}
+	if err != nil {
+		fmt.Println("failed to update role:", err)
+		return
+	}
+
+	roles, _ := enforcer.GetRolesForUser(u.ID.String())
+	fmt.Printf("%s roles: %v\n", *email, roles)
+}
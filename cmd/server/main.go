@@ -6,37 +6,62 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
+	"github.com/casbin/casbin/v2/persist"
 	"github.com/chai-rs/simple-bookstore/api"
 	"github.com/chai-rs/simple-bookstore/config"
 	"github.com/chai-rs/simple-bookstore/docs"
 	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
 	"github.com/chai-rs/simple-bookstore/infrastructure/db"
-	_ "github.com/chai-rs/simple-bookstore/infrastructure/logger"
+	"github.com/chai-rs/simple-bookstore/infrastructure/logger"
+	"github.com/chai-rs/simple-bookstore/infrastructure/tracing"
+	"github.com/chai-rs/simple-bookstore/internal/health"
+	"github.com/chai-rs/simple-bookstore/internal/middleware"
+	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/chai-rs/simple-bookstore/pkg/migration"
 	eval "github.com/chai-rs/simple-bookstore/pkg/validator"
-	"github.com/gin-contrib/cors"
-	"github.com/gin-contrib/logger"
+	ginlogger "github.com/gin-contrib/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// migrationsDir holds the migrations the database is expected to be migrated to
+const migrationsDir = "db/migrations"
+
+// cfg is the configuration the server started with, manager holds the running one.
+var (
+	cfg     *config.Config
+	manager *config.Manager
 )
 
 func init() {
-	config.Init()
+	cfg = config.Init()
+	logger.RedactFields(cfg.RedactFields...)
+	if cfg.Mode == config.ProductionMode {
+		logger.UseJSON()
+	}
+	manager = config.NewManager(cfg, os.Getenv(config.FileEnv))
 	db.PostgreSQLConnect(
-		config.POSTGRES_HOST,
-		config.POSTGRES_PORT,
-		config.POSTGRES_USER,
-		config.POSTGRES_PASSWORD,
-		config.POSTGRES_DB,
+		cfg.Postgres.Host,
+		cfg.Postgres.Port,
+		cfg.Postgres.User,
+		cfg.Postgres.DB,
+		func() string { return manager.Current().Postgres.Password.Reveal() },
 	)
 
+	if err := logger.SetLevel(cfg.LogLevel); err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to set log level")
+	}
+
 	setupValidator()
 }
 
@@ -44,28 +69,46 @@ func main() {
 	// Setup gin engine
 	app := setupGin()
 
-	// Setup middleware
-	app.Use(logger.SetLogger())
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     strings.Split(config.CORS_ALLOWED_ORIGINS, ","),
-		AllowMethods:     strings.Split(config.CORS_ALLOWED_METHODS, ","),
-		AllowHeaders:     strings.Split(config.CORS_ALLOWED_HEADERS, ","),
-		ExposeHeaders:    strings.Split(config.CORS_EXPOSED_HEADERS, ","),
-		MaxAge:           time.Duration(config.CORS_MAX_AGE),
-		AllowCredentials: true,
-	}))
+	// Reload the log level, rate limits, CORS lists and feature flags on SIGHUP and config file changes,
+	// and pick up rotated secrets. Database and Redis passwords are read for every new connection,
+	// the SMTP password for every message and OIDC client secrets for every code exchange.
+	manager.OnReload("log level", func(cfg *config.Config) error {
+		return logger.SetLevel(cfg.LogLevel)
+	})
+	keys := auth.NewKeys(cfg.JWT.AccessSecret.Reveal(), cfg.JWT.RefreshSecret.Reveal())
+	manager.OnReload("jwt secrets", func(cfg *config.Config) error {
+		keys.Set(cfg.JWT.AccessSecret.Reveal(), cfg.JWT.RefreshSecret.Reveal())
+		return nil
+	})
+	go manager.Run(context.Background(), config.WatchInterval, config.SecretRefreshInterval)
+
+	// Setup tracing
+	shutdownTracing := setupTracing()
+
+	// Setup middleware. Requests are logged with the request logger, carrying the request and trace IDs
+	app.Use(middleware.TracingMiddleware())
+	app.Use(middleware.RequestIDMiddleware())
+	app.Use(ginlogger.SetLogger(
+		ginlogger.WithLogger(func(c *gin.Context, _ zerolog.Logger) zerolog.Logger { return *log.Ctx(c.Request.Context()) }),
+	))
+	app.Use(middleware.MetricsMiddleware())
+	app.Use(setupCORS())
 
 	// Setup enforcer
-	enforcer := auth.NewAuthEnforcer(auth.GormAdapter(db.PostgreSQL()))
+	rdb := db.Redis(cfg.Redis.Host, cfg.Redis.Port, func() string { return manager.Current().Redis.Password.Reveal() }, cfg.Redis.DB)
+	enforcer := setupEnforcer(rdb)
 
 	// Bind routes
-	api.BindRoutes(app, enforcer)
+	probes := setupHealth(rdb)
+	api.BindHealthRoutes(app, probes)
+	api.BindMetricsRoutes(app)
+	api.BindRoutes(app, manager, enforcer, rdb, keys, setupHasher())
 
 	// Setup swagger
 	setupSwagger(app)
 
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", config.PORT),
+		Addr:    fmt.Sprintf(":%d", cfg.Port),
 		Handler: app.Handler(),
 	}
 
@@ -82,6 +125,10 @@ func main() {
 	<-quit
 	log.Info().Msg("👋 server is shutting down")
 
+	// Fail readiness first, so load balancers stop sending requests before the server stops accepting them
+	probes.Drain()
+	time.Sleep(time.Duration(cfg.ShutdownDrain) * time.Second)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -90,6 +137,11 @@ func main() {
 		log.Fatal().Err(err).Msg("🚨 failed to shutdown server")
 	}
 
+	// Flush the spans of the last requests
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("🚨 failed to flush traces")
+	}
+
 	// Wait for server to exit
 	<-ctx.Done()
 	log.Info().Msg("👋 server exited properly")
@@ -97,13 +149,92 @@ func main() {
 
 // Setup Gin Engine
 func setupGin() *gin.Engine {
-	if config.MODE == config.ProductionMode {
+	if cfg.Mode == config.ProductionMode {
 		gin.SetMode(gin.ReleaseMode)
 	} else {
 		gin.SetMode(gin.DebugMode)
 	}
 
-	return gin.Default()
+	engine := gin.Default()
+
+	// Only the listed proxies may set the client IP, which keys the per-IP rate limits and login lockout.
+	var proxies []string
+	if len(cfg.TrustedProxies) > 0 {
+		proxies = cfg.TrustedProxies
+	}
+	if err := engine.SetTrustedProxies(proxies); err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to set trusted proxies")
+	}
+
+	return engine
+}
+
+// Setup enforcer, sharing policy changes with other instances through Redis when the policy watcher is redis
+func setupEnforcer(rdb *redis.Client) auth.AuthEnforcer {
+	var watcher persist.Watcher = auth.NewNoopWatcher()
+	if cfg.Policy.Watcher == config.RedisWatcher {
+		redisWatcher, err := auth.NewRedisWatcher(context.Background(), rdb)
+		if err != nil {
+			log.Fatal().Err(err).Msg("💣 failed to setup policy watcher")
+		}
+
+		watcher = redisWatcher
+	}
+
+	return auth.NewAuthEnforcer(auth.GormAdapter(db.PostgreSQL()), &auth.AuthEnforcerOpts{
+		ModelPath: auth.DefaultAuthEnforcerOpts.ModelPath,
+		Watcher:   watcher,
+	})
+}
+
+// Setup CORS, replacing the allowed origins, methods and headers when the configuration is reloaded
+func setupCORS() gin.HandlerFunc {
+	cors, err := middleware.NewCORS(&cfg.CORS)
+	if err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to setup cors")
+	}
+
+	manager.OnReload("cors", func(cfg *config.Config) error {
+		return cors.Update(&cfg.CORS)
+	})
+
+	return cors.Middleware()
+}
+
+// Setup tracing, sending spans to the configured exporter. W3C trace context is propagated even when no exporter is set
+func setupTracing() func(ctx context.Context) error {
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Tracing.Exporter {
+	case config.OTLPExporter:
+		exporter, err = tracing.NewOTLPExporter(context.Background(), cfg.Tracing.Endpoint)
+	case config.StdoutExporter:
+		exporter, err = tracing.NewStdoutExporter()
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to setup tracing exporter")
+	}
+
+	return tracing.Init(exporter, &tracing.ProviderOpts{
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+}
+
+// Setup health checks, reporting the server ready once PostgreSQL and Redis answer and every known migration is applied
+func setupHealth(rdb *redis.Client) health.Service {
+	version, err := migration.LatestVersion(migrationsDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to read migrations")
+	}
+
+	return health.NewService(
+		health.PostgresCheck(db.PostgreSQL()),
+		health.RedisCheck(rdb),
+		health.MigrationCheck(db.PostgreSQL(), version),
+	)
 }
 
 // Setup swagger
@@ -112,7 +243,7 @@ func setupSwagger(app *gin.Engine) {
 	docs.SwaggerInfo.Title = "Simple Bookstore API"
 	docs.SwaggerInfo.Description = "API for managing books in a bookstore"
 	docs.SwaggerInfo.Version = "1.0"
-	docs.SwaggerInfo.Host = fmt.Sprintf("localhost:%d", config.PORT)
+	docs.SwaggerInfo.Host = fmt.Sprintf("localhost:%d", cfg.Port)
 
 	app.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
@@ -123,3 +254,23 @@ func setupValidator() {
 		v.RegisterValidation("date_valid", eval.DateValid)
 	}
 }
+
+// Setup password hasher
+func setupHasher() *crypto.PasswordHasher {
+	hasher, err := crypto.NewHasher(
+		cfg.Password.HashAlgorithm,
+		&crypto.Argon2idParams{
+			Memory:      uint32(cfg.Password.Argon2Memory),
+			Iterations:  uint32(cfg.Password.Argon2Iterations),
+			Parallelism: uint8(cfg.Password.Argon2Parallelism),
+			SaltLength:  crypto.DefaultArgon2idParams.SaltLength,
+			KeyLength:   crypto.DefaultArgon2idParams.KeyLength,
+		},
+		cfg.Password.BcryptCost,
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to setup password hasher")
+	}
+
+	return crypto.NewPasswordHasher(hasher)
+}
@@ -2,9 +2,11 @@ package config
 
 import (
 	"os"
-	"strconv"
+	"slices"
 
+	"github.com/chai-rs/simple-bookstore/pkg/crypto"
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,78 +21,229 @@ const (
 	DevelopmentMode = Mode("development")
 )
 
-var (
-	MODE       Mode
-	PORT       int
-	LIMIT_RATE string
-
-	CORS_ALLOWED_ORIGINS string
-	CORS_ALLOWED_METHODS string
-	CORS_ALLOWED_HEADERS string
-	CORS_EXPOSED_HEADERS string
-	CORS_MAX_AGE         int
-
-	REDIS_HOST     string
-	REDIS_PORT     string
-	REDIS_PASSWORD string
-	REDIS_DB       int
-
-	POSTGRES_HOST     string
-	POSTGRES_PORT     string
-	POSTGRES_USER     string
-	POSTGRES_PASSWORD string
-	POSTGRES_DB       string
-
-	ACCESS_SECRET  string
-	REFRESH_SECRET string
+const (
+	// MemoryStore keeps rate limit and quota counters in the process.
+	MemoryStore = "memory"
+	// RedisStore shares rate limit and quota counters between instances through Redis.
+	RedisStore = "redis"
+
+	// NoWatcher keeps policy changes local to the instance making them.
+	NoWatcher = "none"
+	// RedisWatcher publishes policy changes to the other instances through Redis.
+	RedisWatcher = "redis"
+
+	// OTLPExporter sends spans to an OpenTelemetry collector over HTTP.
+	OTLPExporter = "otlp"
+	// StdoutExporter writes spans to stdout.
+	StdoutExporter = "stdout"
+	// NoExporter propagates trace context without recording spans.
+	NoExporter = "none"
 )
 
-func Init() {
-	MODE = ModeEnv("MODE")
-	LIMIT_RATE = StringEnv("LIMIT_RATE")
-	PORT = IntEnv("PORT")
-
-	CORS_ALLOWED_ORIGINS = StringEnv("CORS_ALLOWED_ORIGINS")
-	CORS_ALLOWED_METHODS = StringEnv("CORS_ALLOWED_METHODS")
-	CORS_ALLOWED_HEADERS = StringEnv("CORS_ALLOWED_HEADERS")
-	CORS_EXPOSED_HEADERS = StringEnv("CORS_EXPOSED_HEADERS")
-	CORS_MAX_AGE = IntEnv("CORS_MAX_AGE")
-
-	REDIS_HOST = StringEnv("REDIS_HOST")
-	REDIS_PORT = StringEnv("REDIS_PORT")
-	REDIS_PASSWORD = StringEnv("REDIS_PASSWORD")
-	REDIS_DB = IntEnv("REDIS_DB")
-
-	POSTGRES_HOST = StringEnv("POSTGRES_HOST")
-	POSTGRES_PORT = StringEnv("POSTGRES_PORT")
-	POSTGRES_USER = StringEnv("POSTGRES_USER")
-	POSTGRES_PASSWORD = StringEnv("POSTGRES_PASSWORD")
-	POSTGRES_DB = StringEnv("POSTGRES_DB")
-
-	ACCESS_SECRET = StringEnv("ACCESS_SECRET")
-	REFRESH_SECRET = StringEnv("REFRESH_SECRET")
-}
-
-func ModeEnv(key string) Mode {
-	switch os.Getenv(key) {
-	case ProductionMode.String():
-		return ProductionMode
-	case DevelopmentMode.String():
-		return DevelopmentMode
-	default:
-		log.Fatal().Msg("🚨 mode must be prod or dev")
-		return ""
+// Feature flags, enabled by listing them in Features.
+const (
+	// ClosedRegistrationFeature stops new users from registering, e.g. to ride out a wave of spam sign-ups.
+	ClosedRegistrationFeature = "closed_registration"
+)
+
+// Config holds the application configuration. It is built from Default, then the
+// optional config file, then environment variables, each overriding the previous.
+// Fields tagged reload:"true" are applied by Manager.Reload while the server runs;
+// changing any other field requires a restart.
+type Config struct {
+	Mode     Mode     `yaml:"mode" toml:"mode" env:"MODE"`
+	Port     int      `yaml:"port" toml:"port" env:"PORT"`
+	AppURL   string   `yaml:"app_url" toml:"app_url" env:"APP_URL"`
+	LogLevel string   `yaml:"log_level" toml:"log_level" env:"LOG_LEVEL" reload:"true"`
+	Features []string `yaml:"features" toml:"features" env:"FEATURES" reload:"true"`
+	// ShutdownDrain is how many seconds readiness fails before the server stops accepting requests,
+	// giving load balancers time to send traffic elsewhere.
+	ShutdownDrain int `yaml:"shutdown_drain" toml:"shutdown_drain" env:"SHUTDOWN_DRAIN_SECONDS"`
+	// TrustedProxies lists the IPs and CIDRs of the reverse proxies allowed to set the client IP through
+	// X-Forwarded-For. With none, the client IP is always the address of the connection.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	// RedactFields names the log fields masked besides passwords, tokens, secrets and the like, which always are.
+	RedactFields []string `yaml:"redact_fields" toml:"redact_fields" env:"LOG_REDACT_FIELDS"`
+
+	Limit    LimitConfig    `yaml:"limit" toml:"limit"`
+	CORS     CORSConfig     `yaml:"cors" toml:"cors" reload:"true"`
+	Redis    RedisConfig    `yaml:"redis" toml:"redis"`
+	Postgres PostgresConfig `yaml:"postgres" toml:"postgres"`
+	JWT      JWTConfig      `yaml:"jwt" toml:"jwt"`
+	Account  AccountConfig  `yaml:"account" toml:"account"`
+	Password PasswordConfig `yaml:"password" toml:"password"`
+	OIDC     OIDCConfig     `yaml:"oidc" toml:"oidc"`
+	Policy   PolicyConfig   `yaml:"policy" toml:"policy"`
+	SMTP     SMTPConfig     `yaml:"smtp" toml:"smtp"`
+	Tracing  TracingConfig  `yaml:"tracing" toml:"tracing"`
+}
+
+// LimitConfig configures rate limiting and the quota counters. Rates are written <requests>-<S|M|H|D>.
+type LimitConfig struct {
+	Store    string `yaml:"store" toml:"store" env:"LIMIT_STORE"`
+	Rate     string `yaml:"rate" toml:"rate" env:"LIMIT_RATE" reload:"true"`
+	RateAuth string `yaml:"rate_auth" toml:"rate_auth" env:"LIMIT_RATE_AUTH" reload:"true"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods []string `yaml:"allowed_methods" toml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders []string `yaml:"allowed_headers" toml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders []string `yaml:"exposed_headers" toml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	MaxAge         int      `yaml:"max_age" toml:"max_age" env:"CORS_MAX_AGE"`
+}
+
+type RedisConfig struct {
+	Host     string `yaml:"host" toml:"host" env:"REDIS_HOST"`
+	Port     string `yaml:"port" toml:"port" env:"REDIS_PORT"`
+	Password Secret `yaml:"password" toml:"password" env:"REDIS_PASSWORD" reload:"true"`
+	DB       int    `yaml:"db" toml:"db" env:"REDIS_DB"`
+}
+
+type PostgresConfig struct {
+	Host     string `yaml:"host" toml:"host" env:"POSTGRES_HOST"`
+	Port     string `yaml:"port" toml:"port" env:"POSTGRES_PORT"`
+	User     string `yaml:"user" toml:"user" env:"POSTGRES_USER"`
+	Password Secret `yaml:"password" toml:"password" env:"POSTGRES_PASSWORD" reload:"true"`
+	DB       string `yaml:"db" toml:"db" env:"POSTGRES_DB"`
+}
+
+// JWTConfig holds the keys signing tokens. After a rotation new tokens are signed with the new keys, and
+// those signed with the previous keys are accepted until they expire, at most 30 days later.
+type JWTConfig struct {
+	AccessSecret  Secret `yaml:"access_secret" toml:"access_secret" env:"ACCESS_SECRET" reload:"true"`
+	RefreshSecret Secret `yaml:"refresh_secret" toml:"refresh_secret" env:"REFRESH_SECRET" reload:"true"`
+}
+
+type AccountConfig struct {
+	TOTPIssuer               string `yaml:"totp_issuer" toml:"totp_issuer" env:"TOTP_ISSUER"`
+	RequireEmailVerification bool   `yaml:"require_email_verification" toml:"require_email_verification" env:"REQUIRE_EMAIL_VERIFICATION"`
+}
+
+// PasswordConfig configures the password policy and hashing. Argon2Memory is in KiB and
+// BreachedFile holds one SHA-1 hash per line.
+type PasswordConfig struct {
+	MinLength         int    `yaml:"min_length" toml:"min_length" env:"PASSWORD_MIN_LENGTH"`
+	MinClasses        int    `yaml:"min_classes" toml:"min_classes" env:"PASSWORD_MIN_CLASSES"`
+	MinScore          int    `yaml:"min_score" toml:"min_score" env:"PASSWORD_MIN_SCORE"`
+	BreachedFile      string `yaml:"breached_file" toml:"breached_file" env:"BREACHED_PASSWORDS_FILE"`
+	HashAlgorithm     string `yaml:"hash_algorithm" toml:"hash_algorithm" env:"PASSWORD_HASH_ALGORITHM"`
+	Argon2Memory      int    `yaml:"argon2_memory" toml:"argon2_memory" env:"ARGON2_MEMORY"`
+	Argon2Iterations  int    `yaml:"argon2_iterations" toml:"argon2_iterations" env:"ARGON2_ITERATIONS"`
+	Argon2Parallelism int    `yaml:"argon2_parallelism" toml:"argon2_parallelism" env:"ARGON2_PARALLELISM"`
+	BcryptCost        int    `yaml:"bcrypt_cost" toml:"bcrypt_cost" env:"BCRYPT_COST"`
+}
+
+// OIDCConfig lists the social login providers. In the environment, OIDC_PROVIDERS names
+// them and each is configured with OIDC_<NAME>_* variables.
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig `yaml:"providers" toml:"providers"`
+}
+
+// OIDCProviderConfig configures one OpenID Connect provider. Its env tags are relative to OIDC_<NAME>_.
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name" toml:"name"`
+	Issuer       string   `yaml:"issuer" toml:"issuer" env:"ISSUER"`
+	ClientID     string   `yaml:"client_id" toml:"client_id" env:"CLIENT_ID"`
+	ClientSecret Secret   `yaml:"client_secret" toml:"client_secret" env:"CLIENT_SECRET" reload:"true"`
+	Scopes       []string `yaml:"scopes" toml:"scopes" env:"SCOPES"`
+}
+
+type PolicyConfig struct {
+	Watcher string `yaml:"watcher" toml:"watcher" env:"POLICY_WATCHER"`
+}
+
+// SMTPConfig configures outgoing mail. Leaving Host empty keeps mail in memory.
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host" env:"SMTP_HOST"`
+	Port     string `yaml:"port" toml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" toml:"username" env:"SMTP_USERNAME"`
+	Password Secret `yaml:"password" toml:"password" env:"SMTP_PASSWORD" reload:"true"`
+	From     string `yaml:"from" toml:"from" env:"SMTP_FROM"`
+}
+
+// TracingConfig configures OpenTelemetry tracing. An empty Endpoint falls back to OTEL_EXPORTER_OTLP_ENDPOINT.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" env:"TRACING_ENDPOINT"`
+	ServiceName string  `yaml:"service_name" toml:"service_name" env:"TRACING_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// FeatureEnabled reports whether the feature flag name is listed in Features.
+func (c *Config) FeatureEnabled(name string) bool {
+	return slices.Contains(c.Features, name)
+}
+
+// Default returns the configuration used for every value the file and environment leave unset.
+// Secrets have no default and must always be provided.
+func Default() *Config {
+	return &Config{
+		Mode:           DevelopmentMode,
+		Port:           8000,
+		AppURL:         "http://localhost:3000",
+		LogLevel:       zerolog.InfoLevel.String(),
+		Features:       []string{},
+		ShutdownDrain:  5,
+		TrustedProxies: []string{},
+		RedactFields:   []string{},
+		Limit: LimitConfig{
+			Store:    MemoryStore,
+			Rate:     "10-M",
+			RateAuth: "5-M",
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Origin", "Content-Type", "Authorization"},
+			ExposedHeaders: []string{"Authorization", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+			MaxAge:         120,
+		},
+		Redis: RedisConfig{
+			Host: "localhost",
+			Port: "6379",
+		},
+		Postgres: PostgresConfig{
+			Host: "localhost",
+			Port: "5432",
+			User: "postgres",
+			DB:   "bookstore",
+		},
+		Account: AccountConfig{
+			TOTPIssuer: "Bookstore",
+		},
+		Password: PasswordConfig{
+			MinLength:         8,
+			MinClasses:        2,
+			MinScore:          2,
+			HashAlgorithm:     crypto.Argon2id,
+			Argon2Memory:      19 * 1024,
+			Argon2Iterations:  2,
+			Argon2Parallelism: 1,
+			BcryptCost:        10,
+		},
+		Policy: PolicyConfig{
+			Watcher: NoWatcher,
+		},
+		SMTP: SMTPConfig{
+			Port: "587",
+		},
+		Tracing: TracingConfig{
+			Exporter:    NoExporter,
+			ServiceName: "simple-bookstore",
+			SampleRatio: 1,
+		},
 	}
 }
 
-func IntEnv(key string) int {
-	value, err := strconv.Atoi(os.Getenv(key))
+// Init loads the configuration from the file named by CONFIG_FILE, if any, and the environment,
+// stopping the process with every problem found when it is invalid.
+func Init(opts ...*LoadOpts) *Config {
+	cfg, err := Load(os.Getenv(FileEnv), opts...)
 	if err != nil {
-		log.Fatal().Err(err).Msg("🚨 failed to convert " + key + " to int")
+		log.Fatal().Err(err).Msg("🚨 invalid configuration")
 	}
-	return value
-}
 
-func StringEnv(key string) string {
-	return os.Getenv(key)
+	log.Info().Interface("config", cfg).Msg("⚙️ loaded configuration")
+	return cfg
 }
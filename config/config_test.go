@@ -0,0 +1,155 @@
+package config_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	type Testcase struct {
+		Name      string
+		File      string
+		Content   string
+		Env       map[string]string
+		WantError []string
+		Check     func(t *testing.T, cfg *config.Config)
+	}
+
+	secrets := map[string]string{"ACCESS_SECRET": "access", "REFRESH_SECRET": "refresh"}
+
+	testcases := []Testcase{
+		{
+			Name: "defaults",
+			Env:  secrets,
+			Check: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, config.DevelopmentMode, cfg.Mode)
+				assert.Equal(t, 8000, cfg.Port)
+				assert.Equal(t, config.MemoryStore, cfg.Limit.Store)
+				assert.Equal(t, "access", cfg.JWT.AccessSecret.Reveal())
+			},
+		},
+		{
+			Name: "yaml-file-with-env-overlay",
+			File: "config.yaml",
+			Content: `
+port: 9000
+app_url: https://shop.example.com
+redis:
+  host: cache
+jwt:
+  access_secret: from-file
+  refresh_secret: from-file
+oidc:
+  providers:
+    - name: google
+      issuer: https://accounts.google.com
+      client_id: file-client
+`,
+			Env: map[string]string{"PORT": "9100", "REFRESH_SECRET": "from-env", "OIDC_PROVIDERS": "google", "OIDC_GOOGLE_CLIENT_SECRET": "shh", "OIDC_GOOGLE_SCOPES": "openid, email"},
+			Check: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 9100, cfg.Port)
+				assert.Equal(t, "https://shop.example.com", cfg.AppURL)
+				assert.Equal(t, "cache", cfg.Redis.Host)
+				assert.Equal(t, "6379", cfg.Redis.Port)
+				assert.Equal(t, "from-file", cfg.JWT.AccessSecret.Reveal())
+				assert.Equal(t, "from-env", cfg.JWT.RefreshSecret.Reveal())
+				assert.Equal(t, []config.OIDCProviderConfig{{
+					Name:         "google",
+					Issuer:       "https://accounts.google.com",
+					ClientID:     "file-client",
+					ClientSecret: "shh",
+					Scopes:       []string{"openid", "email"},
+				}}, cfg.OIDC.Providers)
+			},
+		},
+		{
+			Name: "toml-file",
+			File: "config.toml",
+			Content: `
+mode = "production"
+
+[limit]
+store = "redis"
+
+[tracing]
+exporter = "otlp"
+sample_ratio = 0.25
+
+[jwt]
+access_secret = "access"
+refresh_secret = "refresh"
+`,
+			Check: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, config.ProductionMode, cfg.Mode)
+				assert.Equal(t, config.RedisStore, cfg.Limit.Store)
+				assert.Equal(t, config.OTLPExporter, cfg.Tracing.Exporter)
+				assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+			},
+		},
+		{
+			Name:      "unsupported-file",
+			File:      "config.json",
+			Content:   `{}`,
+			WantError: []string{"unsupported config file format"},
+		},
+		{
+			Name: "reports-every-problem",
+			Env:  map[string]string{"PORT": "eighty", "REDIS_DB": "one", "TRUSTED_PROXIES": "10.0.0.0/8, proxy.internal", "MODE": "staging", "LIMIT_RATE": "often", "SMTP_HOST": "smtp.example.com", "TRACING_EXPORTER": "jaeger", "TRACING_SAMPLE_RATIO": "2"},
+			WantError: []string{
+				`PORT: "eighty" is not an integer`,
+				`REDIS_DB: "one" is not an integer`,
+				`mode must be production or development, got "staging"`,
+				`trusted proxy must be an IP or CIDR, got "proxy.internal"`,
+				`limit rate must be <requests>-<S|M|H|D>, got "often"`,
+				"jwt access_secret is required",
+				"jwt refresh_secret is required",
+				"smtp from is required when smtp host is set",
+				`tracing exporter must be otlp, stdout or none, got "jaeger"`,
+				"tracing sample_ratio must be between 0 and 1, got 2",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			for key, value := range tc.Env {
+				t.Setenv(key, value)
+			}
+
+			path := ""
+			if tc.File != "" {
+				path = filepath.Join(t.TempDir(), tc.File)
+				assert.NoError(t, os.WriteFile(path, []byte(tc.Content), 0o600))
+			}
+
+			cfg, err := config.Load(path)
+			if len(tc.WantError) > 0 {
+				assert.Error(t, err)
+				for _, want := range tc.WantError {
+					assert.ErrorContains(t, err, want)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			tc.Check(t, cfg)
+		})
+	}
+}
+
+func TestSecret(t *testing.T) {
+	cfg := config.Default()
+	cfg.JWT.AccessSecret = "super-secret"
+
+	encoded, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), "super-secret")
+	assert.NotContains(t, fmt.Sprintf("%v %+v %#v %s", cfg.JWT, cfg.JWT, cfg.JWT, cfg.JWT.AccessSecret), "super-secret")
+	assert.Equal(t, "super-secret", cfg.JWT.AccessSecret.Reveal())
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable holding the path of the optional config file.
+const FileEnv = "CONFIG_FILE"
+
+// LoadOpts contains optional settings for Load.
+type LoadOpts struct {
+	// Secrets resolves the secret references. Defaults to NewSecretResolver, supporting file:// and env://.
+	Secrets *SecretResolver
+}
+
+// withDefaults fills unset options.
+func (o LoadOpts) withDefaults() LoadOpts {
+	if o.Secrets == nil {
+		o.Secrets = NewSecretResolver()
+	}
+
+	return o
+}
+
+// Load builds the configuration from Default, the YAML or TOML file at path when path is not
+// empty, and the environment, then resolves the secret references. Every malformed value,
+// unresolved secret and failed validation is reported in the returned error, not only the first one.
+func Load(path string, opts ...*LoadOpts) (*Config, error) {
+	option := LoadOpts{}
+	if len(opts) > 0 && opts[0] != nil {
+		option = *opts[0]
+	}
+	option = option.withDefaults()
+
+	cfg := Default()
+
+	if path != "" {
+		if err := decodeFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []error
+	problems = append(problems, applyEnv(reflect.ValueOf(cfg).Elem(), "")...)
+	problems = append(problems, applyOIDCEnv(&cfg.OIDC)...)
+	problems = append(problems, resolveSecrets(context.Background(), option.Secrets, reflect.ValueOf(cfg).Elem(), "")...)
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err)
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+
+	return cfg, nil
+}
+
+// decodeFile decodes the config file at path over cfg, choosing the format from its extension.
+func decodeFile(path string, cfg *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, cfg)
+	case ".toml":
+		err = toml.Unmarshal(content, cfg)
+	default:
+		return fmt.Errorf("unsupported config file format %q, use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// applyEnv overrides the fields of v tagged with env by the environment variables set under
+// prefix plus the tag, recursing into nested structs. Secrets may instead be read from the file
+// named by the variable suffixed with _FILE. It returns one error per malformed value.
+func applyEnv(v reflect.Value, prefix string) []error {
+	var problems []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		key, tagged := v.Type().Field(i).Tag.Lookup("env")
+		if !tagged {
+			if field.Kind() == reflect.Struct {
+				problems = append(problems, applyEnv(field, prefix)...)
+			}
+			continue
+		}
+
+		raw := os.Getenv(prefix + key)
+		if file := os.Getenv(prefix + key + "_FILE"); file != "" && field.Type() == reflect.TypeOf(Secret("")) {
+			if raw != "" {
+				problems = append(problems, fmt.Errorf("set either %s%s or %s%s_FILE, not both", prefix, key, prefix, key))
+				continue
+			}
+			raw = "file://" + file
+		}
+
+		if raw == "" {
+			continue
+		}
+
+		if err := setField(field, raw); err != nil {
+			problems = append(problems, fmt.Errorf("%s%s: %w", prefix, key, err))
+		}
+	}
+
+	return problems
+}
+
+// setField parses raw into field according to its kind. Slices are read as comma separated lists.
+func setField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		field.SetInt(int64(value))
+	case reflect.Float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		field.SetFloat(value)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		field.SetBool(value)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(splitList(raw)))
+	default:
+		return fmt.Errorf("unsupported field kind %s", field.Kind())
+	}
+
+	return nil
+}
+
+// applyOIDCEnv overrides the providers named in OIDC_PROVIDERS with their OIDC_<NAME>_* variables.
+// Providers already configured in the file keep the values the environment does not set.
+func applyOIDCEnv(cfg *OIDCConfig) []error {
+	names := os.Getenv("OIDC_PROVIDERS")
+	if names == "" {
+		return nil
+	}
+
+	var problems []error
+	providers := []OIDCProviderConfig{}
+	for _, name := range splitList(names) {
+		name = strings.ToLower(name)
+
+		provider := OIDCProviderConfig{Name: name}
+		for _, configured := range cfg.Providers {
+			if configured.Name == name {
+				provider = configured
+			}
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		problems = append(problems, applyEnv(reflect.ValueOf(&provider).Elem(), prefix)...)
+		providers = append(providers, provider)
+	}
+
+	cfg.Providers = providers
+	return problems
+}
+
+// splitList splits a comma separated list, dropping blank entries.
+func splitList(raw string) []string {
+	values := []string{}
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
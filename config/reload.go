@@ -0,0 +1,299 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// WatchInterval is how often Manager.Run checks the config file for changes.
+	WatchInterval = 5 * time.Second
+	// SecretRefreshInterval is how often Manager.Run resolves the secrets again to pick up rotations.
+	SecretRefreshInterval = time.Minute
+	// ReloadHistorySize is how many reload outcomes Manager.History keeps.
+	ReloadHistorySize = 20
+)
+
+// ReloadTrigger names what started a reload.
+type ReloadTrigger string
+
+const (
+	FileTrigger   = ReloadTrigger("file")
+	SignalTrigger = ReloadTrigger("signal")
+	AdminTrigger  = ReloadTrigger("admin")
+	SecretTrigger = ReloadTrigger("secret_rotation")
+)
+
+// ReloadResult is the outcome of a reload. Applied and RequiresRestart list the changed
+// settings by their config file keys, e.g. cors.allowed_origins. Values are never included.
+type ReloadResult struct {
+	Trigger         ReloadTrigger
+	At              time.Time
+	Applied         []string
+	RequiresRestart []string
+	Error           string
+}
+
+// ReloadFunc applies a reloaded configuration to a running component.
+type ReloadFunc func(cfg *Config) error
+
+// Manager holds the running configuration and reloads it from the config file and the environment.
+// Reloadable settings are swapped in all at once; the others keep their running values until restart.
+type Manager struct {
+	path    string
+	opts    *LoadOpts
+	current atomic.Pointer[Config]
+
+	mu        sync.Mutex
+	listeners map[string]ReloadFunc
+	history   []ReloadResult
+	modTime   time.Time
+	// requiresRestart is the RequiresRestart of the last recorded reload.
+	requiresRestart []string
+}
+
+// NewManager creates a manager running cfg, which was loaded from path with opts.
+func NewManager(cfg *Config, path string, opts ...*LoadOpts) *Manager {
+	m := &Manager{path: path, listeners: map[string]ReloadFunc{}, history: []ReloadResult{}}
+	if len(opts) > 0 {
+		m.opts = opts[0]
+	}
+
+	m.current.Store(cfg)
+	m.modTime = m.fileModTime()
+
+	return m
+}
+
+// Current returns the running configuration. It must not be modified.
+func (m *Manager) Current() *Config {
+	return m.current.Load()
+}
+
+// OnReload registers apply, called under name with the new configuration on each reload that changes a reloadable
+// setting. When another component rejects the configuration, apply is called again with the running one.
+func (m *Manager) OnReload(name string, apply ReloadFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.listeners[name] = apply
+}
+
+// History returns the most recent reload outcomes, newest first.
+func (m *Manager) History() []ReloadResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := make([]ReloadResult, len(m.history))
+	copy(history, m.history)
+	return history
+}
+
+// Reload loads the configuration again and applies the reloadable settings that changed. An invalid
+// configuration, or one a component fails to apply, is rejected as a whole, keeping every running setting.
+func (m *Manager) Reload(trigger ReloadTrigger) ReloadResult {
+	return m.reload(trigger, false)
+}
+
+// reload implements Reload. Quiet reloads only log and keep the outcome when a setting was applied,
+// the reload failed or the settings requiring a restart changed since the last recorded reload, as
+// periodic secret refreshes mostly find nothing new.
+func (m *Manager) reload(trigger ReloadTrigger, quiet bool) ReloadResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := ReloadResult{Trigger: trigger, At: time.Now(), Applied: []string{}, RequiresRestart: []string{}}
+	m.modTime = m.fileModTime()
+
+	loaded, err := Load(m.path, m.opts)
+	if err != nil {
+		result.Error = err.Error()
+		m.record(result)
+		return result
+	}
+
+	current := m.Current()
+	next := *current
+	diff(reflect.ValueOf(current).Elem(), reflect.ValueOf(loaded).Elem(), reflect.ValueOf(&next).Elem(), "", false, &result)
+
+	if len(result.Applied) > 0 {
+		if err := m.apply(current, &next); err != nil {
+			result.Error = err.Error()
+			result.Applied = []string{}
+		} else {
+			m.current.Store(&next)
+		}
+	}
+
+	if !quiet || len(result.Applied) > 0 || result.Error != "" || !slices.Equal(result.RequiresRestart, m.requiresRestart) {
+		m.record(result)
+	}
+	return result
+}
+
+// apply hands next to every listener, in name order. When one fails, it and the listeners already given
+// next are given previous again, so that components never run with settings from both configurations.
+func (m *Manager) apply(previous, next *Config) error {
+	names := slices.Sorted(maps.Keys(m.listeners))
+	for i, name := range names {
+		err := m.listeners[name](next)
+		if err == nil {
+			continue
+		}
+
+		log.Error().Err(err).Str("component", name).Msg("🚨 failed to apply reloaded configuration")
+		failed := []error{fmt.Errorf("%s: %w", name, err)}
+		for _, applied := range names[:i+1] {
+			if err := m.listeners[applied](previous); err != nil {
+				log.Error().Err(err).Str("component", applied).Msg("🚨 failed to restore running configuration")
+				failed = append(failed, fmt.Errorf("%s: restoring: %w", applied, err))
+			}
+		}
+		return errors.Join(failed...)
+	}
+
+	return nil
+}
+
+// Run reloads the configuration on SIGHUP and whenever the config file changes, checked every
+// watchInterval, and resolves the secrets again every secretInterval, until ctx is done.
+func (m *Manager) Run(ctx context.Context, watchInterval, secretInterval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	secrets := time.NewTicker(secretInterval)
+	defer secrets.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			m.Reload(SignalTrigger)
+		case <-ticker.C:
+			if m.fileChanged() {
+				m.Reload(FileTrigger)
+			}
+		case <-secrets.C:
+			m.reload(SecretTrigger, true)
+		}
+	}
+}
+
+// record logs a reload outcome and keeps it in the history.
+func (m *Manager) record(result ReloadResult) {
+	event, message := log.Info(), "⚙️ reloaded configuration"
+	if result.Error != "" {
+		event, message = log.Error().Str("error", result.Error), "🚨 failed to reload configuration"
+	}
+	event.Str("trigger", string(result.Trigger)).
+		Strs("applied", result.Applied).
+		Strs("requires_restart", result.RequiresRestart).
+		Msg(message)
+
+	m.requiresRestart = result.RequiresRestart
+	m.history = append([]ReloadResult{result}, m.history...)
+	if len(m.history) > ReloadHistorySize {
+		m.history = m.history[:ReloadHistorySize]
+	}
+}
+
+// fileChanged reports whether the config file was modified since the last reload.
+func (m *Manager) fileChanged() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return !m.fileModTime().Equal(m.modTime)
+}
+
+// fileModTime returns the modification time of the config file, or the zero time without one.
+func (m *Manager) fileModTime() time.Time {
+	if m.path == "" {
+		return time.Time{}
+	}
+
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// diff compares current with loaded field by field, copying the changed reloadable fields into next
+// and recording every changed field in result under its config file key. Lists of structs of the
+// same length, such as the OIDC providers, are compared element by element under keys like
+// oidc.providers.0.client_secret.
+func diff(current, loaded, next reflect.Value, prefix string, reloadable bool, result *ReloadResult) {
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		key := prefix + strings.Split(field.Tag.Get("yaml"), ",")[0]
+		fieldReloadable := reloadable || field.Tag.Get("reload") == "true"
+
+		if field.Type.Kind() == reflect.Struct {
+			diff(current.Field(i), loaded.Field(i), next.Field(i), key+".", fieldReloadable, result)
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct && current.Field(i).Len() == loaded.Field(i).Len() {
+			diffElements(current.Field(i), loaded.Field(i), next.Field(i), key+".", fieldReloadable, result)
+			continue
+		}
+
+		if reflect.DeepEqual(current.Field(i).Interface(), loaded.Field(i).Interface()) {
+			continue
+		}
+
+		if !fieldReloadable {
+			result.RequiresRestart = append(result.RequiresRestart, key)
+			continue
+		}
+
+		next.Field(i).Set(loaded.Field(i))
+		result.Applied = append(result.Applied, key)
+	}
+}
+
+// diffElements diffs the struct elements of the lists current and loaded into a copy of the list
+// set on next, so the running configuration keeps its own. An element with a change requiring a
+// restart keeps its running values, so that it never mixes the settings of two different entries.
+func diffElements(current, loaded, next reflect.Value, prefix string, reloadable bool, result *ReloadResult) {
+	elements := reflect.MakeSlice(current.Type(), current.Len(), current.Len())
+	reflect.Copy(elements, current)
+
+	applied := false
+	for j := 0; j < current.Len(); j++ {
+		element := &ReloadResult{}
+		diff(current.Index(j), loaded.Index(j), elements.Index(j), prefix+strconv.Itoa(j)+".", reloadable, element)
+
+		if len(element.RequiresRestart) > 0 {
+			elements.Index(j).Set(current.Index(j))
+			result.RequiresRestart = append(result.RequiresRestart, element.RequiresRestart...)
+			result.RequiresRestart = append(result.RequiresRestart, element.Applied...)
+			continue
+		}
+		result.Applied = append(result.Applied, element.Applied...)
+		applied = applied || len(element.Applied) > 0
+	}
+
+	if applied {
+		next.Set(elements)
+	}
+}
@@ -0,0 +1,169 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_Reload(t *testing.T) {
+	t.Setenv("ACCESS_SECRET", "access")
+	t.Setenv("REFRESH_SECRET", "refresh")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	write("port: 8000\nlog_level: info\ncors:\n  allowed_origins: [\"https://a.example.com\"]\n")
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+
+	manager := config.NewManager(cfg, path)
+	var applied []*config.Config
+	manager.OnReload("test", func(cfg *config.Config) error {
+		applied = append(applied, cfg)
+		return nil
+	})
+
+	// Reloadable settings are applied, the others are reported and keep their running value.
+	write("port: 9000\nlog_level: debug\nfeatures: [beta]\ncors:\n  allowed_origins: [\"https://b.example.com\"]\n")
+	result := manager.Reload(config.AdminTrigger)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, []string{"log_level", "features", "cors.allowed_origins"}, result.Applied)
+	assert.Equal(t, []string{"port"}, result.RequiresRestart)
+
+	current := manager.Current()
+	assert.Equal(t, 8000, current.Port)
+	assert.Equal(t, "debug", current.LogLevel)
+	assert.True(t, current.FeatureEnabled("beta"))
+	assert.Equal(t, []string{"https://b.example.com"}, current.CORS.AllowedOrigins)
+	assert.Equal(t, []*config.Config{current}, applied)
+	assert.Equal(t, 8000, cfg.Port, "the previous configuration is left untouched")
+	assert.Equal(t, "info", cfg.LogLevel, "the previous configuration is left untouched")
+
+	// An invalid configuration is rejected as a whole.
+	write("log_level: loud\nlimit:\n  rate: 20-M\n")
+	result = manager.Reload(config.SignalTrigger)
+	assert.Contains(t, result.Error, "log_level must be one of")
+	assert.Empty(t, result.Applied)
+	assert.Same(t, current, manager.Current())
+
+	// A configuration a component fails to apply is rejected as a whole, and the components
+	// that already applied it are given the running configuration back.
+	manager.OnReload("validator", func(cfg *config.Config) error {
+		if cfg.Limit.Rate == "20-M" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	write("port: 9000\nlimit:\n  rate: 20-M\n")
+	result = manager.Reload(config.AdminTrigger)
+	assert.Equal(t, "validator: boom", result.Error)
+	assert.Empty(t, result.Applied)
+	assert.Same(t, current, manager.Current())
+	assert.Len(t, applied, 3, "the running configuration is given back once")
+	assert.Equal(t, "20-M", applied[1].Limit.Rate)
+	assert.Same(t, current, applied[2])
+
+	history := manager.History()
+	assert.Len(t, history, 3)
+	assert.Equal(t, config.AdminTrigger, history[0].Trigger)
+	assert.Equal(t, config.SignalTrigger, history[1].Trigger)
+}
+
+func TestManager_Run(t *testing.T) {
+	t.Setenv("ACCESS_SECRET", "access")
+	t.Setenv("REFRESH_SECRET", "refresh")
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	assert.NoError(t, os.WriteFile(path, []byte(`log_level = "info"`), 0o600))
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	manager := config.NewManager(cfg, path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go manager.Run(ctx, 10*time.Millisecond, time.Hour)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`log_level = "warn"`), 0o600))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	assert.Eventually(t, func() bool {
+		return manager.Current().LogLevel == "warn"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, config.FileTrigger, manager.History()[0].Trigger)
+}
+
+func TestManager_Run_SecretRefresh(t *testing.T) {
+	t.Setenv("ACCESS_SECRET", "access")
+	t.Setenv("REFRESH_SECRET", "refresh")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("port: 8000\n"), 0o600))
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	manager := config.NewManager(cfg, path)
+
+	// A setting requiring a restart is only reported once by the periodic secret refreshes.
+	assert.NoError(t, os.WriteFile(path, []byte("port: 9000\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go manager.Run(ctx, time.Hour, 5*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return len(manager.History()) > 0
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	history := manager.History()
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, config.SecretTrigger, history[0].Trigger)
+		assert.Equal(t, []string{"port"}, history[0].RequiresRestart)
+	}
+}
+
+func TestManager_Reload_Providers(t *testing.T) {
+	t.Setenv("ACCESS_SECRET", "access")
+	t.Setenv("REFRESH_SECRET", "refresh")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(secret, name string) {
+		content := "smtp:\n  password: " + secret + "\noidc:\n  providers:\n" +
+			"    - name: google\n      issuer: https://accounts.google.com\n      client_id: shop\n      client_secret: " + secret + "\n" +
+			"    - name: " + name + "\n      issuer: https://" + name + ".com\n      client_id: shop\n      client_secret: " + secret + "\n"
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	write("one", "github")
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	manager := config.NewManager(cfg, path)
+
+	// Rotated client secrets are applied to each provider in place.
+	write("two", "github")
+	result := manager.Reload(config.SecretTrigger)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, []string{"oidc.providers.0.client_secret", "oidc.providers.1.client_secret", "smtp.password"}, result.Applied)
+	assert.Equal(t, "two", manager.Current().OIDC.Providers[1].ClientSecret.Reveal())
+	assert.Equal(t, "two", manager.Current().SMTP.Password.Reveal())
+	assert.Equal(t, "one", cfg.OIDC.Providers[1].ClientSecret.Reveal(), "the previous configuration is left untouched")
+
+	// A provider replaced by another keeps running as it is, secret included, until restart.
+	write("three", "gitlab")
+	result = manager.Reload(config.SecretTrigger)
+	assert.Equal(t, []string{"oidc.providers.0.client_secret", "smtp.password"}, result.Applied)
+	assert.Equal(t, []string{"oidc.providers.1.name", "oidc.providers.1.issuer", "oidc.providers.1.client_secret"}, result.RequiresRestart)
+	assert.Equal(t, "github", manager.Current().OIDC.Providers[1].Name)
+	assert.Equal(t, "two", manager.Current().OIDC.Providers[1].ClientSecret.Reveal())
+	assert.Equal(t, "three", manager.Current().OIDC.Providers[0].ClientSecret.Reveal())
+}
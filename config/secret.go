@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces the value of a secret wherever it is printed or logged.
+const redacted = "[REDACTED]"
+
+// Secret is a configuration value that must not appear in logs. Use Reveal to read the value.
+// Secrets may be given as references resolved by a SecretResolver, e.g. file:///run/secrets/db,
+// and in the environment through a <NAME>_FILE variable naming the file holding the value.
+type Secret string
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// String returns a placeholder, so secrets printed with fmt or logged do not leak.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString returns the same placeholder as String for the %#v verb.
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// MarshalJSON encodes the placeholder, keeping secrets out of structured logs.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// SecretResolveTimeout bounds the time taken to resolve a single secret reference.
+const SecretResolveTimeout = 10 * time.Second
+
+// SecretProvider resolves the secret references of one scheme, such as vault://kv/bookstore#access_secret.
+type SecretProvider interface {
+	// Resolve returns the value the reference points to. ref is the whole reference, scheme included.
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretProviderFunc adapts a function to the SecretProvider interface.
+type SecretProviderFunc func(ctx context.Context, ref string) (string, error)
+
+func (f SecretProviderFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// referencePattern matches values of the form <scheme>://..., which are resolved rather than used as is.
+var referencePattern = regexp.MustCompile(`^([a-z][a-z0-9+.-]*)://`)
+
+// SecretResolver resolves secret references by their scheme. file:// and env:// are always supported.
+type SecretResolver struct {
+	mu        sync.RWMutex
+	providers map[string]SecretProvider
+}
+
+// NewSecretResolver creates a resolver supporting file:// and env:// references.
+func NewSecretResolver() *SecretResolver {
+	return &SecretResolver{providers: map[string]SecretProvider{
+		"file": SecretProviderFunc(resolveFile),
+		"env":  SecretProviderFunc(resolveEnv),
+	}}
+}
+
+// Register makes provider resolve the references of scheme, replacing any previous provider.
+func (r *SecretResolver) Register(scheme string, provider SecretProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers[scheme] = provider
+}
+
+// Resolve returns the value value refers to. Values that are not references are returned unchanged,
+// references with an unknown scheme are rejected rather than mistaken for the secret itself.
+func (r *SecretResolver) Resolve(ctx context.Context, value string) (string, error) {
+	match := referencePattern.FindStringSubmatch(value)
+	if match == nil {
+		return value, nil
+	}
+
+	r.mu.RLock()
+	provider, ok := r.providers[match[1]]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown secret provider %q", match[1])
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SecretResolveTimeout)
+	defer cancel()
+
+	return provider.Resolve(ctx, value)
+}
+
+// resolveSecrets replaces every Secret in v, recursing into structs and slices, by the value it refers to.
+// It returns one error per secret that could not be resolved, named by its config file key.
+func resolveSecrets(ctx context.Context, resolver *SecretResolver, v reflect.Value, prefix string) []error {
+	var problems []error
+	switch {
+	case v.Type() == reflect.TypeOf(Secret("")):
+		resolved, err := resolver.Resolve(ctx, v.String())
+		if err != nil {
+			return []error{fmt.Errorf("failed to resolve %s: %w", prefix, err)}
+		}
+		v.SetString(resolved)
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			key := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			problems = append(problems, resolveSecrets(ctx, resolver, v.Field(i), key)...)
+		}
+	case v.Kind() == reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			problems = append(problems, resolveSecrets(ctx, resolver, v.Index(i), fmt.Sprintf("%s[%d]", prefix, i))...)
+		}
+	}
+
+	return problems
+}
+
+// resolveFile reads a file:// reference. Trailing newlines are dropped, as secret files usually end with one.
+func resolveFile(_ context.Context, ref string) (string, error) {
+	content, err := os.ReadFile(strings.TrimPrefix(ref, "file://"))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// resolveEnv reads an env://NAME reference.
+func resolveEnv(_ context.Context, ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "env://")
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return value, nil
+}
+
+// MemorySecretProvider is a SecretProvider holding its secrets in memory, standing in for a
+// Vault-style backend in tests and local development. Secrets are looked up by their whole reference.
+type MemorySecretProvider struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+// NewMemorySecretProvider creates a provider holding secrets, keyed by reference.
+func NewMemorySecretProvider(secrets map[string]string) *MemorySecretProvider {
+	p := &MemorySecretProvider{secrets: map[string]string{}}
+	for ref, value := range secrets {
+		p.secrets[ref] = value
+	}
+
+	return p
+}
+
+// Set stores or rotates the secret at ref.
+func (p *MemorySecretProvider) Set(ref, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.secrets[ref] = value
+}
+
+func (p *MemorySecretProvider) Resolve(_ context.Context, ref string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	value, ok := p.secrets[ref]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", ref)
+	}
+
+	return value, nil
+}
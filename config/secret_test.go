@@ -0,0 +1,131 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretResolver_Resolve(t *testing.T) {
+	type Testcase struct {
+		Name      string
+		Value     string
+		Want      string
+		WantError string
+	}
+
+	path := filepath.Join(t.TempDir(), "secret")
+	assert.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+	t.Setenv("BOOKSTORE_TEST_SECRET", "from-env")
+
+	resolver := config.NewSecretResolver()
+	resolver.Register("vault", config.NewMemorySecretProvider(map[string]string{
+		"vault://kv/bookstore#access_secret": "from-vault",
+	}))
+
+	testcases := []Testcase{
+		{Name: "literal", Value: "plain-value", Want: "plain-value"},
+		{Name: "empty", Value: "", Want: ""},
+		{Name: "file", Value: "file://" + path, Want: "from-file"},
+		{Name: "file-missing", Value: "file://" + path + ".missing", WantError: "no such file"},
+		{Name: "env", Value: "env://BOOKSTORE_TEST_SECRET", Want: "from-env"},
+		{Name: "env-missing", Value: "env://BOOKSTORE_TEST_MISSING", WantError: "BOOKSTORE_TEST_MISSING is not set"},
+		{Name: "provider", Value: "vault://kv/bookstore#access_secret", Want: "from-vault"},
+		{Name: "provider-missing", Value: "vault://kv/bookstore#other", WantError: "not found"},
+		{Name: "unknown-scheme", Value: "aws://secret", WantError: `unknown secret provider "aws"`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tc.Value)
+			if tc.WantError != "" {
+				assert.ErrorContains(t, err, tc.WantError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Want, got)
+		})
+	}
+}
+
+func TestLoad_Secrets(t *testing.T) {
+	dir := t.TempDir()
+	accessFile := filepath.Join(dir, "access")
+	assert.NoError(t, os.WriteFile(accessFile, []byte("access-from-file\n"), 0o600))
+
+	vault := config.NewMemorySecretProvider(map[string]string{"vault://kv/bookstore#refresh": "refresh-from-vault"})
+	resolver := config.NewSecretResolver()
+	resolver.Register("vault", vault)
+
+	t.Run("resolves", func(t *testing.T) {
+		t.Setenv("ACCESS_SECRET_FILE", accessFile)
+		t.Setenv("REFRESH_SECRET", "vault://kv/bookstore#refresh")
+
+		cfg, err := config.Load("", &config.LoadOpts{Secrets: resolver})
+		assert.NoError(t, err)
+		assert.Equal(t, "access-from-file", cfg.JWT.AccessSecret.Reveal())
+		assert.Equal(t, "refresh-from-vault", cfg.JWT.RefreshSecret.Reveal())
+	})
+
+	t.Run("reports-every-problem", func(t *testing.T) {
+		t.Setenv("ACCESS_SECRET", "access")
+		t.Setenv("ACCESS_SECRET_FILE", accessFile)
+		t.Setenv("REFRESH_SECRET", "vault://kv/bookstore#missing")
+		t.Setenv("POSTGRES_PASSWORD_FILE", filepath.Join(dir, "missing"))
+
+		_, err := config.Load("", &config.LoadOpts{Secrets: resolver})
+		assert.ErrorContains(t, err, "set either ACCESS_SECRET or ACCESS_SECRET_FILE, not both")
+		assert.ErrorContains(t, err, "failed to resolve jwt.refresh_secret")
+		assert.ErrorContains(t, err, "failed to resolve postgres.password")
+	})
+}
+
+func TestManager_SecretRotation(t *testing.T) {
+	vault := config.NewMemorySecretProvider(map[string]string{
+		"vault://kv/bookstore#access":  "access-v1",
+		"vault://kv/bookstore#refresh": "refresh-v1",
+	})
+	opts := &config.LoadOpts{Secrets: config.NewSecretResolver()}
+	opts.Secrets.Register("vault", vault)
+
+	t.Setenv("ACCESS_SECRET", "vault://kv/bookstore#access")
+	t.Setenv("REFRESH_SECRET", "vault://kv/bookstore#refresh")
+
+	cfg, err := config.Load("", opts)
+	assert.NoError(t, err)
+	manager := config.NewManager(cfg, "", opts)
+
+	rotated := make(chan string, 1)
+	manager.OnReload("jwt", func(cfg *config.Config) error {
+		rotated <- cfg.JWT.AccessSecret.Reveal()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go manager.Run(ctx, time.Hour, 10*time.Millisecond)
+
+	// Refreshes finding nothing new are not recorded.
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, manager.History())
+
+	vault.Set("vault://kv/bookstore#access", "access-v2")
+	select {
+	case secret := <-rotated:
+		assert.Equal(t, "access-v2", secret)
+	case <-time.After(time.Second):
+		t.Fatal("rotated secret was not applied")
+	}
+
+	history := manager.History()
+	assert.Len(t, history, 1)
+	assert.Equal(t, config.SecretTrigger, history[0].Trigger)
+	assert.Equal(t, []string{"jwt.access_secret"}, history[0].Applied)
+	assert.Equal(t, "access-v2", manager.Current().JWT.AccessSecret.Reveal())
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/rs/zerolog"
+	ulimiter "github.com/ulule/limiter/v3"
+)
+
+// Validate checks the configuration, returning every problem joined into one error.
+func (c *Config) Validate() error {
+	var problems []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Mode == ProductionMode || c.Mode == DevelopmentMode, "mode must be %s or %s, got %q", ProductionMode, DevelopmentMode, c.Mode)
+	check(c.Port > 0 && c.Port <= 65535, "port must be between 1 and 65535, got %d", c.Port)
+	if appURL, err := url.Parse(c.AppURL); err != nil || appURL.Scheme == "" || appURL.Host == "" {
+		problems = append(problems, fmt.Errorf("app_url must be an absolute URL, got %q", c.AppURL))
+	}
+
+	_, err := zerolog.ParseLevel(c.LogLevel)
+	check(err == nil && c.LogLevel != "", "log_level must be one of trace, debug, info, warn, error, fatal, panic or disabled, got %q", c.LogLevel)
+	check(c.ShutdownDrain >= 0, "shutdown_drain must not be negative, got %d", c.ShutdownDrain)
+	for _, proxy := range c.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "trusted proxy must be an IP or CIDR, got %q", proxy)
+	}
+
+	check(c.Limit.Store == MemoryStore || c.Limit.Store == RedisStore, "limit store must be %s or %s, got %q", MemoryStore, RedisStore, c.Limit.Store)
+	_, err = ulimiter.NewRateFromFormatted(c.Limit.Rate)
+	check(err == nil, "limit rate must be <requests>-<S|M|H|D>, got %q", c.Limit.Rate)
+	_, err = ulimiter.NewRateFromFormatted(c.Limit.RateAuth)
+	check(err == nil, "limit rate_auth must be <requests>-<S|M|H|D>, got %q", c.Limit.RateAuth)
+
+	check(len(c.CORS.AllowedOrigins) > 0, "cors allowed_origins must list at least one origin")
+	for _, origin := range c.CORS.AllowedOrigins {
+		check(origin == "*" || strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"), "cors allowed origin must be * or start with http:// or https://, got %q", origin)
+	}
+	check(c.CORS.MaxAge >= 0, "cors max_age must not be negative, got %d", c.CORS.MaxAge)
+
+	check(c.Redis.Host != "", "redis host is required")
+	check(c.Redis.Port != "", "redis port is required")
+	check(c.Redis.DB >= 0, "redis db must not be negative, got %d", c.Redis.DB)
+
+	check(c.Postgres.Host != "", "postgres host is required")
+	check(c.Postgres.Port != "", "postgres port is required")
+	check(c.Postgres.User != "", "postgres user is required")
+	check(c.Postgres.DB != "", "postgres db is required")
+
+	check(c.JWT.AccessSecret != "", "jwt access_secret is required")
+	check(c.JWT.RefreshSecret != "", "jwt refresh_secret is required")
+
+	check(c.Password.MinLength > 0, "password min_length must be positive, got %d", c.Password.MinLength)
+	check(c.Password.MinClasses >= 0 && c.Password.MinClasses <= 4, "password min_classes must be between 0 and 4, got %d", c.Password.MinClasses)
+	check(c.Password.MinScore >= 0 && c.Password.MinScore <= 4, "password min_score must be between 0 and 4, got %d", c.Password.MinScore)
+	check(c.Password.HashAlgorithm == crypto.Argon2id || c.Password.HashAlgorithm == crypto.Bcrypt, "password hash_algorithm must be %s or %s, got %q", crypto.Argon2id, crypto.Bcrypt, c.Password.HashAlgorithm)
+	check(c.Password.Argon2Memory > 0, "password argon2_memory must be positive, got %d", c.Password.Argon2Memory)
+	check(c.Password.Argon2Iterations > 0, "password argon2_iterations must be positive, got %d", c.Password.Argon2Iterations)
+	check(c.Password.Argon2Parallelism > 0 && c.Password.Argon2Parallelism <= 255, "password argon2_parallelism must be between 1 and 255, got %d", c.Password.Argon2Parallelism)
+	check(c.Password.BcryptCost >= 4 && c.Password.BcryptCost <= 31, "password bcrypt_cost must be between 4 and 31, got %d", c.Password.BcryptCost)
+
+	var names []string
+	for _, provider := range c.OIDC.Providers {
+		check(provider.Name != "", "oidc provider name is required")
+		check(!slices.Contains(names, provider.Name), "oidc provider %q is configured twice", provider.Name)
+		check(provider.Issuer != "", "oidc provider %q issuer is required", provider.Name)
+		check(provider.ClientID != "", "oidc provider %q client_id is required", provider.Name)
+		names = append(names, provider.Name)
+	}
+
+	check(c.Policy.Watcher == NoWatcher || c.Policy.Watcher == RedisWatcher, "policy watcher must be %s or %s, got %q", NoWatcher, RedisWatcher, c.Policy.Watcher)
+
+	if c.SMTP.Host != "" {
+		check(c.SMTP.Port != "", "smtp port is required when smtp host is set")
+		check(c.SMTP.From != "", "smtp from is required when smtp host is set")
+	}
+
+	check(slices.Contains([]string{OTLPExporter, StdoutExporter, NoExporter}, c.Tracing.Exporter), "tracing exporter must be %s, %s or %s, got %q", OTLPExporter, StdoutExporter, NoExporter, c.Tracing.Exporter)
+	check(c.Tracing.Endpoint == "" || strings.HasPrefix(c.Tracing.Endpoint, "http://") || strings.HasPrefix(c.Tracing.Endpoint, "https://"), "tracing endpoint must start with http:// or https://, got %q", c.Tracing.Endpoint)
+	check(c.Tracing.ServiceName != "", "tracing service_name is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,16 @@
+-- Two-factor authentication columns
+ALTER TABLE users
+    ADD COLUMN totp_secret  TEXT NOT NULL DEFAULT '',
+    ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- One-time recovery codes for two-factor authentication
+CREATE TABLE user_recovery_codes (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL,
+    hashed_code TEXT NOT NULL,
+    used_at     TIMESTAMP,
+    created_at  TIMESTAMP DEFAULT now(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
@@ -0,0 +1,3 @@
+-- Email ownership verification
+ALTER TABLE users
+    ADD COLUMN email_verified_at TIMESTAMP;
@@ -0,0 +1,13 @@
+-- External identities linked to users (social login)
+CREATE TABLE user_identities (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL,
+    provider    TEXT NOT NULL,
+    subject     TEXT NOT NULL,
+    email       TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP DEFAULT now(),
+    UNIQUE (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
@@ -0,0 +1,28 @@
+-- Third-party OAuth2 clients
+CREATE TABLE oauth_clients (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id       UUID NOT NULL,
+    name           TEXT NOT NULL,
+    hashed_secret  TEXT NOT NULL DEFAULT '',
+    confidential   BOOLEAN NOT NULL DEFAULT TRUE,
+    redirect_uris  TEXT NOT NULL DEFAULT '',
+    scopes         TEXT NOT NULL DEFAULT '',
+    grant_types    TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMP DEFAULT now(),
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_oauth_clients_owner_id ON oauth_clients(owner_id);
+
+-- Scopes users have granted to OAuth2 clients
+CREATE TABLE oauth_consents (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL,
+    client_id   UUID NOT NULL,
+    scopes      TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP DEFAULT now(),
+    updated_at  TIMESTAMP DEFAULT now(),
+    UNIQUE (user_id, client_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (client_id) REFERENCES oauth_clients(id) ON DELETE CASCADE
+);
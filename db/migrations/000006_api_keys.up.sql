@@ -0,0 +1,16 @@
+-- Personal API keys for machine-to-machine access
+CREATE TABLE api_keys (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL,
+    name          TEXT NOT NULL,
+    prefix        TEXT NOT NULL UNIQUE,
+    hashed_key    TEXT NOT NULL,
+    scopes        TEXT NOT NULL DEFAULT '',
+    expires_at    TIMESTAMP,
+    last_used_at  TIMESTAMP,
+    revoked_at    TIMESTAMP,
+    created_at    TIMESTAMP DEFAULT now(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
@@ -0,0 +1,22 @@
+-- Append-only log of security-relevant events
+CREATE TABLE audit_events (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type        TEXT NOT NULL,
+    outcome     TEXT NOT NULL,
+    actor_id    TEXT NOT NULL DEFAULT '',
+    target      TEXT NOT NULL DEFAULT '',
+    details     TEXT NOT NULL DEFAULT '',
+    ip          TEXT NOT NULL DEFAULT '',
+    user_agent  TEXT NOT NULL DEFAULT '',
+    request_id  TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id, created_at);
+CREATE INDEX idx_audit_events_target ON audit_events(target, created_at);
+CREATE INDEX idx_audit_events_type ON audit_events(type, created_at);
+
+-- Events are never changed or removed by the application
+CREATE RULE audit_events_no_update AS ON UPDATE TO audit_events DO INSTEAD NOTHING;
+CREATE RULE audit_events_no_delete AS ON DELETE TO audit_events DO INSTEAD NOTHING;
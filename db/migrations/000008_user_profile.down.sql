@@ -0,0 +1,6 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS display_name,
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS preferences,
+    DROP COLUMN IF EXISTS pending_email;
@@ -0,0 +1,7 @@
+-- Profile and account management columns
+ALTER TABLE users
+    ADD COLUMN display_name  TEXT NOT NULL DEFAULT '',
+    ADD COLUMN avatar_url    TEXT NOT NULL DEFAULT '',
+    ADD COLUMN locale        TEXT NOT NULL DEFAULT '',
+    ADD COLUMN preferences   JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN pending_email TEXT NOT NULL DEFAULT '';
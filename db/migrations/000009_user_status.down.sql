@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_status;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS password_reset_required;
@@ -0,0 +1,6 @@
+-- Account status managed by administrators
+ALTER TABLE users
+    ADD COLUMN status                  TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'disabled')),
+    ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_users_status ON users (status);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS quota_usage;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS plan;
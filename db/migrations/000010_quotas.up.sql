@@ -0,0 +1,14 @@
+-- Request quotas per plan
+ALTER TABLE users
+    ADD COLUMN plan TEXT NOT NULL DEFAULT 'free';
+
+-- Requests counted per user or API key ("user:<id>", "key:<id>") in each day and month,
+-- copied from Redis periodically
+CREATE TABLE quota_usage (
+    subject       TEXT NOT NULL,
+    period        TEXT NOT NULL,
+    period_start  TIMESTAMP NOT NULL,
+    count         BIGINT NOT NULL DEFAULT 0,
+    updated_at    TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (subject, period, period_start)
+);
@@ -0,0 +1 @@
+-- The per-user policies are not restored: the users keep the customer role granting the same access.
@@ -0,0 +1,27 @@
+-- Move users holding the per-user resource policies granted before roles existed onto the customer
+-- role. Those users hold no role at all, which keeps per-user policies added later by admins, whose
+-- users got a role on registration. The Casbin table is created by the server on its first start,
+-- so a fresh database has none.
+DO $$
+BEGIN
+    IF to_regclass('casbin_rule') IS NULL THEN
+        RETURN;
+    END IF;
+
+    CREATE TEMPORARY TABLE legacy_subjects ON COMMIT DROP AS
+    SELECT DISTINCT p.v0 AS subject
+    FROM casbin_rule p
+    WHERE p.ptype = 'p'
+      AND p.v1 = 'resource'
+      AND p.v0 ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$'
+      AND NOT EXISTS (SELECT 1 FROM casbin_rule g WHERE g.ptype = 'g' AND g.v0 = p.v0);
+
+    INSERT INTO casbin_rule (ptype, v0, v1, v2, v3, v4, v5)
+    SELECT 'g', subject, 'role:customer', '', '', '', ''
+    FROM legacy_subjects;
+
+    DELETE FROM casbin_rule
+    WHERE ptype = 'p'
+      AND v1 = 'resource'
+      AND v0 IN (SELECT subject FROM legacy_subjects);
+END $$;
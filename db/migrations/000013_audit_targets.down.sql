@@ -0,0 +1 @@
+-- The emails are not restored: the events keep the user IDs, which the application reads either way.
@@ -0,0 +1,13 @@
+-- Audit events target users by ID: emails are personal data and change, IDs do not.
+-- The append-only rule is lifted for this one rewrite.
+DROP RULE audit_events_no_update ON audit_events;
+
+UPDATE audit_events SET target = users.id::text
+FROM users
+WHERE audit_events.target = users.email;
+
+-- Emails left over are those of deleted accounts or unknown login attempts
+UPDATE audit_events SET target = ''
+WHERE target LIKE '%@%';
+
+CREATE RULE audit_events_no_update AS ON UPDATE TO audit_events DO INSTEAD NOTHING;
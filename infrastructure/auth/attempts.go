@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LockoutPolicy configures how failed logins are throttled.
+type LockoutPolicy struct {
+	// MaxAttempts is the number of failures per account before it is locked.
+	MaxAttempts int
+	// IPMaxAttempts is the number of failures per client IP before it is locked.
+	IPMaxAttempts int
+	// BaseDelay is the backoff after the first failure, doubled on every further failure.
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff.
+	MaxDelay time.Duration
+	// LockoutDuration is how long a locked account or IP stays locked.
+	LockoutDuration time.Duration
+	// Window is how long failures are remembered.
+	Window time.Duration
+}
+
+// DefaultLockoutPolicy provides the default failed login policy.
+var DefaultLockoutPolicy = &LockoutPolicy{
+	MaxAttempts:     5,
+	IPMaxAttempts:   20,
+	BaseDelay:       time.Second,
+	MaxDelay:        30 * time.Second,
+	LockoutDuration: 15 * time.Minute,
+	Window:          15 * time.Minute,
+}
+
+// LoginBlockedError is returned when a login is refused because of previous failures.
+type LoginBlockedError struct {
+	// Locked is true for a temporary lockout and false for an exponential backoff.
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("login locked, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter)
+}
+
+// LoginAttempts defines methods for tracking failed logins per account and per client IP.
+type LoginAttempts interface {
+	Check(ctx context.Context, email, ip string) error
+	Fail(ctx context.Context, email, ip string) error
+	Reset(ctx context.Context, email string) error
+}
+
+// RedisLoginAttempts implements LoginAttempts using Redis as backend.
+type RedisLoginAttempts struct {
+	client *redis.Client
+	policy *LockoutPolicy
+}
+
+// NewRedisLoginAttempts creates a new RedisLoginAttempts instance.
+func NewRedisLoginAttempts(client *redis.Client, policies ...*LockoutPolicy) *RedisLoginAttempts {
+	policy := DefaultLockoutPolicy
+	if len(policies) > 0 {
+		policy = policies[0]
+	}
+
+	return &RedisLoginAttempts{client, policy}
+}
+
+func (r *RedisLoginAttempts) Check(ctx context.Context, email, ip string) error {
+	var blocked *LoginBlockedError
+	for _, key := range attemptKeys(email, ip) {
+		pipe := r.client.Pipeline()
+		state := pipe.Get(ctx, blockKey(key))
+		ttl := pipe.PTTL(ctx, blockKey(key))
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return err
+		}
+
+		if state.Err() == redis.Nil || ttl.Val() <= 0 {
+			continue
+		}
+
+		blocked = longestBlock(blocked, &LoginBlockedError{Locked: state.Val() == "locked", RetryAfter: ttl.Val()})
+	}
+
+	if blocked != nil {
+		return blocked
+	}
+
+	return nil
+}
+
+func (r *RedisLoginAttempts) Fail(ctx context.Context, email, ip string) error {
+	for _, key := range attemptKeys(email, ip) {
+		failures, err := r.client.Incr(ctx, failKey(key)).Result()
+		if err != nil {
+			return err
+		}
+
+		if failures == 1 {
+			if err := r.client.Expire(ctx, failKey(key), r.policy.Window).Err(); err != nil {
+				return err
+			}
+		}
+
+		if block := r.policy.blockFor(key, int(failures)); block != nil {
+			state := "backoff"
+			if block.Locked {
+				state = "locked"
+			}
+
+			if err := r.client.Set(ctx, blockKey(key), state, block.RetryAfter).Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *RedisLoginAttempts) Reset(ctx context.Context, email string) error {
+	key := accountKey(email)
+	return r.client.Del(ctx, failKey(key), blockKey(key)).Err()
+}
+
+// MemoryLoginAttempts implements LoginAttempts using an in-memory map (for testing or local usage).
+type MemoryLoginAttempts struct {
+	mu      sync.Mutex
+	policy  *LockoutPolicy
+	entries map[string]*memoryAttempt
+}
+
+type memoryAttempt struct {
+	failures     int
+	windowEnds   time.Time
+	blockedUntil time.Time
+	locked       bool
+}
+
+// NewMemoryLoginAttempts creates a new MemoryLoginAttempts instance.
+func NewMemoryLoginAttempts(policies ...*LockoutPolicy) *MemoryLoginAttempts {
+	policy := DefaultLockoutPolicy
+	if len(policies) > 0 {
+		policy = policies[0]
+	}
+
+	return &MemoryLoginAttempts{policy: policy, entries: map[string]*memoryAttempt{}}
+}
+
+func (m *MemoryLoginAttempts) Check(ctx context.Context, email, ip string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var blocked *LoginBlockedError
+	for _, key := range attemptKeys(email, ip) {
+		entry, ok := m.entries[key]
+		if !ok || !now.Before(entry.blockedUntil) {
+			continue
+		}
+
+		blocked = longestBlock(blocked, &LoginBlockedError{Locked: entry.locked, RetryAfter: entry.blockedUntil.Sub(now)})
+	}
+
+	if blocked != nil {
+		return blocked
+	}
+
+	return nil
+}
+
+func (m *MemoryLoginAttempts) Fail(ctx context.Context, email, ip string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range attemptKeys(email, ip) {
+		entry, ok := m.entries[key]
+		if !ok || now.After(entry.windowEnds) {
+			entry = &memoryAttempt{windowEnds: now.Add(m.policy.Window)}
+			m.entries[key] = entry
+		}
+
+		entry.failures++
+		if block := m.policy.blockFor(key, entry.failures); block != nil {
+			entry.blockedUntil = now.Add(block.RetryAfter)
+			entry.locked = block.Locked
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryLoginAttempts) Reset(ctx context.Context, email string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, accountKey(email))
+	return nil
+}
+
+// blockFor returns the block to apply after the given number of failures for a key, if any.
+func (p *LockoutPolicy) blockFor(key string, failures int) *LoginBlockedError {
+	maxAttempts := p.MaxAttempts
+	if strings.HasPrefix(key, "ip:") {
+		maxAttempts = p.IPMaxAttempts
+	}
+
+	if failures >= maxAttempts {
+		return &LoginBlockedError{Locked: true, RetryAfter: p.LockoutDuration}
+	}
+
+	if p.BaseDelay <= 0 {
+		return nil
+	}
+
+	delay := p.MaxDelay
+	if shift := failures - 1; shift < 32 {
+		delay = min(p.BaseDelay<<shift, p.MaxDelay)
+	}
+
+	return &LoginBlockedError{RetryAfter: delay}
+}
+
+// longestBlock returns whichever block is more severe, preferring lockouts over backoff.
+func longestBlock(current, next *LoginBlockedError) *LoginBlockedError {
+	if current == nil || (next.Locked && !current.Locked) {
+		return next
+	}
+
+	if next.Locked == current.Locked && next.RetryAfter > current.RetryAfter {
+		return next
+	}
+
+	return current
+}
+
+// attemptKeys returns the counter keys for an account and, if known, a client IP.
+func attemptKeys(email, ip string) []string {
+	keys := []string{accountKey(email)}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+
+	return keys
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func failKey(key string) string {
+	return "login:fail:" + key
+}
+
+func blockKey(key string) string {
+	return "login:block:" + key
+}
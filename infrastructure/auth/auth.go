@@ -3,6 +3,8 @@ package auth
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,9 +19,17 @@ func (o AuthObject) String() string {
 }
 
 const (
-	Resource = AuthObject("resource")
+	Resource    = AuthObject("resource")
+	Users       = AuthObject("users")
+	APIKeys     = AuthObject("api_keys")
+	Policies    = AuthObject("policies")
+	AuditEvents = AuthObject("audit_events")
+	Settings    = AuthObject("settings")
 )
 
+// Objects lists every AuthObject policies may refer to.
+var Objects = []AuthObject{Resource, Users, APIKeys, Policies, AuditEvents, Settings}
+
 // AuthAction represents an action for authorization.
 type AuthAction string
 
@@ -32,11 +42,34 @@ const (
 	Read  = AuthAction("read")
 )
 
+// Actions lists every AuthAction policies may refer to.
+var Actions = []AuthAction{Read, Write}
+
 // AccessProperties holds information about a user's token.
+// ClientID is only set for tokens issued to OAuth2 clients and APIKeyID for API keys,
+// in both cases Scopes limits what the credential may do on the user's behalf.
+// ExpiresAt is the expiry of tokens as a Unix time, zero for API keys.
 type AccessProperties struct {
 	TokenUUID string
 	UserID    string
 	Email     string
+	ClientID  string
+	APIKeyID  string
+	Scopes    []Scope
+	ExpiresAt int64
+}
+
+type accessPropertiesKey struct{}
+
+// WithAccessProperties returns a copy of ctx carrying the authenticated caller.
+func WithAccessProperties(ctx context.Context, properties *AccessProperties) context.Context {
+	return context.WithValue(ctx, accessPropertiesKey{}, properties)
+}
+
+// AccessPropertiesFromContext returns the authenticated caller stored in ctx, if any.
+func AccessPropertiesFromContext(ctx context.Context) (*AccessProperties, bool) {
+	properties, ok := ctx.Value(accessPropertiesKey{}).(*AccessProperties)
+	return properties, ok
 }
 
 // TokenProperties contains details for access and refresh tokens.
@@ -55,6 +88,15 @@ type Auth interface {
 	FetchAuth(ctx context.Context, userId string) (string, error)
 	DeleteRefreshToken(ctx context.Context, userId string) error
 	DeleteAccessToken(ctx context.Context, properties *AccessProperties) error
+	DeleteUserTokens(ctx context.Context, userId string, keepTokenUUID string) error
+	// LinkTokens records a token pair created for another user as belonging to owner too, so that
+	// DeleteUserTokens(owner) revokes it, e.g. every token issued to an OAuth2 client.
+	LinkTokens(ctx context.Context, owner string, properties *TokenProperties) error
+}
+
+// sessionsKey is the Redis set of "<access uuid> <refresh uuid>" pairs issued to a user.
+func sessionsKey(userId string) string {
+	return "sessions:" + userId
 }
 
 // RedisAuth implements Auth using Redis as backend.
@@ -86,7 +128,25 @@ func (r *RedisAuth) CreateAuth(ctx context.Context, userId string, properties *T
 		return fmt.Errorf("failed to create auth")
 	}
 
-	return nil
+	// Track the pair so every session of the user can be revoked at once.
+	return r.LinkTokens(ctx, userId, properties)
+}
+
+// LinkTokens adds the pair to the sessions of owner. The set lives as long as the longest lived
+// token in it, pairs of expired tokens are dropped by DeleteUserTokens.
+func (r *RedisAuth) LinkTokens(ctx context.Context, owner string, properties *TokenProperties) error {
+	key := sessionsKey(owner)
+	if err := r.client.SAdd(ctx, key, properties.AccessTokenUUID+" "+properties.RefreshTokenUUID).Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ttl := max(time.Unix(properties.AccessTokenExpire, 0).Sub(now), time.Unix(properties.RefreshTokenExpire, 0).Sub(now))
+	if err := r.client.ExpireNX(ctx, key, ttl).Err(); err != nil {
+		return err
+	}
+
+	return r.client.ExpireGT(ctx, key, ttl).Err()
 }
 
 func (r *RedisAuth) FetchAuth(ctx context.Context, tokenUUID string) (string, error) {
@@ -130,14 +190,43 @@ func (r *RedisAuth) DeleteRefreshToken(ctx context.Context, refreshUUID string)
 	return nil
 }
 
+// DeleteUserTokens revokes every access and refresh token of the user except the pair of keepTokenUUID.
+func (r *RedisAuth) DeleteUserTokens(ctx context.Context, userId string, keepTokenUUID string) error {
+	key := sessionsKey(userId)
+	pairs, err := r.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		accessUUID, refreshUUID, _ := strings.Cut(pair, " ")
+		if keepTokenUUID != "" && accessUUID == keepTokenUUID {
+			continue
+		}
+
+		if err := r.client.Del(ctx, accessUUID, refreshUUID).Err(); err != nil {
+			return err
+		}
+
+		if err := r.client.SRem(ctx, key, pair).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // MemoryAuth implements Auth using an in-memory map (for testing or local usage).
 type MemoryAuth struct {
 	storage sync.Map
+	// links holds the token UUIDs linked to each owner by LinkTokens.
+	links   map[string][]string
+	linksMu sync.Mutex
 }
 
 // NewMemoryAuth creates a new MemoryAuth instance.
 func NewMemoryAuth() *MemoryAuth {
-	return &MemoryAuth{}
+	return &MemoryAuth{links: map[string][]string{}}
 }
 
 func (m *MemoryAuth) CreateAuth(ctx context.Context, userId string, properties *TokenProperties) error {
@@ -165,6 +254,38 @@ func (m *MemoryAuth) DeleteRefreshToken(ctx context.Context, refreshUUID string)
 	return nil
 }
 
+func (m *MemoryAuth) DeleteUserTokens(ctx context.Context, userId string, keepTokenUUID string) error {
+	keep := []string{keepTokenUUID, ToRefreshUUID(keepTokenUUID, userId)}
+	m.storage.Range(func(key, value any) bool {
+		if value == userId && !slices.Contains(keep, key.(string)) {
+			m.storage.Delete(key)
+		}
+		return true
+	})
+
+	m.linksMu.Lock()
+	defer m.linksMu.Unlock()
+
+	m.links[userId] = slices.DeleteFunc(m.links[userId], func(tokenUUID string) bool {
+		if slices.Contains(keep, tokenUUID) {
+			return false
+		}
+
+		m.storage.Delete(tokenUUID)
+		return true
+	})
+
+	return nil
+}
+
+func (m *MemoryAuth) LinkTokens(ctx context.Context, owner string, properties *TokenProperties) error {
+	m.linksMu.Lock()
+	defer m.linksMu.Unlock()
+
+	m.links[owner] = append(m.links[owner], properties.AccessTokenUUID, properties.RefreshTokenUUID)
+	return nil
+}
+
 // ToRefreshUUID generates a unique key for the refresh token using token UUID and user ID.
 func ToRefreshUUID(tokenUUID, userId string) string {
 	return fmt.Sprintf("%s++%s", tokenUUID, userId)
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AuthorizationGrant is what an OAuth2 authorization code stands for until it is exchanged.
+// RedirectURI is the redirect_uri of the authorization request, empty when it was omitted.
+type AuthorizationGrant struct {
+	ClientID      string  `json:"client_id"`
+	UserID        string  `json:"user_id"`
+	Email         string  `json:"email"`
+	RedirectURI   string  `json:"redirect_uri"`
+	Scopes        []Scope `json:"scopes"`
+	CodeChallenge string  `json:"code_challenge"`
+}
+
+// AuthorizationCodes defines methods for storing single-use OAuth2 authorization codes.
+type AuthorizationCodes interface {
+	Save(ctx context.Context, code string, grant *AuthorizationGrant, ttl time.Duration) error
+	Take(ctx context.Context, code string) (*AuthorizationGrant, error)
+}
+
+// RedisAuthorizationCodes implements AuthorizationCodes using Redis as backend.
+type RedisAuthorizationCodes struct {
+	client *redis.Client
+}
+
+// NewRedisAuthorizationCodes creates a new RedisAuthorizationCodes instance.
+func NewRedisAuthorizationCodes(client *redis.Client) *RedisAuthorizationCodes {
+	return &RedisAuthorizationCodes{client}
+}
+
+func (r *RedisAuthorizationCodes) Save(ctx context.Context, code string, grant *AuthorizationGrant, ttl time.Duration) error {
+	value, err := json.Marshal(grant)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, authorizationCodeKey(code), value, ttl).Err()
+}
+
+func (r *RedisAuthorizationCodes) Take(ctx context.Context, code string) (*AuthorizationGrant, error) {
+	value, err := r.client.GetDel(ctx, authorizationCodeKey(code)).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired authorization code")
+	}
+
+	var grant AuthorizationGrant
+	if err := json.Unmarshal(value, &grant); err != nil {
+		return nil, err
+	}
+
+	return &grant, nil
+}
+
+// MemoryAuthorizationCodes implements AuthorizationCodes using an in-memory map (for testing or local usage).
+type MemoryAuthorizationCodes struct {
+	storage sync.Map
+}
+
+type memoryAuthorizationGrant struct {
+	grant     *AuthorizationGrant
+	expiresAt time.Time
+}
+
+// NewMemoryAuthorizationCodes creates a new MemoryAuthorizationCodes instance.
+func NewMemoryAuthorizationCodes() *MemoryAuthorizationCodes {
+	return &MemoryAuthorizationCodes{}
+}
+
+func (m *MemoryAuthorizationCodes) Save(ctx context.Context, code string, grant *AuthorizationGrant, ttl time.Duration) error {
+	m.storage.Store(authorizationCodeKey(code), memoryAuthorizationGrant{grant, time.Now().Add(ttl)})
+	return nil
+}
+
+func (m *MemoryAuthorizationCodes) Take(ctx context.Context, code string) (*AuthorizationGrant, error) {
+	value, ok := m.storage.LoadAndDelete(authorizationCodeKey(code))
+	if !ok || time.Now().After(value.(memoryAuthorizationGrant).expiresAt) {
+		return nil, fmt.Errorf("invalid or expired authorization code")
+	}
+
+	return value.(memoryAuthorizationGrant).grant, nil
+}
+
+func authorizationCodeKey(code string) string {
+	return "oauth:code:" + code
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"context"
 	"fmt"
+	"slices"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/persist"
@@ -9,20 +11,53 @@ import (
 )
 
 // AuthEnforcer defines the interface for authorization enforcement.
+// The context passed to policy changes carries the request making them, for auditing.
 type AuthEnforcer interface {
 	Enforce(sub string, obj AuthObject, act AuthAction) (bool, error)
-	AddPolicy(sub string, obj AuthObject, act AuthAction) error
-	RemovePolicy(sub string, obj AuthObject, act AuthAction) error
+	EnforceResource(sub string, res *AuthResource, act AuthAction) (bool, error)
+	Explain(sub string, res *AuthResource, act AuthAction) (*Explanation, error)
+	AddPolicy(ctx context.Context, sub string, obj AuthObject, act AuthAction) error
+	RemovePolicy(ctx context.Context, sub string, obj AuthObject, act AuthAction) error
+	AddRoleForUser(ctx context.Context, user string, role Role) error
+	DeleteRoleForUser(ctx context.Context, user string, role Role) error
+	GetRolesForUser(user string) ([]Role, error)
+	GetPolicies(filter Policy) ([]Policy, error)
+	GetGroupings(filter Grouping) ([]Grouping, error)
+	AddGrouping(ctx context.Context, member string, group string) error
+	RemoveGrouping(ctx context.Context, member string, group string) error
+}
+
+// Policy is a policy (p) rule allowing Subject to perform Action on Object.
+type Policy struct {
+	Subject string
+	Object  AuthObject
+	Action  AuthAction
+}
+
+// Grouping is a grouping (g) rule making Member inherit the permissions of Group.
+type Grouping struct {
+	Member string
+	Group  string
+}
+
+// Explanation describes an authorization decision: the policy that allowed it, if any,
+// and every role the subject holds directly or through inheritance.
+type Explanation struct {
+	Allowed bool
+	Matched *Policy
+	Roles   []string
 }
 
 // authEnforcer implements AuthEnforcer using Casbin.
 type authEnforcer struct {
-	enforcer *casbin.Enforcer
+	enforcer *casbin.SyncedEnforcer
 }
 
 // AuthEnforcerOpts contains configuration options for AuthEnforcer.
+// Watcher defaults to a NoopWatcher, which is enough for a single instance.
 type AuthEnforcerOpts struct {
 	ModelPath string
+	Watcher   persist.Watcher
 }
 
 // DefaultAuthEnforcerOpts provides default configuration for AuthEnforcer.
@@ -37,7 +72,7 @@ func NewAuthEnforcer(adapter persist.Adapter, opts ...*AuthEnforcerOpts) *authEn
 		option = opts[0]
 	}
 
-	enforcer, err := casbin.NewEnforcer(option.ModelPath, adapter)
+	enforcer, err := casbin.NewSyncedEnforcer(option.ModelPath, adapter)
 	if err != nil {
 		log.Fatal().Err(err).Msg("💣 failed to create enforcer")
 	}
@@ -46,40 +81,183 @@ func NewAuthEnforcer(adapter persist.Adapter, opts ...*AuthEnforcerOpts) *authEn
 		log.Fatal().Err(err).Msg("💣 failed to load policy")
 	}
 
-	return &authEnforcer{enforcer}
+	watcher := option.Watcher
+	if watcher == nil {
+		watcher = NewNoopWatcher()
+	}
+
+	if err := enforcer.SetWatcher(watcher); err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to set policy watcher")
+	}
+
+	// Casbin's default callback reloads without the synced enforcer's lock.
+	if err := watcher.SetUpdateCallback(func(string) {
+		if err := enforcer.LoadPolicy(); err != nil {
+			log.Error().Err(err).Msg("🚨 failed to reload policy")
+			return
+		}
+
+		log.Info().Msg("🔐 reloaded policy after a change on another instance")
+	}); err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to set policy watcher callback")
+	}
+
+	e := &authEnforcer{enforcer}
+	if err := e.seedRoles(); err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to seed roles")
+	}
+
+	return e
 }
 
-// Enforce checks if a request is allowed.
+// Enforce checks if a request on any instance of obj is allowed.
 func (e *authEnforcer) Enforce(sub string, obj AuthObject, act AuthAction) (bool, error) {
-	return e.enforcer.Enforce(sub, obj.String(), act.String())
+	return e.enforcer.Enforce(sub, obj.String(), act.String(), "", "")
+}
+
+// EnforceResource checks if a request on a single resource is allowed, taking its owner into account.
+func (e *authEnforcer) EnforceResource(sub string, res *AuthResource, act AuthAction) (bool, error) {
+	return e.enforcer.Enforce(sub, res.Object.String(), act.String(), res.ID, res.Owner)
+}
+
+// Explain evaluates a request like EnforceResource and reports why it was allowed or denied.
+// Leave the resource ID and owner empty to explain a request on any instance of the object.
+func (e *authEnforcer) Explain(sub string, res *AuthResource, act AuthAction) (*Explanation, error) {
+	ok, rule, err := e.enforcer.EnforceEx(sub, res.Object.String(), act.String(), res.ID, res.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := e.enforcer.GetImplicitRolesForUser(sub)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(roles)
+
+	explanation := &Explanation{Allowed: ok, Roles: append([]string{}, roles...)}
+	if len(rule) >= 3 {
+		explanation.Matched = &Policy{Subject: rule[0], Object: AuthObject(rule[1]), Action: AuthAction(rule[2])}
+	}
+
+	return explanation, nil
 }
 
 // AddPolicy adds a new policy rule.
-func (e *authEnforcer) AddPolicy(sub string, obj AuthObject, act AuthAction) error {
+func (e *authEnforcer) AddPolicy(ctx context.Context, sub string, obj AuthObject, act AuthAction) error {
 	if ok, err := e.enforcer.AddPolicy(sub, obj.String(), act.String()); !ok || err != nil {
 		log.Error().Err(err).Msg("🚨 failed to add policy")
 		return fmt.Errorf("failed to add policy")
 	}
 
-	if err := e.enforcer.SavePolicy(); err != nil {
-		log.Error().Err(err).Msg("🚨 failed to save policy")
-		return fmt.Errorf("failed to save policy")
-	}
-
 	return nil
 }
 
 // RemovePolicy removes a policy rule.
-func (e *authEnforcer) RemovePolicy(sub string, obj AuthObject, act AuthAction) error {
+func (e *authEnforcer) RemovePolicy(ctx context.Context, sub string, obj AuthObject, act AuthAction) error {
 	if ok, err := e.enforcer.RemovePolicy(sub, obj.String(), act.String()); !ok || err != nil {
 		log.Error().Err(err).Msg("🚨 failed to remove policy")
 		return fmt.Errorf("failed to remove policy")
 	}
 
-	if err := e.enforcer.SavePolicy(); err != nil {
-		log.Error().Err(err).Msg("🚨 failed to save policy")
-		return fmt.Errorf("failed to save policy")
+	return nil
+}
+
+// AddRoleForUser assigns a role to a user.
+func (e *authEnforcer) AddRoleForUser(ctx context.Context, user string, role Role) error {
+	if _, err := e.enforcer.AddRoleForUser(user, role.Subject()); err != nil {
+		log.Error().Err(err).Msg("🚨 failed to add role")
+		return fmt.Errorf("failed to add role")
+	}
+
+	return nil
+}
+
+// DeleteRoleForUser removes a role from a user.
+func (e *authEnforcer) DeleteRoleForUser(ctx context.Context, user string, role Role) error {
+	if _, err := e.enforcer.DeleteRoleForUser(user, role.Subject()); err != nil {
+		log.Error().Err(err).Msg("🚨 failed to delete role")
+		return fmt.Errorf("failed to delete role")
+	}
+
+	return nil
+}
+
+// GetRolesForUser returns the roles directly assigned to a user.
+func (e *authEnforcer) GetRolesForUser(user string) ([]Role, error) {
+	subjects, err := e.enforcer.GetRolesForUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []Role{}
+	for _, subject := range subjects {
+		if role, ok := RoleFromSubject(subject); ok {
+			roles = append(roles, role)
+		}
+	}
+	slices.Sort(roles)
+
+	return roles, nil
+}
+
+// GetPolicies returns the policy rules matching filter. Empty fields match anything.
+func (e *authEnforcer) GetPolicies(filter Policy) ([]Policy, error) {
+	rules, err := e.enforcer.GetFilteredPolicy(0, filter.Subject, filter.Object.String(), filter.Action.String())
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]Policy, 0, len(rules))
+	for _, rule := range rules {
+		policies = append(policies, Policy{Subject: rule[0], Object: AuthObject(rule[1]), Action: AuthAction(rule[2])})
+	}
+
+	return policies, nil
+}
+
+// GetGroupings returns the grouping rules matching filter. Empty fields match anything.
+func (e *authEnforcer) GetGroupings(filter Grouping) ([]Grouping, error) {
+	rules, err := e.enforcer.GetFilteredGroupingPolicy(0, filter.Member, filter.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	groupings := make([]Grouping, 0, len(rules))
+	for _, rule := range rules {
+		groupings = append(groupings, Grouping{Member: rule[0], Group: rule[1]})
+	}
+
+	return groupings, nil
+}
+
+// AddGrouping adds a new grouping rule.
+func (e *authEnforcer) AddGrouping(ctx context.Context, member string, group string) error {
+	if ok, err := e.enforcer.AddGroupingPolicy(member, group); !ok || err != nil {
+		log.Error().Err(err).Msg("🚨 failed to add grouping")
+		return fmt.Errorf("failed to add grouping")
 	}
 
 	return nil
 }
+
+// RemoveGrouping removes a grouping rule.
+func (e *authEnforcer) RemoveGrouping(ctx context.Context, member string, group string) error {
+	if ok, err := e.enforcer.RemoveGroupingPolicy(member, group); !ok || err != nil {
+		log.Error().Err(err).Msg("🚨 failed to remove grouping")
+		return fmt.Errorf("failed to remove grouping")
+	}
+
+	return nil
+}
+
+// seedRoles adds the policies and groupings of the seeded roles if they are missing. It runs on
+// every start, so it must leave policies added through the policy administration API alone.
+func (e *authEnforcer) seedRoles() error {
+	policies, groupings := rolePolicies()
+	if _, err := e.enforcer.AddPoliciesEx(policies); err != nil {
+		return err
+	}
+
+	_, err := e.enforcer.AddGroupingPoliciesEx(groupings)
+	return err
+}
@@ -0,0 +1,75 @@
+package auth_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthEnforcer_Explain(t *testing.T) {
+	type Testcase struct {
+		Name        string
+		Sub         string
+		Res         *auth.AuthResource
+		Act         auth.AuthAction
+		WantAllowed bool
+		WantMatched *auth.Policy
+		WantRoles   []string
+	}
+
+	path := filepath.Join(t.TempDir(), "policy.csv")
+	assert.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	enforcer := auth.NewAuthEnforcer(fileadapter.NewAdapter(path), &auth.AuthEnforcerOpts{ModelPath: "../../auth_model.conf"})
+	assert.NoError(t, enforcer.AddRoleForUser(context.Background(), "editor-id", auth.Editor))
+
+	testcases := []Testcase{
+		{
+			Name:        "inherited",
+			Sub:         "editor-id",
+			Res:         &auth.AuthResource{Object: auth.Resource},
+			Act:         auth.Read,
+			WantAllowed: true,
+			WantMatched: &auth.Policy{Subject: auth.Customer.Subject(), Object: auth.Resource, Action: auth.Read},
+			WantRoles:   []string{auth.Customer.Subject(), auth.Editor.Subject()},
+		},
+		{
+			Name:        "owner",
+			Sub:         "editor-id",
+			Res:         &auth.AuthResource{Object: auth.APIKeys, ID: "key-1", Owner: "editor-id"},
+			Act:         auth.Write,
+			WantAllowed: true,
+			WantMatched: &auth.Policy{Subject: auth.OwnerSubject, Object: auth.APIKeys, Action: auth.Write},
+			WantRoles:   []string{auth.Customer.Subject(), auth.Editor.Subject()},
+		},
+		{
+			Name:      "denied",
+			Sub:       "editor-id",
+			Res:       &auth.AuthResource{Object: auth.Users},
+			Act:       auth.Read,
+			WantRoles: []string{auth.Customer.Subject(), auth.Editor.Subject()},
+		},
+		{
+			Name:      "unknown-subject",
+			Sub:       "nobody",
+			Res:       &auth.AuthResource{Object: auth.Resource},
+			Act:       auth.Read,
+			WantRoles: []string{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			explanation, err := enforcer.Explain(tc.Sub, tc.Res, tc.Act)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.WantAllowed, explanation.Allowed)
+			assert.Equal(t, tc.WantMatched, explanation.Matched)
+			assert.ElementsMatch(t, tc.WantRoles, explanation.Roles)
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/chai-rs/simple-bookstore/config"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -14,14 +16,142 @@ import (
 // TokenManager defines methods for JWT token operations.
 type TokenManager interface {
 	CreateToken(userId, email string) (*TokenProperties, error)
+	VerifyRefreshToken(tokenString string) (string, error)
 	ExtractTokenMetadata(*http.Request) (*AccessProperties, error)
+	VerifyAccessToken(tokenString string) (*AccessProperties, error)
+	CreateClientToken(subject, email, clientID string, scopes []Scope, withRefresh bool) (*TokenProperties, error)
+	VerifyClientRefreshToken(tokenString string) (*ClientRefreshProperties, error)
 }
 
-type tokenManager struct{}
+const (
+	// ChallengeTokenTTL is how long a user has to complete a two-factor challenge.
+	ChallengeTokenTTL = 5 * time.Minute
+	// ClientAccessTokenTTL is how long an access token issued to an OAuth2 client stays valid.
+	ClientAccessTokenTTL = 30 * time.Minute
+	// ClientRefreshTokenTTL is how long a refresh token issued to an OAuth2 client stays valid.
+	ClientRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// ClientRefreshProperties holds information about a refresh token issued to an OAuth2 client.
+type ClientRefreshProperties struct {
+	RefreshUUID string
+	AccessUUID  string
+	Subject     string
+	Email       string
+	ClientID    string
+	Scopes      []Scope
+	ExpiresAt   int64
+}
+
+// ClientSubject is the subject of an OAuth2 client acting on its own behalf (client credentials
+// grant). Every token issued to the client is also linked to it, see Auth.LinkTokens.
+func ClientSubject(clientID string) string {
+	return "client:" + clientID
+}
+
+// SecretGracePeriod is how long tokens signed with replaced keys are still accepted after a
+// rotation: the lifetime of the longest lived of them, the refresh tokens of OAuth2 clients.
+const SecretGracePeriod = ClientRefreshTokenTTL
+
+// Keys holds the keys signing and verifying tokens. Set is called again on every reload, which rotates
+// the keys when they changed: new tokens are signed with the new keys, and those signed with the
+// replaced keys are still accepted for SecretGracePeriod, so that a rotation signs nobody out.
+type Keys struct {
+	mu      sync.RWMutex
+	access  []byte
+	refresh []byte
+	// previousAccess and previousRefresh are the keys replaced by the last rotation, still verifying
+	// the tokens they signed until previousUntil.
+	previousAccess  []byte
+	previousRefresh []byte
+	previousUntil   time.Time
+}
+
+// NewKeys creates the keys signing access and refresh tokens.
+func NewKeys(access, refresh string) *Keys {
+	return &Keys{access: []byte(access), refresh: []byte(refresh)}
+}
+
+// Set replaces the keys signing access and refresh tokens, keeping the replaced ones for verification.
+func (k *Keys) Set(access, refresh string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if access == string(k.access) && refresh == string(k.refresh) {
+		return
+	}
+
+	k.previousAccess = k.access
+	k.previousRefresh = k.refresh
+	k.previousUntil = time.Now().Add(SecretGracePeriod)
+	k.access = []byte(access)
+	k.refresh = []byte(refresh)
+}
+
+// signing returns the keys signing access and refresh tokens.
+func (k *Keys) signing() (access, refresh []byte) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.access, k.refresh
+}
+
+// verification returns the keys tokens are verified with: the current keys, then the keys they
+// replaced while within SecretGracePeriod.
+func (k *Keys) verification() (access, refresh [][]byte) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	access = [][]byte{k.access}
+	refresh = [][]byte{k.refresh}
+	if k.previousAccess != nil && time.Now().Before(k.previousUntil) {
+		access = append(access, k.previousAccess)
+		refresh = append(refresh, k.previousRefresh)
+	}
+
+	return access, refresh
+}
+
+// derive derives a signing key for the given purpose from the access key, keeping non-access
+// tokens from ever verifying as access tokens.
+func (k *Keys) derive(label string) []byte {
+	access, _ := k.signing()
+	return derive(access, label)
+}
+
+// derived returns the keys verifying tokens signed with derive, including those signed before
+// the last rotation.
+func (k *Keys) derived(label string) [][]byte {
+	access, _ := k.verification()
+	keys := make([][]byte, len(access))
+	for i, secret := range access {
+		keys[i] = derive(secret, label)
+	}
+	return keys
+}
+
+// hmacKeyFunc returns a jwt.Keyfunc accepting HMAC signatures made with any of keys.
+func hmacKeyFunc(keys [][]byte) jwt.Keyfunc {
+	return func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected method: %s", t.Header["alg"])
+		}
+
+		set := jwt.VerificationKeySet{}
+		for _, key := range keys {
+			set.Keys = append(set.Keys, key)
+		}
+		return set, nil
+	}
+}
+
+type tokenManager struct {
+	keys *Keys
+}
 
-// NewTokenManager creates a new TokenManager instance.
-func NewTokenManager() TokenManager {
-	return &tokenManager{}
+// NewTokenManager creates a new TokenManager instance signing tokens with keys.
+func NewTokenManager(keys *Keys) TokenManager {
+	return &tokenManager{keys}
 }
 
 // CreateToken generates new access and refresh tokens for a user.
@@ -32,7 +162,8 @@ func (t *tokenManager) CreateToken(userId, email string) (*TokenProperties, erro
 	properties.AccessTokenExpire = now.Add(time.Minute * 30).Unix()
 	properties.AccessTokenUUID = uuid.New().String()
 	properties.RefreshTokenExpire = now.Add(time.Hour * 24 * 7).Unix()
-	properties.RefreshTokenUUID = ToRefreshUUID(properties.RefreshTokenUUID, userId)
+	properties.RefreshTokenUUID = ToRefreshUUID(properties.AccessTokenUUID, userId)
+	access, refresh := t.keys.signing()
 
 	// Create access token
 	var err error
@@ -42,7 +173,7 @@ func (t *tokenManager) CreateToken(userId, email string) (*TokenProperties, erro
 	atClaims["email"] = email
 	atClaims["exp"] = properties.AccessTokenExpire
 	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
-	properties.AccessToken, err = at.SignedString([]byte(config.ACCESS_SECRET))
+	properties.AccessToken, err = at.SignedString(access)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +185,7 @@ func (t *tokenManager) CreateToken(userId, email string) (*TokenProperties, erro
 	rtClaims["email"] = email
 	rtClaims["exp"] = properties.RefreshTokenExpire
 	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, rtClaims)
-	properties.RefreshToken, err = rt.SignedString([]byte(config.REFRESH_SECRET))
+	properties.RefreshToken, err = rt.SignedString(refresh)
 	if err != nil {
 		return nil, err
 	}
@@ -62,46 +193,142 @@ func (t *tokenManager) CreateToken(userId, email string) (*TokenProperties, erro
 	return properties, nil
 }
 
-// ExtractTokenMetadata extracts token metadata from HTTP request.
+// VerifyRefreshToken verifies a refresh token issued by CreateToken and returns its refresh UUID.
+func (t *tokenManager) VerifyRefreshToken(tokenString string) (string, error) {
+	_, refresh := t.keys.verification()
+	token, err := jwt.Parse(tokenString, hmacKeyFunc(refresh))
+	if err != nil {
+		return "", err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", fmt.Errorf("invalid refresh token")
+	}
+
+	refreshUUID, ok := claims["refresh_uuid"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid refresh uuid")
+	}
+
+	return refreshUUID, nil
+}
+
+// ExtractTokenMetadata verifies the bearer token of an HTTP request and returns its access properties.
 func (t *tokenManager) ExtractTokenMetadata(r *http.Request) (*AccessProperties, error) {
-	return ExtractTokenMetadata(r)
+	return t.VerifyAccessToken(ExtractToken(r))
 }
 
-// TokenValid checks if the token in the request is valid.
-func TokenValid(r *http.Request) error {
-	token, err := VerifyAuthorizationHeader(r)
+// VerifyAccessToken verifies an access token and returns its access properties.
+func (t *tokenManager) VerifyAccessToken(tokenString string) (*AccessProperties, error) {
+	access, _ := t.keys.verification()
+	token, err := jwt.Parse(tokenString, hmacKeyFunc(access))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return fmt.Errorf("invalid token")
+		return nil, fmt.Errorf("invalid token")
 	}
 
-	return nil
+	return extract(token)
 }
 
-// VerifyAuthorizationHeader verifies and parses the Authorization header.
-func VerifyAuthorizationHeader(r *http.Request) (*jwt.Token, error) {
-	tokenString := ExtractToken(r)
-	return VerifyToken(tokenString)
-}
+// CreateClientToken generates an access token, and optionally a refresh token, issued to an OAuth2 client.
+// The access token is accepted wherever a user access token is, restricted to the granted scopes.
+// The refresh token is signed with a derived key so it can never be used as an access token.
+func (t *tokenManager) CreateClientToken(subject, email, clientID string, scopes []Scope, withRefresh bool) (*TokenProperties, error) {
+	properties := new(TokenProperties)
 
-// VerifyToken verifies and parses a JWT token string.
-func VerifyToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected method: %s", t.Header["alg"])
-		}
+	now := time.Now()
+	properties.AccessTokenExpire = now.Add(ClientAccessTokenTTL).Unix()
+	properties.AccessTokenUUID = uuid.New().String()
+	properties.RefreshTokenExpire = properties.AccessTokenExpire
+	properties.RefreshTokenUUID = ToRefreshUUID(properties.AccessTokenUUID, subject)
+	access, _ := t.keys.signing()
+
+	var err error
+	atClaims := jwt.MapClaims{}
+	atClaims["access_uuid"] = properties.AccessTokenUUID
+	atClaims["user_id"] = subject
+	atClaims["email"] = email
+	atClaims["client_id"] = clientID
+	atClaims["scope"] = JoinScopes(scopes)
+	atClaims["exp"] = properties.AccessTokenExpire
+	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
+	properties.AccessToken, err = at.SignedString(access)
+	if err != nil {
+		return nil, err
+	}
 
-		return []byte(config.ACCESS_SECRET), nil
-	})
+	if !withRefresh {
+		return properties, nil
+	}
 
+	properties.RefreshTokenExpire = now.Add(ClientRefreshTokenTTL).Unix()
+	rtClaims := jwt.MapClaims{}
+	rtClaims["refresh_uuid"] = properties.RefreshTokenUUID
+	rtClaims["parent_uuid"] = properties.AccessTokenUUID
+	rtClaims["user_id"] = subject
+	rtClaims["email"] = email
+	rtClaims["client_id"] = clientID
+	rtClaims["scope"] = JoinScopes(scopes)
+	rtClaims["exp"] = properties.RefreshTokenExpire
+	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, rtClaims)
+	properties.RefreshToken, err = rt.SignedString(t.keys.derive("client_refresh"))
 	if err != nil {
 		return nil, err
 	}
 
-	return token, nil
+	return properties, nil
+}
+
+// VerifyClientRefreshToken verifies a refresh token issued to an OAuth2 client.
+func (t *tokenManager) VerifyClientRefreshToken(tokenString string) (*ClientRefreshProperties, error) {
+	token, err := jwt.Parse(tokenString, hmacKeyFunc(t.keys.derived("client_refresh")))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+
+	properties := &ClientRefreshProperties{}
+	fields := map[string]*string{
+		"refresh_uuid": &properties.RefreshUUID,
+		"parent_uuid":  &properties.AccessUUID,
+		"user_id":      &properties.Subject,
+		"email":        &properties.Email,
+		"client_id":    &properties.ClientID,
+	}
+	for name, field := range fields {
+		value, ok := claims[name].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s", name)
+		}
+		*field = value
+	}
+
+	scope, _ := claims["scope"].(string)
+	if properties.Scopes, err = ParseScopes(scope); err != nil {
+		return nil, err
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, fmt.Errorf("invalid exp")
+	}
+	properties.ExpiresAt = exp.Unix()
+
+	return properties, nil
+}
+
+func derive(secret []byte, label string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
 }
 
 // ExtractToken retrieves the JWT token from the Authorization header.
@@ -115,8 +342,8 @@ func ExtractToken(r *http.Request) string {
 	return ""
 }
 
-// Extract retrieves access properties from a JWT token.
-func Extract(token *jwt.Token) (*AccessProperties, error) {
+// extract retrieves access properties from a verified JWT token.
+func extract(token *jwt.Token) (*AccessProperties, error) {
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
 		return nil, fmt.Errorf("invalid token claims")
@@ -137,27 +364,28 @@ func Extract(token *jwt.Token) (*AccessProperties, error) {
 		return nil, fmt.Errorf("invalid email")
 	}
 
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, fmt.Errorf("invalid exp")
+	}
+
 	properties := &AccessProperties{
 		TokenUUID: accessUUID,
 		UserID:    userID,
 		Email:     email,
+		ExpiresAt: exp.Unix(),
 	}
 
-	return properties, nil
-}
-
-// ExtractTokenMetadata extracts token metadata from HTTP request.
-// This is a package-level function that implements the TokenManager interface.
-func ExtractTokenMetadata(r *http.Request) (*AccessProperties, error) {
-	token, err := VerifyAuthorizationHeader(r)
-	if err != nil {
-		return nil, err
-	}
+	if clientID, ok := claims["client_id"].(string); ok {
+		scope, _ := claims["scope"].(string)
+		scopes, err := ParseScopes(scope)
+		if err != nil {
+			return nil, err
+		}
 
-	acc, err := Extract(token)
-	if err != nil {
-		return nil, err
+		properties.ClientID = clientID
+		properties.Scopes = scopes
 	}
 
-	return acc, nil
+	return properties, nil
 }
@@ -0,0 +1,47 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys_Rotation(t *testing.T) {
+	ctx := context.Background()
+	keys := auth.NewKeys("access-1", "refresh-1")
+	tokenManager := auth.NewTokenManager(keys)
+	oneTimeTokens := auth.NewMemoryOneTimeTokens(keys)
+
+	before, err := tokenManager.CreateToken("user-id", "one@example.com")
+	assert.NoError(t, err)
+	resetToken, err := oneTimeTokens.Issue(ctx, auth.PasswordReset, "user-id", time.Hour)
+	assert.NoError(t, err)
+
+	keys.Set("access-2", "refresh-2")
+
+	_, err = tokenManager.VerifyAccessToken(before.AccessToken)
+	assert.NoError(t, err, "access tokens signed before the rotation must still verify")
+	_, err = tokenManager.VerifyRefreshToken(before.RefreshToken)
+	assert.NoError(t, err, "refresh tokens signed before the rotation must still verify")
+	_, err = oneTimeTokens.Lookup(ctx, auth.PasswordReset, resetToken)
+	assert.NoError(t, err, "links sent before the rotation must still work")
+
+	after, err := tokenManager.CreateToken("user-id", "one@example.com")
+	assert.NoError(t, err)
+	_, err = jwt.Parse(after.AccessToken, func(*jwt.Token) (any, error) { return []byte("access-2"), nil })
+	assert.NoError(t, err, "new tokens must be signed with the new key")
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"access_uuid": "x"}).SignedString([]byte("access-0"))
+	assert.NoError(t, err)
+	_, err = tokenManager.VerifyAccessToken(forged)
+	assert.Error(t, err)
+
+	// Reloading unchanged keys must keep the previous ones.
+	keys.Set("access-2", "refresh-2")
+	_, err = tokenManager.VerifyAccessToken(before.AccessToken)
+	assert.NoError(t, err)
+}
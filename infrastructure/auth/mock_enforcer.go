@@ -5,6 +5,8 @@
 package auth
 
 import (
+	"context"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -35,17 +37,64 @@ func (_m *MockAuthEnforcer) EXPECT() *MockAuthEnforcer_Expecter {
 	return &MockAuthEnforcer_Expecter{mock: &_m.Mock}
 }
 
+// AddGrouping provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) AddGrouping(ctx context.Context, member string, group string) error {
+	ret := _mock.Called(ctx, member, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddGrouping")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, member, group)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthEnforcer_AddGrouping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddGrouping'
+type MockAuthEnforcer_AddGrouping_Call struct {
+	*mock.Call
+}
+
+// AddGrouping is a helper method to define mock.On call
+//   - ctx
+//   - member
+//   - group
+func (_e *MockAuthEnforcer_Expecter) AddGrouping(ctx interface{}, member interface{}, group interface{}) *MockAuthEnforcer_AddGrouping_Call {
+	return &MockAuthEnforcer_AddGrouping_Call{Call: _e.mock.On("AddGrouping", ctx, member, group)}
+}
+
+func (_c *MockAuthEnforcer_AddGrouping_Call) Run(run func(ctx context.Context, member string, group string)) *MockAuthEnforcer_AddGrouping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_AddGrouping_Call) Return(err error) *MockAuthEnforcer_AddGrouping_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_AddGrouping_Call) RunAndReturn(run func(ctx context.Context, member string, group string) error) *MockAuthEnforcer_AddGrouping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddPolicy provides a mock function for the type MockAuthEnforcer
-func (_mock *MockAuthEnforcer) AddPolicy(sub string, obj AuthObject, act AuthAction) error {
-	ret := _mock.Called(sub, obj, act)
+func (_mock *MockAuthEnforcer) AddPolicy(ctx context.Context, sub string, obj AuthObject, act AuthAction) error {
+	ret := _mock.Called(ctx, sub, obj, act)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AddPolicy")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string, AuthObject, AuthAction) error); ok {
-		r0 = returnFunc(sub, obj, act)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, AuthObject, AuthAction) error); ok {
+		r0 = returnFunc(ctx, sub, obj, act)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -58,16 +107,17 @@ type MockAuthEnforcer_AddPolicy_Call struct {
 }
 
 // AddPolicy is a helper method to define mock.On call
+//   - ctx
 //   - sub
 //   - obj
 //   - act
-func (_e *MockAuthEnforcer_Expecter) AddPolicy(sub interface{}, obj interface{}, act interface{}) *MockAuthEnforcer_AddPolicy_Call {
-	return &MockAuthEnforcer_AddPolicy_Call{Call: _e.mock.On("AddPolicy", sub, obj, act)}
+func (_e *MockAuthEnforcer_Expecter) AddPolicy(ctx interface{}, sub interface{}, obj interface{}, act interface{}) *MockAuthEnforcer_AddPolicy_Call {
+	return &MockAuthEnforcer_AddPolicy_Call{Call: _e.mock.On("AddPolicy", ctx, sub, obj, act)}
 }
 
-func (_c *MockAuthEnforcer_AddPolicy_Call) Run(run func(sub string, obj AuthObject, act AuthAction)) *MockAuthEnforcer_AddPolicy_Call {
+func (_c *MockAuthEnforcer_AddPolicy_Call) Run(run func(ctx context.Context, sub string, obj AuthObject, act AuthAction)) *MockAuthEnforcer_AddPolicy_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(AuthObject), args[2].(AuthAction))
+		run(args[0].(context.Context), args[1].(string), args[2].(AuthObject), args[3].(AuthAction))
 	})
 	return _c
 }
@@ -77,7 +127,101 @@ func (_c *MockAuthEnforcer_AddPolicy_Call) Return(err error) *MockAuthEnforcer_A
 	return _c
 }
 
-func (_c *MockAuthEnforcer_AddPolicy_Call) RunAndReturn(run func(sub string, obj AuthObject, act AuthAction) error) *MockAuthEnforcer_AddPolicy_Call {
+func (_c *MockAuthEnforcer_AddPolicy_Call) RunAndReturn(run func(ctx context.Context, sub string, obj AuthObject, act AuthAction) error) *MockAuthEnforcer_AddPolicy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddRoleForUser provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) AddRoleForUser(ctx context.Context, user string, role Role) error {
+	ret := _mock.Called(ctx, user, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddRoleForUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, Role) error); ok {
+		r0 = returnFunc(ctx, user, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthEnforcer_AddRoleForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddRoleForUser'
+type MockAuthEnforcer_AddRoleForUser_Call struct {
+	*mock.Call
+}
+
+// AddRoleForUser is a helper method to define mock.On call
+//   - ctx
+//   - user
+//   - role
+func (_e *MockAuthEnforcer_Expecter) AddRoleForUser(ctx interface{}, user interface{}, role interface{}) *MockAuthEnforcer_AddRoleForUser_Call {
+	return &MockAuthEnforcer_AddRoleForUser_Call{Call: _e.mock.On("AddRoleForUser", ctx, user, role)}
+}
+
+func (_c *MockAuthEnforcer_AddRoleForUser_Call) Run(run func(ctx context.Context, user string, role Role)) *MockAuthEnforcer_AddRoleForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(Role))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_AddRoleForUser_Call) Return(err error) *MockAuthEnforcer_AddRoleForUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_AddRoleForUser_Call) RunAndReturn(run func(ctx context.Context, user string, role Role) error) *MockAuthEnforcer_AddRoleForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteRoleForUser provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) DeleteRoleForUser(ctx context.Context, user string, role Role) error {
+	ret := _mock.Called(ctx, user, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRoleForUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, Role) error); ok {
+		r0 = returnFunc(ctx, user, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthEnforcer_DeleteRoleForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRoleForUser'
+type MockAuthEnforcer_DeleteRoleForUser_Call struct {
+	*mock.Call
+}
+
+// DeleteRoleForUser is a helper method to define mock.On call
+//   - ctx
+//   - user
+//   - role
+func (_e *MockAuthEnforcer_Expecter) DeleteRoleForUser(ctx interface{}, user interface{}, role interface{}) *MockAuthEnforcer_DeleteRoleForUser_Call {
+	return &MockAuthEnforcer_DeleteRoleForUser_Call{Call: _e.mock.On("DeleteRoleForUser", ctx, user, role)}
+}
+
+func (_c *MockAuthEnforcer_DeleteRoleForUser_Call) Run(run func(ctx context.Context, user string, role Role)) *MockAuthEnforcer_DeleteRoleForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(Role))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_DeleteRoleForUser_Call) Return(err error) *MockAuthEnforcer_DeleteRoleForUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_DeleteRoleForUser_Call) RunAndReturn(run func(ctx context.Context, user string, role Role) error) *MockAuthEnforcer_DeleteRoleForUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -138,17 +282,346 @@ func (_c *MockAuthEnforcer_Enforce_Call) RunAndReturn(run func(sub string, obj A
 	return _c
 }
 
+// EnforceResource provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) EnforceResource(sub string, res *AuthResource, act AuthAction) (bool, error) {
+	ret := _mock.Called(sub, res, act)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnforceResource")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string, *AuthResource, AuthAction) (bool, error)); ok {
+		return returnFunc(sub, res, act)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string, *AuthResource, AuthAction) bool); ok {
+		r0 = returnFunc(sub, res, act)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string, *AuthResource, AuthAction) error); ok {
+		r1 = returnFunc(sub, res, act)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthEnforcer_EnforceResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnforceResource'
+type MockAuthEnforcer_EnforceResource_Call struct {
+	*mock.Call
+}
+
+// EnforceResource is a helper method to define mock.On call
+//   - sub
+//   - res
+//   - act
+func (_e *MockAuthEnforcer_Expecter) EnforceResource(sub interface{}, res interface{}, act interface{}) *MockAuthEnforcer_EnforceResource_Call {
+	return &MockAuthEnforcer_EnforceResource_Call{Call: _e.mock.On("EnforceResource", sub, res, act)}
+}
+
+func (_c *MockAuthEnforcer_EnforceResource_Call) Run(run func(sub string, res *AuthResource, act AuthAction)) *MockAuthEnforcer_EnforceResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*AuthResource), args[2].(AuthAction))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_EnforceResource_Call) Return(b bool, err error) *MockAuthEnforcer_EnforceResource_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_EnforceResource_Call) RunAndReturn(run func(sub string, res *AuthResource, act AuthAction) (bool, error)) *MockAuthEnforcer_EnforceResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Explain provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) Explain(sub string, res *AuthResource, act AuthAction) (*Explanation, error) {
+	ret := _mock.Called(sub, res, act)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Explain")
+	}
+
+	var r0 *Explanation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string, *AuthResource, AuthAction) (*Explanation, error)); ok {
+		return returnFunc(sub, res, act)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string, *AuthResource, AuthAction) *Explanation); ok {
+		r0 = returnFunc(sub, res, act)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Explanation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string, *AuthResource, AuthAction) error); ok {
+		r1 = returnFunc(sub, res, act)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthEnforcer_Explain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Explain'
+type MockAuthEnforcer_Explain_Call struct {
+	*mock.Call
+}
+
+// Explain is a helper method to define mock.On call
+//   - sub
+//   - res
+//   - act
+func (_e *MockAuthEnforcer_Expecter) Explain(sub interface{}, res interface{}, act interface{}) *MockAuthEnforcer_Explain_Call {
+	return &MockAuthEnforcer_Explain_Call{Call: _e.mock.On("Explain", sub, res, act)}
+}
+
+func (_c *MockAuthEnforcer_Explain_Call) Run(run func(sub string, res *AuthResource, act AuthAction)) *MockAuthEnforcer_Explain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*AuthResource), args[2].(AuthAction))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_Explain_Call) Return(explanation *Explanation, err error) *MockAuthEnforcer_Explain_Call {
+	_c.Call.Return(explanation, err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_Explain_Call) RunAndReturn(run func(sub string, res *AuthResource, act AuthAction) (*Explanation, error)) *MockAuthEnforcer_Explain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetGroupings provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) GetGroupings(filter Grouping) ([]Grouping, error) {
+	ret := _mock.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroupings")
+	}
+
+	var r0 []Grouping
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(Grouping) ([]Grouping, error)); ok {
+		return returnFunc(filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(Grouping) []Grouping); ok {
+		r0 = returnFunc(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Grouping)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(Grouping) error); ok {
+		r1 = returnFunc(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthEnforcer_GetGroupings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGroupings'
+type MockAuthEnforcer_GetGroupings_Call struct {
+	*mock.Call
+}
+
+// GetGroupings is a helper method to define mock.On call
+//   - filter
+func (_e *MockAuthEnforcer_Expecter) GetGroupings(filter interface{}) *MockAuthEnforcer_GetGroupings_Call {
+	return &MockAuthEnforcer_GetGroupings_Call{Call: _e.mock.On("GetGroupings", filter)}
+}
+
+func (_c *MockAuthEnforcer_GetGroupings_Call) Run(run func(filter Grouping)) *MockAuthEnforcer_GetGroupings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Grouping))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_GetGroupings_Call) Return(groupings []Grouping, err error) *MockAuthEnforcer_GetGroupings_Call {
+	_c.Call.Return(groupings, err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_GetGroupings_Call) RunAndReturn(run func(filter Grouping) ([]Grouping, error)) *MockAuthEnforcer_GetGroupings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPolicies provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) GetPolicies(filter Policy) ([]Policy, error) {
+	ret := _mock.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPolicies")
+	}
+
+	var r0 []Policy
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(Policy) ([]Policy, error)); ok {
+		return returnFunc(filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(Policy) []Policy); ok {
+		r0 = returnFunc(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Policy)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(Policy) error); ok {
+		r1 = returnFunc(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthEnforcer_GetPolicies_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPolicies'
+type MockAuthEnforcer_GetPolicies_Call struct {
+	*mock.Call
+}
+
+// GetPolicies is a helper method to define mock.On call
+//   - filter
+func (_e *MockAuthEnforcer_Expecter) GetPolicies(filter interface{}) *MockAuthEnforcer_GetPolicies_Call {
+	return &MockAuthEnforcer_GetPolicies_Call{Call: _e.mock.On("GetPolicies", filter)}
+}
+
+func (_c *MockAuthEnforcer_GetPolicies_Call) Run(run func(filter Policy)) *MockAuthEnforcer_GetPolicies_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Policy))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_GetPolicies_Call) Return(policys []Policy, err error) *MockAuthEnforcer_GetPolicies_Call {
+	_c.Call.Return(policys, err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_GetPolicies_Call) RunAndReturn(run func(filter Policy) ([]Policy, error)) *MockAuthEnforcer_GetPolicies_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRolesForUser provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) GetRolesForUser(user string) ([]Role, error) {
+	ret := _mock.Called(user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRolesForUser")
+	}
+
+	var r0 []Role
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) ([]Role, error)); ok {
+		return returnFunc(user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) []Role); ok {
+		r0 = returnFunc(user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Role)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthEnforcer_GetRolesForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRolesForUser'
+type MockAuthEnforcer_GetRolesForUser_Call struct {
+	*mock.Call
+}
+
+// GetRolesForUser is a helper method to define mock.On call
+//   - user
+func (_e *MockAuthEnforcer_Expecter) GetRolesForUser(user interface{}) *MockAuthEnforcer_GetRolesForUser_Call {
+	return &MockAuthEnforcer_GetRolesForUser_Call{Call: _e.mock.On("GetRolesForUser", user)}
+}
+
+func (_c *MockAuthEnforcer_GetRolesForUser_Call) Run(run func(user string)) *MockAuthEnforcer_GetRolesForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_GetRolesForUser_Call) Return(roles []Role, err error) *MockAuthEnforcer_GetRolesForUser_Call {
+	_c.Call.Return(roles, err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_GetRolesForUser_Call) RunAndReturn(run func(user string) ([]Role, error)) *MockAuthEnforcer_GetRolesForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveGrouping provides a mock function for the type MockAuthEnforcer
+func (_mock *MockAuthEnforcer) RemoveGrouping(ctx context.Context, member string, group string) error {
+	ret := _mock.Called(ctx, member, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveGrouping")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, member, group)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthEnforcer_RemoveGrouping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveGrouping'
+type MockAuthEnforcer_RemoveGrouping_Call struct {
+	*mock.Call
+}
+
+// RemoveGrouping is a helper method to define mock.On call
+//   - ctx
+//   - member
+//   - group
+func (_e *MockAuthEnforcer_Expecter) RemoveGrouping(ctx interface{}, member interface{}, group interface{}) *MockAuthEnforcer_RemoveGrouping_Call {
+	return &MockAuthEnforcer_RemoveGrouping_Call{Call: _e.mock.On("RemoveGrouping", ctx, member, group)}
+}
+
+func (_c *MockAuthEnforcer_RemoveGrouping_Call) Run(run func(ctx context.Context, member string, group string)) *MockAuthEnforcer_RemoveGrouping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthEnforcer_RemoveGrouping_Call) Return(err error) *MockAuthEnforcer_RemoveGrouping_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthEnforcer_RemoveGrouping_Call) RunAndReturn(run func(ctx context.Context, member string, group string) error) *MockAuthEnforcer_RemoveGrouping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RemovePolicy provides a mock function for the type MockAuthEnforcer
-func (_mock *MockAuthEnforcer) RemovePolicy(sub string, obj AuthObject, act AuthAction) error {
-	ret := _mock.Called(sub, obj, act)
+func (_mock *MockAuthEnforcer) RemovePolicy(ctx context.Context, sub string, obj AuthObject, act AuthAction) error {
+	ret := _mock.Called(ctx, sub, obj, act)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RemovePolicy")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string, AuthObject, AuthAction) error); ok {
-		r0 = returnFunc(sub, obj, act)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, AuthObject, AuthAction) error); ok {
+		r0 = returnFunc(ctx, sub, obj, act)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -161,16 +634,17 @@ type MockAuthEnforcer_RemovePolicy_Call struct {
 }
 
 // RemovePolicy is a helper method to define mock.On call
+//   - ctx
 //   - sub
 //   - obj
 //   - act
-func (_e *MockAuthEnforcer_Expecter) RemovePolicy(sub interface{}, obj interface{}, act interface{}) *MockAuthEnforcer_RemovePolicy_Call {
-	return &MockAuthEnforcer_RemovePolicy_Call{Call: _e.mock.On("RemovePolicy", sub, obj, act)}
+func (_e *MockAuthEnforcer_Expecter) RemovePolicy(ctx interface{}, sub interface{}, obj interface{}, act interface{}) *MockAuthEnforcer_RemovePolicy_Call {
+	return &MockAuthEnforcer_RemovePolicy_Call{Call: _e.mock.On("RemovePolicy", ctx, sub, obj, act)}
 }
 
-func (_c *MockAuthEnforcer_RemovePolicy_Call) Run(run func(sub string, obj AuthObject, act AuthAction)) *MockAuthEnforcer_RemovePolicy_Call {
+func (_c *MockAuthEnforcer_RemovePolicy_Call) Run(run func(ctx context.Context, sub string, obj AuthObject, act AuthAction)) *MockAuthEnforcer_RemovePolicy_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(AuthObject), args[2].(AuthAction))
+		run(args[0].(context.Context), args[1].(string), args[2].(AuthObject), args[3].(AuthAction))
 	})
 	return _c
 }
@@ -180,7 +654,7 @@ func (_c *MockAuthEnforcer_RemovePolicy_Call) Return(err error) *MockAuthEnforce
 	return _c
 }
 
-func (_c *MockAuthEnforcer_RemovePolicy_Call) RunAndReturn(run func(sub string, obj AuthObject, act AuthAction) error) *MockAuthEnforcer_RemovePolicy_Call {
+func (_c *MockAuthEnforcer_RemovePolicy_Call) RunAndReturn(run func(ctx context.Context, sub string, obj AuthObject, act AuthAction) error) *MockAuthEnforcer_RemovePolicy_Call {
 	_c.Call.Return(run)
 	return _c
 }
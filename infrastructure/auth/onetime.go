@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenPurpose represents what a one-time token may be used for.
+type TokenPurpose string
+
+func (p TokenPurpose) String() string {
+	return string(p)
+}
+
+const (
+	PasswordReset     = TokenPurpose("password_reset")
+	EmailVerification = TokenPurpose("email_verification")
+	EmailChange       = TokenPurpose("email_change")
+	// LoginChallenge proves the password step of a two-factor login. It is consumed once the second factor is verified.
+	LoginChallenge = TokenPurpose("login_challenge")
+)
+
+// OneTimeTokens defines methods for signed, single-use, expiring tokens.
+type OneTimeTokens interface {
+	Issue(ctx context.Context, purpose TokenPurpose, userId string, ttl time.Duration) (string, error)
+	Lookup(ctx context.Context, purpose TokenPurpose, token string) (string, error)
+	Consume(ctx context.Context, purpose TokenPurpose, token string) (string, error)
+}
+
+// RedisOneTimeTokens implements OneTimeTokens using Redis as backend.
+type RedisOneTimeTokens struct {
+	client *redis.Client
+	keys   *Keys
+}
+
+// NewRedisOneTimeTokens creates a new RedisOneTimeTokens instance signing tokens with keys.
+func NewRedisOneTimeTokens(client *redis.Client, keys *Keys) *RedisOneTimeTokens {
+	return &RedisOneTimeTokens{client, keys}
+}
+
+func (r *RedisOneTimeTokens) Issue(ctx context.Context, purpose TokenPurpose, userId string, ttl time.Duration) (string, error) {
+	id := uuid.New().String()
+	if err := r.client.Set(ctx, oneTimeKey(purpose, id), userId, ttl).Err(); err != nil {
+		return "", err
+	}
+
+	return signOneTimeToken(r.keys, purpose, id), nil
+}
+
+func (r *RedisOneTimeTokens) Lookup(ctx context.Context, purpose TokenPurpose, token string) (string, error) {
+	id, err := verifyOneTimeToken(r.keys, purpose, token)
+	if err != nil {
+		return "", err
+	}
+
+	userId, err := r.client.Get(ctx, oneTimeKey(purpose, id)).Result()
+	if err != nil {
+		return "", fmt.Errorf("invalid or expired token")
+	}
+
+	return userId, nil
+}
+
+func (r *RedisOneTimeTokens) Consume(ctx context.Context, purpose TokenPurpose, token string) (string, error) {
+	id, err := verifyOneTimeToken(r.keys, purpose, token)
+	if err != nil {
+		return "", err
+	}
+
+	userId, err := r.client.GetDel(ctx, oneTimeKey(purpose, id)).Result()
+	if err != nil {
+		return "", fmt.Errorf("invalid or expired token")
+	}
+
+	return userId, nil
+}
+
+// MemoryOneTimeTokens implements OneTimeTokens using an in-memory map (for testing or local usage).
+type MemoryOneTimeTokens struct {
+	storage sync.Map
+	keys    *Keys
+}
+
+type memoryOneTimeToken struct {
+	userId    string
+	expiresAt time.Time
+}
+
+// NewMemoryOneTimeTokens creates a new MemoryOneTimeTokens instance signing tokens with keys.
+func NewMemoryOneTimeTokens(keys *Keys) *MemoryOneTimeTokens {
+	return &MemoryOneTimeTokens{keys: keys}
+}
+
+func (m *MemoryOneTimeTokens) Issue(ctx context.Context, purpose TokenPurpose, userId string, ttl time.Duration) (string, error) {
+	id := uuid.New().String()
+	m.storage.Store(oneTimeKey(purpose, id), memoryOneTimeToken{userId, time.Now().Add(ttl)})
+	return signOneTimeToken(m.keys, purpose, id), nil
+}
+
+func (m *MemoryOneTimeTokens) Lookup(ctx context.Context, purpose TokenPurpose, token string) (string, error) {
+	id, err := verifyOneTimeToken(m.keys, purpose, token)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := m.storage.Load(oneTimeKey(purpose, id))
+	if !ok || time.Now().After(value.(memoryOneTimeToken).expiresAt) {
+		return "", fmt.Errorf("invalid or expired token")
+	}
+
+	return value.(memoryOneTimeToken).userId, nil
+}
+
+func (m *MemoryOneTimeTokens) Consume(ctx context.Context, purpose TokenPurpose, token string) (string, error) {
+	id, err := verifyOneTimeToken(m.keys, purpose, token)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := m.storage.LoadAndDelete(oneTimeKey(purpose, id))
+	if !ok || time.Now().After(value.(memoryOneTimeToken).expiresAt) {
+		return "", fmt.Errorf("invalid or expired token")
+	}
+
+	return value.(memoryOneTimeToken).userId, nil
+}
+
+// oneTimeKey builds the storage key of a one-time token.
+func oneTimeKey(purpose TokenPurpose, id string) string {
+	return fmt.Sprintf("onetime:%s:%s", purpose, id)
+}
+
+// signOneTimeToken appends an HMAC of the purpose and ID so tampered tokens are rejected before any lookup.
+func signOneTimeToken(keys *Keys, purpose TokenPurpose, id string) string {
+	return id + "." + oneTimeSignature(keys.derive(purpose.String()), id)
+}
+
+// verifyOneTimeToken checks the signature of a one-time token and returns its ID.
+func verifyOneTimeToken(keys *Keys, purpose TokenPurpose, token string) (string, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", fmt.Errorf("invalid token signature")
+	}
+
+	for _, key := range keys.derived(purpose.String()) {
+		if hmac.Equal([]byte(signature), []byte(oneTimeSignature(key, id))) {
+			return id, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid token signature")
+}
+
+func oneTimeSignature(key []byte, id string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
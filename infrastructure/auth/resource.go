@@ -0,0 +1,20 @@
+package auth
+
+// OwnerSubject is the policy subject standing for the owner of the resource being accessed.
+// "p, @owner, obj, act" lets every user act on the instances of obj they own.
+const OwnerSubject = "@owner"
+
+// OwnerPermissions lists what owners may do with their own resources.
+var OwnerPermissions = []Scope{
+	NewScope(APIKeys, Read),
+	NewScope(APIKeys, Write),
+}
+
+// AuthResource identifies a single instance of an AuthObject and who owns it.
+// Besides policies on the whole object, access is granted by "p, sub, obj/id, act" on the
+// instance itself and by OwnerPermissions when Owner is the subject.
+type AuthResource struct {
+	Object AuthObject
+	ID     string
+	Owner  string
+}
@@ -0,0 +1,55 @@
+package auth_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthEnforcer_EnforceResource(t *testing.T) {
+	type Testcase struct {
+		Name string
+		Sub  string
+		Res  *auth.AuthResource
+		Act  auth.AuthAction
+		Want bool
+	}
+
+	path := filepath.Join(t.TempDir(), "policy.csv")
+	assert.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	enforcer := auth.NewAuthEnforcer(fileadapter.NewAdapter(path), &auth.AuthEnforcerOpts{ModelPath: "../../auth_model.conf"})
+	assert.NoError(t, enforcer.AddRoleForUser(context.Background(), "owner-id", auth.Customer))
+	assert.NoError(t, enforcer.AddRoleForUser(context.Background(), "other-id", auth.Customer))
+	assert.NoError(t, enforcer.AddRoleForUser(context.Background(), "admin-id", auth.Admin))
+	assert.NoError(t, enforcer.AddPolicy(context.Background(), "delegate-id", "api_keys/key-2", auth.Write))
+
+	key := &auth.AuthResource{Object: auth.APIKeys, ID: "key-1", Owner: "owner-id"}
+	testcases := []Testcase{
+		{Name: "owner", Sub: "owner-id", Res: key, Act: auth.Write, Want: true},
+		{Name: "not-owner", Sub: "other-id", Res: key, Act: auth.Write, Want: false},
+		{Name: "admin-any-instance", Sub: "admin-id", Res: key, Act: auth.Write, Want: true},
+		{Name: "instance-policy", Sub: "delegate-id", Res: &auth.AuthResource{Object: auth.APIKeys, ID: "key-2"}, Act: auth.Write, Want: true},
+		{Name: "instance-policy-other-instance", Sub: "delegate-id", Res: key, Act: auth.Write, Want: false},
+		{Name: "role-on-object", Sub: "other-id", Res: &auth.AuthResource{Object: auth.Resource, ID: "book-1"}, Act: auth.Read, Want: true},
+		{Name: "no-owner", Sub: "", Res: &auth.AuthResource{Object: auth.APIKeys, ID: "key-3"}, Act: auth.Write, Want: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, err := enforcer.EnforceResource(tc.Sub, tc.Res, tc.Act)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Want, ok)
+		})
+	}
+
+	// Ownership only applies to single resources, never to the whole object.
+	ok, err := enforcer.Enforce("owner-id", auth.APIKeys, auth.Write)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Role is a named set of permissions users are assigned to through Casbin groupings (g).
+type Role string
+
+func (r Role) String() string {
+	return string(r)
+}
+
+const (
+	Customer = Role("customer")
+	Editor   = Role("editor")
+	Admin    = Role("admin")
+)
+
+// DefaultRole is assigned to every user on registration.
+const DefaultRole = Customer
+
+// rolePrefix keeps role subjects apart from user, client and API key subjects.
+const rolePrefix = "role:"
+
+// Subject returns the Casbin subject of the role.
+func (r Role) Subject() string {
+	return rolePrefix + string(r)
+}
+
+// RoleDefinition describes the permissions a role holds directly and the role it inherits from.
+type RoleDefinition struct {
+	Permissions []Scope
+	Inherits    Role
+}
+
+// Roles lists the seeded roles, from least to most privileged. Editors inherit customers and
+// admins inherit editors, so only the permissions added at each level are listed.
+var Roles = map[Role]RoleDefinition{
+	Customer: {
+		Permissions: []Scope{NewScope(Resource, Read)},
+	},
+	Editor: {
+		Permissions: []Scope{NewScope(Resource, Write)},
+		Inherits:    Customer,
+	},
+	Admin: {
+		Permissions: []Scope{
+			NewScope(Users, Read),
+			NewScope(Users, Write),
+			NewScope(APIKeys, Write),
+			NewScope(Policies, Read),
+			NewScope(Policies, Write),
+			NewScope(AuditEvents, Read),
+			NewScope(Settings, Read),
+			NewScope(Settings, Write),
+		},
+		Inherits: Editor,
+	},
+}
+
+// ParseRole parses a role name, rejecting roles that are not seeded.
+func ParseRole(raw string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(raw)))
+	if _, ok := Roles[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", raw)
+	}
+
+	return role, nil
+}
+
+// RoleFromSubject returns the role named by a Casbin subject, if it is one.
+func RoleFromSubject(subject string) (Role, bool) {
+	name, ok := strings.CutPrefix(subject, rolePrefix)
+	return Role(name), ok
+}
+
+// IsSeededPolicy reports whether a policy is one of the seeded role or owner policies.
+func IsSeededPolicy(policy Policy) bool {
+	policies, _ := rolePolicies()
+	return slices.ContainsFunc(policies, func(rule []string) bool {
+		return rule[0] == policy.Subject && rule[1] == policy.Object.String() && rule[2] == policy.Action.String()
+	})
+}
+
+// IsSeededGrouping reports whether a grouping is one of the seeded role inheritances.
+func IsSeededGrouping(grouping Grouping) bool {
+	_, groupings := rolePolicies()
+	return slices.ContainsFunc(groupings, func(rule []string) bool {
+		return rule[0] == grouping.Member && rule[1] == grouping.Group
+	})
+}
+
+// rolePolicies returns the policy (p) and grouping (g) rules seeding the roles and owner permissions.
+func rolePolicies() (policies [][]string, groupings [][]string) {
+	names := make([]Role, 0, len(Roles))
+	for role := range Roles {
+		names = append(names, role)
+	}
+	slices.Sort(names)
+
+	for _, role := range names {
+		definition := Roles[role]
+		for _, scope := range definition.Permissions {
+			obj, act := scope.Split()
+			policies = append(policies, []string{role.Subject(), obj.String(), act.String()})
+		}
+
+		if definition.Inherits != "" {
+			groupings = append(groupings, []string{role.Subject(), definition.Inherits.Subject()})
+		}
+	}
+
+	for _, scope := range OwnerPermissions {
+		obj, act := scope.Split()
+		policies = append(policies, []string{OwnerSubject, obj.String(), act.String()})
+	}
+
+	return policies, groupings
+}
@@ -0,0 +1,78 @@
+package auth_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	customerID = "123e4567-e89b-12d3-a456-426614174000"
+	ownerID    = "123e4567-e89b-12d3-a456-426614174001"
+)
+
+func TestAuthEnforcer_Roles(t *testing.T) {
+	type Testcase struct {
+		Name string
+		Sub  string
+		Obj  auth.AuthObject
+		Act  auth.AuthAction
+		Want bool
+	}
+
+	// A per-user policy added by an admin through the policy administration API.
+	path := filepath.Join(t.TempDir(), "policy.csv")
+	perUser := "p, " + ownerID + ", resource, write\n"
+	assert.NoError(t, os.WriteFile(path, []byte(perUser), 0o600))
+
+	// Starting twice must seed the roles without touching other policies.
+	auth.NewAuthEnforcer(fileadapter.NewAdapter(path), &auth.AuthEnforcerOpts{ModelPath: "../../auth_model.conf"})
+	enforcer := auth.NewAuthEnforcer(fileadapter.NewAdapter(path), &auth.AuthEnforcerOpts{ModelPath: "../../auth_model.conf"})
+	assert.NoError(t, enforcer.AddRoleForUser(context.Background(), customerID, auth.DefaultRole))
+	assert.NoError(t, enforcer.AddRoleForUser(context.Background(), "editor-id", auth.Editor))
+	assert.NoError(t, enforcer.AddRoleForUser(context.Background(), "admin-id", auth.Admin))
+
+	testcases := []Testcase{
+		{Name: "customer-read", Sub: customerID, Obj: auth.Resource, Act: auth.Read, Want: true},
+		{Name: "customer-write", Sub: customerID, Obj: auth.Resource, Act: auth.Write, Want: false},
+		{Name: "editor-write", Sub: "editor-id", Obj: auth.Resource, Act: auth.Write, Want: true},
+		{Name: "editor-inherits-read", Sub: "editor-id", Obj: auth.Resource, Act: auth.Read, Want: true},
+		{Name: "editor-manage-users", Sub: "editor-id", Obj: auth.Users, Act: auth.Write, Want: false},
+		{Name: "admin-manage-users", Sub: "admin-id", Obj: auth.Users, Act: auth.Write, Want: true},
+		{Name: "admin-inherits-write", Sub: "admin-id", Obj: auth.Resource, Act: auth.Write, Want: true},
+		{Name: "per-user-policy-kept", Sub: ownerID, Obj: auth.Resource, Act: auth.Write, Want: true},
+		{Name: "per-user-policy-without-role", Sub: ownerID, Obj: auth.Resource, Act: auth.Read, Want: false},
+		{Name: "no-role", Sub: "nobody", Obj: auth.Resource, Act: auth.Read, Want: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, err := enforcer.Enforce(tc.Sub, tc.Obj, tc.Act)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Want, ok)
+		})
+	}
+
+	roles, err := enforcer.GetRolesForUser(ownerID)
+	assert.NoError(t, err)
+	assert.Empty(t, roles)
+
+	assert.NoError(t, enforcer.DeleteRoleForUser(context.Background(), "admin-id", auth.Admin))
+	ok, err := enforcer.Enforce("admin-id", auth.Users, auth.Write)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := auth.ParseRole(" Editor ")
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Editor, role)
+
+	_, err = auth.ParseRole("owner")
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scope is an OAuth2 scope of the form "<object>:<action>", granting a single AuthObject/AuthAction pair.
+type Scope string
+
+func (s Scope) String() string {
+	return string(s)
+}
+
+// NewScope returns the scope granting act on obj.
+func NewScope(obj AuthObject, act AuthAction) Scope {
+	return Scope(obj.String() + ":" + act.String())
+}
+
+// Split returns the object and action granted by the scope.
+func (s Scope) Split() (AuthObject, AuthAction) {
+	obj, act, _ := strings.Cut(s.String(), ":")
+	return AuthObject(obj), AuthAction(act)
+}
+
+// SupportedScopes lists every scope clients may request.
+var SupportedScopes = []Scope{
+	NewScope(Resource, Read),
+	NewScope(Resource, Write),
+}
+
+// ParseScopes parses a space separated scope list, rejecting unsupported scopes.
+func ParseScopes(raw string) ([]Scope, error) {
+	var scopes []Scope
+	for _, field := range strings.Fields(raw) {
+		scope := Scope(field)
+		if !slices.Contains(SupportedScopes, scope) {
+			return nil, fmt.Errorf("unsupported scope %q", field)
+		}
+
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+// JoinScopes formats scopes as a space separated list.
+func JoinScopes(scopes []Scope) string {
+	fields := make([]string, len(scopes))
+	for i, scope := range scopes {
+		fields[i] = scope.String()
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// ScopesAllow reports whether the scopes grant act on obj.
+func ScopesAllow(scopes []Scope, obj AuthObject, act AuthAction) bool {
+	return slices.Contains(scopes, NewScope(obj, act))
+}
+
+// ScopesCover reports whether every scope in requested is also in granted.
+func ScopesCover(granted, requested []Scope) bool {
+	for _, scope := range requested {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// PolicyChannel is the Redis channel instances announce policy changes on.
+const PolicyChannel = "casbin:policy"
+
+// RedisWatcher implements Casbin's persist.Watcher with Redis pub/sub, so that when one instance
+// changes the policy the others reload it.
+type RedisWatcher struct {
+	client   *redis.Client
+	pubsub   *redis.PubSub
+	id       string
+	mu       sync.RWMutex
+	callback func(string)
+}
+
+// NewRedisWatcher subscribes to PolicyChannel and returns once the subscription is active.
+func NewRedisWatcher(ctx context.Context, client *redis.Client) (*RedisWatcher, error) {
+	pubsub := client.Subscribe(ctx, PolicyChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	w := &RedisWatcher{client: client, pubsub: pubsub, id: rand.Text()}
+	go w.listen()
+
+	return w, nil
+}
+
+// listen calls the update callback for every change announced by another instance.
+func (w *RedisWatcher) listen() {
+	for msg := range w.pubsub.Channel() {
+		if msg.Payload == w.id {
+			continue
+		}
+
+		w.mu.RLock()
+		callback := w.callback
+		w.mu.RUnlock()
+
+		if callback != nil {
+			callback(msg.Payload)
+		}
+	}
+}
+
+func (w *RedisWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callback = callback
+	return nil
+}
+
+// Update announces a policy change to the other instances.
+func (w *RedisWatcher) Update() error {
+	if err := w.client.Publish(context.Background(), PolicyChannel, w.id).Err(); err != nil {
+		log.Error().Err(err).Msg("🚨 failed to publish policy update")
+		return err
+	}
+
+	return nil
+}
+
+func (w *RedisWatcher) Close() {
+	w.pubsub.Close()
+}
+
+// NoopWatcher implements Casbin's persist.Watcher for a single instance, where there is nobody to notify.
+type NoopWatcher struct{}
+
+// NewNoopWatcher creates a new NoopWatcher instance.
+func NewNoopWatcher() *NoopWatcher {
+	return &NoopWatcher{}
+}
+
+func (w *NoopWatcher) SetUpdateCallback(func(string)) error {
+	return nil
+}
+
+func (w *NoopWatcher) Update() error {
+	return nil
+}
+
+func (w *NoopWatcher) Close() {}
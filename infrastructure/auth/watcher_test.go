@@ -0,0 +1,62 @@
+package auth_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingWatcher keeps the update callback so a test can announce a change from another instance.
+type recordingWatcher struct {
+	auth.NoopWatcher
+	callback func(string)
+	updates  int
+}
+
+func (w *recordingWatcher) SetUpdateCallback(callback func(string)) error {
+	w.callback = callback
+	return nil
+}
+
+func (w *recordingWatcher) Update() error {
+	w.updates++
+	return nil
+}
+
+func TestAuthEnforcer_Watcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.csv")
+	assert.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	opts := func(watcher *recordingWatcher) *auth.AuthEnforcerOpts {
+		return &auth.AuthEnforcerOpts{ModelPath: "../../auth_model.conf", Watcher: watcher}
+	}
+
+	first := &recordingWatcher{}
+	second := &recordingWatcher{}
+	one := auth.NewAuthEnforcer(fileadapter.NewAdapter(path), opts(first))
+	two := auth.NewAuthEnforcer(fileadapter.NewAdapter(path), opts(second))
+
+	// The file adapter does not save single rules, so store the policy as a database adapter would.
+	assert.NoError(t, one.AddPolicy(context.Background(), "user-id", auth.Users, auth.Read))
+	assert.Positive(t, first.updates)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	assert.NoError(t, err)
+	_, err = file.WriteString("p, user-id, users, read\n")
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	ok, err := two.Enforce("user-id", auth.Users, auth.Read)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	second.callback("")
+
+	ok, err = two.Enforce("user-id", auth.Users, auth.Read)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -1,9 +1,15 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/chai-rs/simple-bookstore/infrastructure/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/rs/zerolog/log"
 	driver "gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -14,14 +20,31 @@ var (
 	once     sync.Once
 )
 
+// PasswordFunc returns the current password. It is called for every new connection,
+// so a rotated password is used without reconnecting.
+type PasswordFunc func() string
+
 // PostgreSQLConnect connects to the PostgreSQL database.
-func PostgreSQLConnect(host, port, user, password, db string) *gorm.DB {
+func PostgreSQLConnect(host, port, user, db string, password PasswordFunc) *gorm.DB {
 	once.Do(func() {
-		var err error
-		postgres, err = gorm.Open(driver.Open(fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, user, password, db)), &gorm.Config{})
+		connConfig, err := pgx.ParseConfig(fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=disable", host, port, user, db))
+		if err != nil {
+			log.Fatal().Err(err).Msg("💣 Failed to parse PostgreSQL config")
+		}
+
+		conn := stdlib.OpenDB(*connConfig, stdlib.OptionBeforeConnect(func(_ context.Context, config *pgx.ConnConfig) error {
+			config.Password = password()
+			return nil
+		}))
+
+		postgres, err = gorm.Open(driver.New(driver.Config{Conn: conn}), &gorm.Config{})
 		if err != nil {
 			log.Fatal().Err(err).Msg("💣 Failed to connect to PostgreSQL")
 		}
+
+		if err := errors.Join(postgres.Use(metrics.NewGormPlugin()), postgres.Use(tracing.NewGormPlugin())); err != nil {
+			log.Fatal().Err(err).Msg("💣 Failed to setup PostgreSQL metrics and tracing")
+		}
 		log.Debug().Msg("🔌 Connected to PostgreSQL")
 	})
 
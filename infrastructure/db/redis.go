@@ -4,18 +4,23 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/chai-rs/simple-bookstore/infrastructure/tracing"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 )
 
-// Redis returns the Redis client.
-func Redis() *redis.Client {
+// Redis connects to Redis and returns the client. password is called for every new connection.
+func Redis(host, port string, password PasswordFunc, db int) *redis.Client {
 	rdb := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", config.REDIS_HOST, config.REDIS_PORT),
-		Password: config.REDIS_PASSWORD,
-		DB:       config.REDIS_DB,
+		Addr: fmt.Sprintf("%s:%s", host, port),
+		CredentialsProvider: func() (string, string) {
+			return "", password()
+		},
+		DB: db,
 	})
+	rdb.AddHook(metrics.NewRedisHook())
+	rdb.AddHook(tracing.NewRedisHook())
 
 	if err := rdb.Ping(context.Background()).Err(); err != nil {
 		log.Fatal().Err(err).Msg("💣 failed to connect to redis")
@@ -0,0 +1,40 @@
+package limiter
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/ulule/limiter/v3"
+)
+
+// Limiter is a rate limiter whose rate can be changed while it is in use. Counters are kept in the store,
+// so changing the rate does not reset them.
+type Limiter struct {
+	store limiter.Store
+	rate  atomic.Pointer[limiter.Rate]
+}
+
+// newLimiter creates a limiter counting in store at the rate given in format.
+func newLimiter(store limiter.Store, format string) *Limiter {
+	l := &Limiter{store: store}
+	rate := parseRate(format)
+	l.rate.Store(&rate)
+
+	return l
+}
+
+// Get increments the counter of key and returns its state under the current rate.
+func (l *Limiter) Get(ctx context.Context, key string) (limiter.Context, error) {
+	return l.store.Get(ctx, key, *l.rate.Load())
+}
+
+// SetRate replaces the rate, given as <requests>-<S|M|H|D>.
+func (l *Limiter) SetRate(format string) error {
+	rate, err := limiter.NewRateFromFormatted(format)
+	if err != nil {
+		return err
+	}
+
+	l.rate.Store(&rate)
+	return nil
+}
@@ -7,12 +7,17 @@ import (
 )
 
 // NewMemoryLimiter creates a new limiter using memory store.
-func NewMemoryLimiter(format string) *limiter.Limiter {
+// Its counters are per instance, use NewRedisLimiter when running several.
+func NewMemoryLimiter(format string) *Limiter {
+	return newLimiter(memory.NewStore(), format)
+}
+
+// parseRate parses a rate such as "10-M" (10 requests per minute).
+func parseRate(format string) limiter.Rate {
 	rate, err := limiter.NewRateFromFormatted(format)
 	if err != nil {
-		log.Fatal().Err(err).Msg("💣 failed to create rate")
+		log.Fatal().Err(err).Str("rate", format).Msg("💣 failed to create rate")
 	}
 
-	store := memory.NewStore()
-	return limiter.New(store, rate)
+	return rate
 }
@@ -0,0 +1,23 @@
+package limiter
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/ulule/limiter/v3"
+	sredis "github.com/ulule/limiter/v3/drivers/store/redis"
+)
+
+// KeyPrefix prefixes the Redis keys holding rate limit counters.
+const KeyPrefix = "ratelimit"
+
+// NewRedisLimiter creates a new limiter using a Redis store, so every instance shares the same counters.
+func NewRedisLimiter(client *redis.Client, format string) *Limiter {
+	store, err := sredis.NewStoreWithOptions(client, limiter.StoreOptions{
+		Prefix: KeyPrefix,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("💣 failed to create redis rate limit store")
+	}
+
+	return newLimiter(store, format)
+}
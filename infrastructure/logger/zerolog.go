@@ -1,13 +1,79 @@
 package logger
 
 import (
+	"io"
 	"os"
+	"sync/atomic"
 
+	"github.com/chai-rs/simple-bookstore/pkg/redact"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// init initializes the logger.
+// redactor masks the sensitive fields, URL credentials and email addresses of every line logged.
+var redactor atomic.Pointer[redact.Redactor]
+
+// init initializes the logger, writing human readable lines until UseJSON is called.
 func init() {
-	log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout}).With().Timestamp().Caller().Logger()
+	redactor.Store(redact.New())
+	log.Logger = newLogger(zerolog.ConsoleWriter{Out: os.Stdout})
+
+	// Values logged with Interface leave out the struct fields tagged sensitive:"true".
+	zerolog.InterfaceMarshalFunc = redact.Marshal
+
+	// Services log with log.Ctx(ctx), which returns the request logger stored in ctx,
+	// or the global logger outside of requests.
+	zerolog.DefaultContextLogger = &log.Logger
+}
+
+func newLogger(w io.Writer) zerolog.Logger {
+	return zerolog.New(redactWriter{w}).With().Timestamp().Caller().Logger().Hook(traceHook{})
+}
+
+// UseJSON writes every line as a JSON object, for log collectors in production.
+func UseJSON() {
+	log.Logger = newLogger(os.Stdout)
+}
+
+// RedactFields masks the fields named fields in addition to redact.DefaultFields.
+func RedactFields(fields ...string) {
+	redactor.Store(redact.New(fields...))
+}
+
+// redactWriter masks each line before writing it, so nothing sensitive reaches the output
+// whichever field or message it was logged in.
+type redactWriter struct {
+	w io.Writer
+}
+
+func (w redactWriter) Write(p []byte) (int, error) {
+	if _, err := w.w.Write(redactor.Load().JSON(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// traceHook adds the trace and span IDs carried by the context of an event, set with Ctx,
+// so that lines logged while handling a request can be found from its trace.
+type traceHook struct{}
+
+func (traceHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	spanContext := trace.SpanContextFromContext(e.GetCtx())
+	if !spanContext.IsValid() {
+		return
+	}
+
+	e.Str("trace_id", spanContext.TraceID().String()).Str("span_id", spanContext.SpanID().String())
+}
+
+// SetLevel sets the minimum level of the messages logged, e.g. info or debug.
+func SetLevel(level string) error {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	zerolog.SetGlobalLevel(parsed)
+	return nil
 }
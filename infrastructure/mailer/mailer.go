@@ -0,0 +1,15 @@
+package mailer
+
+import "context"
+
+// Message represents an outgoing email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer defines methods for sending emails.
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPMailerOpts contains configuration options for SMTPMailer.
+type SMTPMailerOpts struct {
+	Host     string
+	Port     string
+	Username string
+	// Password returns the SMTP password. It is called for every message, so a rotated password is used without restarting.
+	Password func() string
+	From     string
+}
+
+// SMTPMailer implements Mailer using an SMTP server.
+type SMTPMailer struct {
+	opts *SMTPMailerOpts
+}
+
+// NewSMTPMailer creates a new SMTPMailer instance.
+func NewSMTPMailer(opts *SMTPMailerOpts) *SMTPMailer {
+	return &SMTPMailer{opts}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message *Message) error {
+	var auth smtp.Auth
+	if m.opts.Username != "" {
+		auth = smtp.PlainAuth("", m.opts.Username, m.opts.Password(), m.opts.Host)
+	}
+
+	addr := net.JoinHostPort(m.opts.Host, m.opts.Port)
+	if err := smtp.SendMail(addr, auth, m.opts.From, []string{message.To}, m.build(message)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	return nil
+}
+
+// build renders the message as an RFC 5322 plain text email.
+func (m *SMTPMailer) build(message *Message) []byte {
+	var sb strings.Builder
+	sb.WriteString("From: " + m.opts.From + "\r\n")
+	sb.WriteString("To: " + message.To + "\r\n")
+	sb.WriteString("Subject: " + message.Subject + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(message.Body)
+	return []byte(sb.String())
+}
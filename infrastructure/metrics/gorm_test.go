@@ -0,0 +1,49 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID   int
+	Name string
+}
+
+// sampleCount returns the number of observations of a histogram series.
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	var metric dto.Metric
+	assert.NoError(t, observer.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(metrics.NewGormPlugin()))
+	assert.NoError(t, db.AutoMigrate(&widget{}))
+
+	creates := metrics.DBQueryDuration.WithLabelValues("create", "widgets")
+	queries := metrics.DBQueryDuration.WithLabelValues("query", "widgets")
+	createErrors := metrics.DBQueryErrors.WithLabelValues("create", "widgets")
+	queryErrors := metrics.DBQueryErrors.WithLabelValues("query", "widgets")
+	createsBefore, queriesBefore := sampleCount(t, creates), sampleCount(t, queries)
+	createErrorsBefore, queryErrorsBefore := testutil.ToFloat64(createErrors), testutil.ToFloat64(queryErrors)
+
+	assert.NoError(t, db.Create(&widget{ID: 1, Name: "a"}).Error)
+	assert.NoError(t, db.First(&widget{}, 1).Error)
+	assert.ErrorIs(t, db.First(&widget{}, 2).Error, gorm.ErrRecordNotFound)
+	assert.Error(t, db.Create(&widget{ID: 1, Name: "duplicate"}).Error)
+
+	assert.Equal(t, uint64(2), sampleCount(t, creates)-createsBefore)
+	assert.Equal(t, uint64(2), sampleCount(t, queries)-queriesBefore)
+	assert.Equal(t, float64(1), testutil.ToFloat64(createErrors)-createErrorsBefore)
+	assert.Equal(t, float64(0), testutil.ToFloat64(queryErrors)-queryErrorsBefore, "records not found are not failures")
+}
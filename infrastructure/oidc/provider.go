@@ -0,0 +1,111 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// DefaultScopes are requested when a provider does not configure its own.
+var DefaultScopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+
+// ProviderConfig contains the client registration of an OpenID Connect provider.
+type ProviderConfig struct {
+	Name      string
+	IssuerURL string
+	ClientID  string
+	// ClientSecret returns the client secret. It is called for every code exchange, so a rotated
+	// secret is used without restarting.
+	ClientSecret func() string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Claims are the identity claims read from a verified ID token.
+type Claims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// Provider is a generic OpenID Connect client using the authorization code flow with PKCE.
+type Provider struct {
+	name         string
+	oauth2       *oauth2.Config
+	clientSecret func() string
+	verifier     *gooidc.IDTokenVerifier
+}
+
+// NewProvider discovers the provider's endpoints from its issuer URL.
+func NewProvider(ctx context.Context, config *ProviderConfig) (*Provider, error) {
+	provider, err := gooidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", config.Name, err)
+	}
+
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+
+	return &Provider{
+		name: config.Name,
+		oauth2: &oauth2.Config{
+			ClientID:    config.ClientID,
+			RedirectURL: config.RedirectURL,
+			Endpoint:    provider.Endpoint(),
+			Scopes:      scopes,
+		},
+		clientSecret: config.ClientSecret,
+		verifier:     provider.Verifier(&gooidc.Config{ClientID: config.ClientID}),
+	}, nil
+}
+
+// Name returns the provider name used in routes and identities.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL returns the URL the user is redirected to in order to sign in.
+func (p *Provider) AuthCodeURL(state *LoginState) string {
+	return p.oauth2.AuthCodeURL(
+		state.State,
+		oauth2.S256ChallengeOption(state.CodeVerifier),
+		gooidc.Nonce(state.Nonce),
+	)
+}
+
+// Exchange trades an authorization code for tokens and returns the claims of the verified ID token.
+func (p *Provider) Exchange(ctx context.Context, code string, state *LoginState) (*Claims, error) {
+	config := *p.oauth2
+	config.ClientSecret = p.clientSecret()
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+
+	if idToken.Nonce != state.Nonce {
+		return nil, fmt.Errorf("id_token nonce mismatch")
+	}
+
+	var claims Claims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+
+	return &claims, nil
+}
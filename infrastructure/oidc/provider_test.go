@@ -0,0 +1,98 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/oidc"
+	"github.com/chai-rs/simple-bookstore/infrastructure/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider_Exchange(t *testing.T) {
+	type Testcase struct {
+		Name      string
+		Tamper    func(state *oidc.LoginState)
+		WantError bool
+	}
+
+	testcases := []Testcase{
+		{
+			Name:   "success",
+			Tamper: func(state *oidc.LoginState) {},
+		},
+		{
+			Name:      "wrong-code-verifier",
+			Tamper:    func(state *oidc.LoginState) { state.CodeVerifier = oidc.NewLoginState("stub").CodeVerifier },
+			WantError: true,
+		},
+		{
+			Name:      "wrong-nonce",
+			Tamper:    func(state *oidc.LoginState) { state.Nonce = "replayed" },
+			WantError: true,
+		},
+	}
+
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+	server.SetIdentity(oidctest.Identity{Subject: "subject-1", Email: "one@example.com", EmailVerified: true})
+
+	ctx := context.Background()
+	provider, err := oidc.NewProvider(ctx, &oidc.ProviderConfig{
+		Name:         "stub",
+		IssuerURL:    server.URL,
+		ClientID:     server.ClientID,
+		ClientSecret: func() string { return server.ClientSecret },
+		RedirectURL:  "http://localhost/callback",
+	})
+	assert.NoError(t, err)
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			state := oidc.NewLoginState(provider.Name())
+			authURL := provider.AuthCodeURL(state)
+
+			parsed, err := url.Parse(authURL)
+			assert.NoError(t, err)
+			assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+			assert.Equal(t, state.Nonce, parsed.Query().Get("nonce"))
+
+			code, returnedState, err := server.Authorize(authURL)
+			assert.NoError(t, err)
+			assert.Equal(t, state.State, returnedState)
+
+			tc.Tamper(state)
+			claims, err := provider.Exchange(ctx, code, state)
+			if tc.WantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "subject-1", claims.Subject)
+				assert.Equal(t, "one@example.com", claims.Email)
+				assert.True(t, claims.EmailVerified)
+			}
+		})
+	}
+}
+
+func TestMemoryStateStore(t *testing.T) {
+	ctx := context.Background()
+	store := oidc.NewMemoryStateStore()
+
+	state := oidc.NewLoginState("stub")
+	assert.NoError(t, store.Save(ctx, state, time.Minute))
+
+	taken, err := store.Take(ctx, state.State)
+	assert.NoError(t, err)
+	assert.Equal(t, state, taken)
+
+	_, err = store.Take(ctx, state.State)
+	assert.Error(t, err)
+
+	expired := oidc.NewLoginState("stub")
+	assert.NoError(t, store.Save(ctx, expired, -time.Second))
+	_, err = store.Take(ctx, expired.State)
+	assert.Error(t, err)
+}
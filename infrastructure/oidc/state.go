@@ -0,0 +1,102 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/oauth2"
+)
+
+// LoginState is kept between the redirect to a provider and its callback.
+type LoginState struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// NewLoginState creates a login state with random state, nonce and PKCE code verifier.
+func NewLoginState(provider string) *LoginState {
+	return &LoginState{
+		Provider:     provider,
+		State:        oauth2.GenerateVerifier(),
+		Nonce:        oauth2.GenerateVerifier(),
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}
+}
+
+// StateStore defines methods for keeping login states until the provider calls back.
+type StateStore interface {
+	Save(ctx context.Context, state *LoginState, ttl time.Duration) error
+	Take(ctx context.Context, state string) (*LoginState, error)
+}
+
+// RedisStateStore implements StateStore using Redis as backend.
+type RedisStateStore struct {
+	client *redis.Client
+}
+
+// NewRedisStateStore creates a new RedisStateStore instance.
+func NewRedisStateStore(client *redis.Client) *RedisStateStore {
+	return &RedisStateStore{client}
+}
+
+func (r *RedisStateStore) Save(ctx context.Context, state *LoginState, ttl time.Duration) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, stateKey(state.State), value, ttl).Err()
+}
+
+func (r *RedisStateStore) Take(ctx context.Context, state string) (*LoginState, error) {
+	value, err := r.client.GetDel(ctx, stateKey(state)).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired state")
+	}
+
+	var loginState LoginState
+	if err := json.Unmarshal(value, &loginState); err != nil {
+		return nil, err
+	}
+
+	return &loginState, nil
+}
+
+// MemoryStateStore implements StateStore using an in-memory map (for testing or local usage).
+type MemoryStateStore struct {
+	storage sync.Map
+}
+
+type memoryLoginState struct {
+	state     *LoginState
+	expiresAt time.Time
+}
+
+// NewMemoryStateStore creates a new MemoryStateStore instance.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{}
+}
+
+func (m *MemoryStateStore) Save(ctx context.Context, state *LoginState, ttl time.Duration) error {
+	m.storage.Store(stateKey(state.State), memoryLoginState{state, time.Now().Add(ttl)})
+	return nil
+}
+
+func (m *MemoryStateStore) Take(ctx context.Context, state string) (*LoginState, error) {
+	value, ok := m.storage.LoadAndDelete(stateKey(state))
+	if !ok || time.Now().After(value.(memoryLoginState).expiresAt) {
+		return nil, fmt.Errorf("invalid or expired state")
+	}
+
+	return value.(memoryLoginState).state, nil
+}
+
+func stateKey(state string) string {
+	return "oidc:state:" + state
+}
@@ -0,0 +1,253 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Period is a window requests are counted in. Periods follow UTC calendar days and months.
+type Period string
+
+const (
+	Day   = Period("day")
+	Month = Period("month")
+)
+
+// Periods lists the periods every request is counted in.
+var Periods = []Period{Day, Month}
+
+// Start returns the start of the period containing t.
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	if p == Month {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// End returns the end of the period containing t, which is the start of the next one.
+func (p Period) End(t time.Time) time.Time {
+	if p == Month {
+		return p.Start(t).AddDate(0, 1, 0)
+	}
+
+	return p.Start(t).AddDate(0, 0, 1)
+}
+
+// Count is the number of requests a subject made in one period.
+type Count struct {
+	Subject     string
+	Period      Period
+	PeriodStart time.Time
+	Count       int64
+}
+
+// Usage holds the request counts of a subject in the current day and month.
+type Usage map[Period]int64
+
+// Counter counts requests per subject, e.g. a user or an API key, in every period.
+// Drain returns the counts changed since the previous drain, so they can be stored.
+type Counter interface {
+	Increment(ctx context.Context, subject string, now time.Time) (Usage, error)
+	Decrement(ctx context.Context, subject string, now time.Time) error
+	Get(ctx context.Context, subject string, now time.Time) (Usage, error)
+	Drain(ctx context.Context) ([]Count, error)
+}
+
+// retention is how long counts are kept once their period is over, so they can still be drained.
+const retention = 24 * time.Hour
+
+// dirtyKey is the Redis set of counts changed since the last drain.
+const dirtyKey = "quota:dirty"
+
+// RedisCounter implements Counter using Redis as backend, so every instance shares the same counts.
+type RedisCounter struct {
+	client *redis.Client
+}
+
+// NewRedisCounter creates a new RedisCounter instance.
+func NewRedisCounter(client *redis.Client) *RedisCounter {
+	return &RedisCounter{client}
+}
+
+func (r *RedisCounter) Increment(ctx context.Context, subject string, now time.Time) (Usage, error) {
+	return r.add(ctx, subject, now, 1)
+}
+
+func (r *RedisCounter) Decrement(ctx context.Context, subject string, now time.Time) error {
+	_, err := r.add(ctx, subject, now, -1)
+	return err
+}
+
+// add changes every period's count of subject by delta and marks them for the next drain.
+func (r *RedisCounter) add(ctx context.Context, subject string, now time.Time, delta int64) (Usage, error) {
+	cmds := make(map[Period]*redis.IntCmd, len(Periods))
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, period := range Periods {
+			key := countKey(subject, period, now)
+			cmds[period] = pipe.IncrBy(ctx, key, delta)
+			pipe.ExpireAt(ctx, key, period.End(now).Add(retention))
+			pipe.SAdd(ctx, dirtyKey, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(Usage, len(cmds))
+	for period, cmd := range cmds {
+		usage[period] = cmd.Val()
+	}
+
+	return usage, nil
+}
+
+func (r *RedisCounter) Get(ctx context.Context, subject string, now time.Time) (Usage, error) {
+	usage := make(Usage, len(Periods))
+	for _, period := range Periods {
+		count, err := r.client.Get(ctx, countKey(subject, period, now)).Int64()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+
+		usage[period] = count
+	}
+
+	return usage, nil
+}
+
+// drainBatch is how many changed counts are popped from Redis at once.
+const drainBatch = 500
+
+func (r *RedisCounter) Drain(ctx context.Context) ([]Count, error) {
+	var counts []Count
+	for {
+		keys, err := r.client.SPopN(ctx, dirtyKey, drainBatch).Result()
+		if err != nil {
+			return counts, err
+		}
+
+		if len(keys) == 0 {
+			return counts, nil
+		}
+
+		values, err := r.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			// Put them back so the next drain picks them up.
+			r.client.SAdd(ctx, dirtyKey, keys)
+			return counts, err
+		}
+
+		for i, key := range keys {
+			raw, ok := values[i].(string)
+			if !ok {
+				continue
+			}
+
+			count, err := parseCount(key, raw)
+			if err != nil {
+				return counts, err
+			}
+
+			counts = append(counts, count)
+		}
+	}
+}
+
+// MemoryCounter implements Counter using an in-memory map (for testing or local usage).
+type MemoryCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	dirty  map[string]struct{}
+}
+
+// NewMemoryCounter creates a new MemoryCounter instance.
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{counts: map[string]int64{}, dirty: map[string]struct{}{}}
+}
+
+func (m *MemoryCounter) Increment(ctx context.Context, subject string, now time.Time) (Usage, error) {
+	return m.add(subject, now, 1), nil
+}
+
+func (m *MemoryCounter) Decrement(ctx context.Context, subject string, now time.Time) error {
+	m.add(subject, now, -1)
+	return nil
+}
+
+func (m *MemoryCounter) add(subject string, now time.Time, delta int64) Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := make(Usage, len(Periods))
+	for _, period := range Periods {
+		key := countKey(subject, period, now)
+		m.counts[key] += delta
+		m.dirty[key] = struct{}{}
+		usage[period] = m.counts[key]
+	}
+
+	return usage
+}
+
+func (m *MemoryCounter) Get(ctx context.Context, subject string, now time.Time) (Usage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := make(Usage, len(Periods))
+	for _, period := range Periods {
+		usage[period] = m.counts[countKey(subject, period, now)]
+	}
+
+	return usage, nil
+}
+
+func (m *MemoryCounter) Drain(ctx context.Context) ([]Count, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make([]Count, 0, len(m.dirty))
+	for key := range m.dirty {
+		count, err := parseCount(key, fmt.Sprint(m.counts[key]))
+		if err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, count)
+		delete(m.dirty, key)
+	}
+
+	return counts, nil
+}
+
+// countKey returns the key counting the requests of subject in the period containing now.
+// The subject comes last since it may itself contain colons.
+func countKey(subject string, period Period, now time.Time) string {
+	return fmt.Sprintf("quota:%s:%d:%s", period, period.Start(now).Unix(), subject)
+}
+
+// parseCount parses a key made by countKey and its value.
+func parseCount(key string, value string) (Count, error) {
+	parts := strings.SplitN(key, ":", 4)
+	if len(parts) != 4 || parts[0] != "quota" {
+		return Count{}, fmt.Errorf("invalid quota key %q", key)
+	}
+
+	var start, count int64
+	if _, err := fmt.Sscan(parts[2], &start); err != nil {
+		return Count{}, fmt.Errorf("invalid quota key %q: %w", key, err)
+	}
+
+	if _, err := fmt.Sscan(value, &count); err != nil {
+		return Count{}, fmt.Errorf("invalid quota count %q: %w", value, err)
+	}
+
+	return Count{Subject: parts[3], Period: Period(parts[1]), PeriodStart: time.Unix(start, 0).UTC(), Count: count}, nil
+}
@@ -0,0 +1,49 @@
+package quota_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/quota"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod(t *testing.T) {
+	now := time.Date(2026, time.December, 31, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+
+	assert.Equal(t, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), quota.Day.Start(now), "periods follow UTC")
+	assert.Equal(t, time.Date(2027, time.January, 2, 0, 0, 0, 0, time.UTC), quota.Day.End(now))
+	assert.Equal(t, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), quota.Month.Start(now))
+	assert.Equal(t, time.Date(2027, time.February, 1, 0, 0, 0, 0, time.UTC), quota.Month.End(now))
+}
+
+func TestMemoryCounter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	tomorrow := now.Add(24 * time.Hour)
+
+	counter := quota.NewMemoryCounter()
+	counter.Increment(ctx, "user:one", now)
+	counter.Increment(ctx, "user:one", now)
+	usage, err := counter.Increment(ctx, "user:one", tomorrow)
+	assert.NoError(t, err)
+	assert.Equal(t, quota.Usage{quota.Day: 1, quota.Month: 3}, usage)
+
+	assert.NoError(t, counter.Decrement(ctx, "user:one", tomorrow))
+	usage, err = counter.Get(ctx, "user:one", tomorrow)
+	assert.NoError(t, err)
+	assert.Equal(t, quota.Usage{quota.Day: 0, quota.Month: 2}, usage)
+
+	counts, err := counter.Drain(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []quota.Count{
+		{Subject: "user:one", Period: quota.Day, PeriodStart: quota.Day.Start(now), Count: 2},
+		{Subject: "user:one", Period: quota.Day, PeriodStart: quota.Day.Start(tomorrow), Count: 0},
+		{Subject: "user:one", Period: quota.Month, PeriodStart: quota.Month.Start(now), Count: 2},
+	}, counts)
+
+	counts, err = counter.Drain(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey holds the span of a query in the statement settings.
+const spanKey = "tracing:span"
+
+// gormPlugin traces every query made through GORM as a child span of the query context.
+type gormPlugin struct{}
+
+// NewGormPlugin creates a GORM plugin tracing queries. Use db.WithContext so queries join the request trace.
+func NewGormPlugin() gorm.Plugin {
+	return gormPlugin{}
+}
+
+func (gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("tracing:before_create", startQuery("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", endQuery),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", startQuery("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", endQuery),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", startQuery("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", endQuery),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", startQuery("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", endQuery),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", startQuery("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", endQuery),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", startQuery("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", endQuery),
+	)
+}
+
+func startQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// endQuery ends the span started by startQuery. The statement is recorded with its placeholders, never its values.
+func endQuery(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+
+	span := value.(trace.Span)
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
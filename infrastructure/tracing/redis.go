@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redisHook traces every command sent through a Redis client as a child span of the command context.
+type redisHook struct{}
+
+// NewRedisHook creates a Redis hook tracing commands. Arguments are not recorded, as they may hold tokens.
+func NewRedisHook() redis.Hook {
+	return redisHook{}
+}
+
+func (redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := startCommand(ctx, cmd.Name())
+		err := next(ctx, cmd)
+		endCommand(span, err)
+		return err
+	}
+}
+
+func (redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := startCommand(ctx, "pipeline")
+		span.SetAttributes(attribute.Int("db.redis.num_cmd", len(cmds)))
+		err := next(ctx, cmds)
+		endCommand(span, err)
+		return err
+	}
+}
+
+func startCommand(ctx context.Context, command string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "redis."+command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationName(command)),
+	)
+}
+
+// endCommand ends the span of a command. Missing keys are not failures.
+func endCommand(span trace.Span, err error) {
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	End(span, err)
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the spans started in this repository.
+const instrumentationName = "github.com/chai-rs/simple-bookstore"
+
+// tracer returns the tracer of the current provider, which providers cache by name.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// ProviderOpts contains optional settings for Init.
+type ProviderOpts struct {
+	// ServiceName names the service in every span. Defaults to "simple-bookstore".
+	ServiceName string
+	// SampleRatio is the share of traces started here that are recorded, between 0 and 1. Traces
+	// continued from an incoming traceparent follow the caller's decision. Defaults to 1.
+	SampleRatio float64
+}
+
+// DefaultProviderOpts provides default settings for Init.
+var DefaultProviderOpts = &ProviderOpts{
+	ServiceName: "simple-bookstore",
+	SampleRatio: 1,
+}
+
+// Init propagates W3C traceparent and baggage headers and sends spans to exporter. When exporter is nil,
+// trace context is still propagated but no span is recorded. The returned function flushes the pending spans
+// and must be called on shutdown.
+func Init(exporter sdktrace.SpanExporter, opts ...*ProviderOpts) func(ctx context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if exporter == nil {
+		return func(context.Context) error { return nil }
+	}
+
+	option := DefaultProviderOpts
+	if len(opts) > 0 {
+		option = opts[0]
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(option.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(option.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown
+}
+
+// NewOTLPExporter creates an exporter sending spans to an OpenTelemetry collector over HTTP. An empty endpoint
+// falls back to the OTEL_EXPORTER_OTLP_ENDPOINT variable, then to http://localhost:4318.
+func NewOTLPExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	var options []otlptracehttp.Option
+	if endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+	}
+
+	return otlptracehttp.New(ctx, options...)
+}
+
+// NewStdoutExporter creates an exporter writing spans to stdout, for local development.
+func NewStdoutExporter() (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithPrettyPrint())
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServer starts a span named name for a request received by the server, as a child of the
+// caller's span in ctx, if any.
+func StartServer(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it failed when err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the ID of the trace ctx belongs to, or an empty string when it belongs to none.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
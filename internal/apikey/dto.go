@@ -0,0 +1,52 @@
+package apikey
+
+import (
+	"strings"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/internal/model"
+)
+
+// CreateKeyRequestDTO represents the request payload for creating an API key.
+// ExpiresAt defaults to 90 days from now.
+type CreateKeyRequestDTO struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ToAPIKey converts CreateKeyRequestDTO to an APIKey model.
+func (r *CreateKeyRequestDTO) ToAPIKey() *model.APIKey {
+	return &model.APIKey{
+		Name:      r.Name,
+		Scopes:    strings.Join(r.Scopes, " "),
+		ExpiresAt: r.ExpiresAt,
+	}
+}
+
+// KeyResponseDTO represents an API key. Key is only returned on creation.
+type KeyResponseDTO struct {
+	ID         string     `json:"id"`
+	Key        string     `json:"key,omitempty" sensitive:"true"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+}
+
+// NewKeyResponseDTO converts an APIKey model to KeyResponseDTO.
+func NewKeyResponseDTO(key *model.APIKey) KeyResponseDTO {
+	return KeyResponseDTO{
+		ID:         key.ID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     strings.Fields(key.Scopes),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,121 @@
+package apikey
+
+import (
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+// CreateKey godoc
+// @Summary Create API key
+// @Description Create a named, scoped and expiring API key for the current user. The key is only returned once; send it as "Authorization: ApiKey <key>".
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body CreateKeyRequestDTO true "API key"
+// @Success 201 {object} KeyResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api-keys [post]
+func (h *Handler) CreateKey(c *gin.Context) {
+	var req CreateKeyRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	key := req.ToAPIKey()
+	plain, err := h.service.CreateKey(c.Request.Context(), metadata.UserID, key)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	res := NewKeyResponseDTO(key)
+	res.Key = plain
+	utils.ResponseCreated(c, res)
+}
+
+// ListKeys godoc
+// @Summary List API keys
+// @Description List the API keys of the current user, including revoked and expired ones
+// @Tags api-keys
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} KeyResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api-keys [get]
+func (h *Handler) ListKeys(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	keys, err := h.service.ListKeys(c.Request.Context(), metadata.UserID)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	res := make([]KeyResponseDTO, len(keys))
+	for i := range keys {
+		res[i] = NewKeyResponseDTO(&keys[i])
+	}
+
+	utils.ResponseOk(c, res)
+}
+
+// RevokeKey godoc
+// @Summary Revoke API key
+// @Description Revoke an API key. Users may revoke their own keys, admins any key.
+// @Tags api-keys
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "API key ID"
+// @Success 200 {object} nil
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api-keys/{id} [delete]
+func (h *Handler) RevokeKey(c *gin.Context) {
+	if err := h.service.RevokeKey(c.Request.Context(), c.Param("id")); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// LoadKey loads the key named by the id path parameter for middleware.AuthorizeResource.
+func (h *Handler) LoadKey(c *gin.Context) (*auth.AuthResource, error) {
+	key, err := h.service.GetKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.AuthResource{
+		Object: auth.APIKeys,
+		ID:     key.ID.String(),
+		Owner:  key.UserID.String(),
+	}, nil
+}
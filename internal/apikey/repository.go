@@ -0,0 +1,91 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"gorm.io/gorm"
+)
+
+// Repository represents the API key repository interface.
+type Repository interface {
+	CreateKey(ctx context.Context, key *model.APIKey) error
+	GetKey(ctx context.Context, id string) (*model.APIKey, error)
+	GetKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error)
+	ListKeys(ctx context.Context, userID string) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, id string) (*model.APIKey, error)
+	TouchKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// repository implements the Repository interface.
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) CreateKey(ctx context.Context, key *model.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return errs.FromGorm(err)
+	}
+	return nil
+}
+
+func (r *repository) GetKey(ctx context.Context, id string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return &key, nil
+}
+
+func (r *repository) GetKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.WithContext(ctx).Preload("User").Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return &key, nil
+}
+
+func (r *repository) ListKeys(ctx context.Context, userID string) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&keys).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return keys, nil
+}
+
+func (r *repository) RevokeKey(ctx context.Context, id string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.WithContext(ctx).Where("id = ? AND revoked_at IS NULL", id).First(&key).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&model.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", now)
+	if result.Error != nil {
+		return nil, errs.FromGorm(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, errs.New(http.StatusNotFound, gorm.ErrRecordNotFound, "api key not found")
+	}
+
+	key.RevokedAt = &now
+	return &key, nil
+}
+
+func (r *repository) TouchKey(ctx context.Context, id string, usedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&model.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error; err != nil {
+		return errs.FromGorm(err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultTTL is how long a key is valid when no expiry is requested.
+	DefaultTTL = 90 * 24 * time.Hour
+	// MaxTTL is the longest lifetime a key may be created with.
+	MaxTTL = 365 * 24 * time.Hour
+	// LastUsedInterval throttles last-used tracking so busy keys do not write on every request.
+	LastUsedInterval = time.Minute
+)
+
+// Service represents the API key service interface. It also verifies keys for the auth middleware.
+type Service interface {
+	auth.APIKeyVerifier
+	CreateKey(ctx context.Context, userID string, key *model.APIKey) (string, error)
+	GetKey(ctx context.Context, id string) (*model.APIKey, error)
+	ListKeys(ctx context.Context, userID string) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, id string) error
+}
+
+// service implements the Service interface
+type service struct {
+	repo     Repository
+	enforcer auth.AuthEnforcer
+}
+
+func NewService(repo Repository, enforcer auth.AuthEnforcer) *service {
+	return &service{repo, enforcer}
+}
+
+// CreateKey validates and stores a new key for the user, returning the plain key. It is never retrievable again.
+func (s *service) CreateKey(ctx context.Context, userID string, key *model.APIKey) (string, error) {
+	scopes, err := auth.ParseScopes(key.Scopes)
+	if err != nil {
+		return "", errs.BadRequest(err.Error())
+	}
+
+	if len(scopes) == 0 {
+		return "", errs.BadRequest("at least one scope is required")
+	}
+
+	now := time.Now()
+	if key.ExpiresAt == nil {
+		expiresAt := now.Add(DefaultTTL)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if !key.ExpiresAt.After(now) {
+		return "", errs.BadRequest("expires_at must be in the future")
+	}
+
+	if key.ExpiresAt.Sub(now) > MaxTTL {
+		return "", errs.BadRequest(fmt.Sprintf("expires_at must be within %d days", int(MaxTTL.Hours()/24)))
+	}
+
+	// A key may never be granted more than its owner holds.
+	for _, scope := range scopes {
+		obj, act := scope.Split()
+		ok, err := s.enforcer.Enforce(userID, obj, act)
+		if err != nil {
+			return "", err
+		}
+
+		if !ok {
+			return "", errs.New(http.StatusForbidden, fmt.Errorf("user lacks %s", scope), fmt.Sprintf("you are not allowed to grant %s", scope))
+		}
+	}
+
+	plain, prefix := auth.NewAPIKey()
+	key.ID = uuid.New()
+	key.UserID = uuid.MustParse(userID)
+	key.Prefix = prefix
+	key.HashedKey = auth.HashAPIKey(plain)
+	key.Scopes = auth.JoinScopes(scopes)
+
+	if err := s.repo.CreateKey(ctx, key); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to create api key")
+		return "", err
+	}
+
+	for _, scope := range scopes {
+		obj, act := scope.Split()
+		if err := s.enforcer.AddPolicy(ctx, auth.APIKeySubject(key.ID.String()), obj, act); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("api_key_id", key.ID.String()).Msg("🚨 failed to add api key policy")
+			return "", err
+		}
+	}
+
+	return plain, nil
+}
+
+func (s *service) ListKeys(ctx context.Context, userID string) ([]model.APIKey, error) {
+	keys, err := s.repo.ListKeys(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list api keys")
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *service) GetKey(ctx context.Context, id string) (*model.APIKey, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errs.New(http.StatusNotFound, err, "api key not found")
+	}
+
+	key, err := s.repo.GetKey(ctx, id)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get api key")
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// RevokeKey revokes a key. Callers are expected to have checked the key's owner, see Handler.LoadKey.
+func (s *service) RevokeKey(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return errs.New(http.StatusNotFound, err, "api key not found")
+	}
+
+	key, err := s.repo.RevokeKey(ctx, id)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to revoke api key")
+		}
+		return err
+	}
+
+	scopes, _ := auth.ParseScopes(key.Scopes)
+	for _, scope := range scopes {
+		obj, act := scope.Split()
+		if err := s.enforcer.RemovePolicy(ctx, auth.APIKeySubject(id), obj, act); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("api_key_id", id).Msg("🚨 failed to remove api key policy")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VerifyAPIKey resolves a presented key to its owner and scopes, recording when it was last used.
+func (s *service) VerifyAPIKey(ctx context.Context, plain string) (*auth.AccessProperties, error) {
+	invalid := errs.New(http.StatusUnauthorized, fmt.Errorf("invalid api key"), "invalid api key")
+
+	prefix, ok := auth.SplitAPIKey(plain)
+	if !ok {
+		return nil, invalid
+	}
+
+	key, err := s.repo.GetKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil, invalid
+		}
+
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get api key")
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(auth.HashAPIKey(plain)), []byte(key.HashedKey)) != 1 {
+		return nil, invalid
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, errs.New(http.StatusUnauthorized, fmt.Errorf("api key %s is revoked or expired", key.ID), "api key has been revoked or has expired")
+	}
+
+	if key.User != nil && key.User.Disabled() {
+		return nil, errs.New(http.StatusUnauthorized, fmt.Errorf("owner of api key %s is disabled", key.ID), "account is disabled")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= LastUsedInterval {
+		if err := s.repo.TouchKey(ctx, key.ID.String(), now); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("api_key_id", key.ID.String()).Msg("⚠️ failed to record api key usage")
+		}
+	}
+
+	scopes, err := auth.ParseScopes(key.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := &auth.AccessProperties{
+		UserID:   key.UserID.String(),
+		APIKeyID: key.ID.String(),
+		Scopes:   scopes,
+	}
+
+	if key.User != nil {
+		properties.Email = key.User.Email
+	}
+
+	return properties, nil
+}
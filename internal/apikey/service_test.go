@@ -0,0 +1,221 @@
+package apikey_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/internal/apikey"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+)
+
+const userID = "123e4567-e89b-12d3-a456-426614174000"
+
+func TestService_CreateKey(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		In         *model.APIKey
+		Allowed    bool
+		WantStatus int
+	}
+
+	testcases := []Testcase{
+		{
+			Name:    "success",
+			In:      &model.APIKey{Name: "ci", Scopes: "resource:read resource:read"},
+			Allowed: true,
+		},
+		{
+			Name:    "explicit-expiry",
+			In:      &model.APIKey{Name: "ci", Scopes: "resource:write", ExpiresAt: pointy.Pointer(time.Now().Add(24 * time.Hour))},
+			Allowed: true,
+		},
+		{
+			Name:       "no-scope",
+			In:         &model.APIKey{Name: "ci"},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "unknown-scope",
+			In:         &model.APIKey{Name: "ci", Scopes: "orders:read"},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "expired",
+			In:         &model.APIKey{Name: "ci", Scopes: "resource:read", ExpiresAt: pointy.Pointer(time.Now().Add(-time.Hour))},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "too-long",
+			In:         &model.APIKey{Name: "ci", Scopes: "resource:read", ExpiresAt: pointy.Pointer(time.Now().Add(2 * apikey.MaxTTL))},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "user-lacks-permission",
+			In:         &model.APIKey{Name: "ci", Scopes: "resource:write"},
+			WantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			repo := apikey.NewMockRepository(t)
+			repo.EXPECT().CreateKey(mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			enforcer := auth.NewMockAuthEnforcer(t)
+			enforcer.EXPECT().Enforce(userID, mock.Anything, mock.Anything).Return(tc.Allowed, nil).Maybe()
+			enforcer.EXPECT().AddPolicy(mock.Anything, mock.Anything, mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, sub string, obj auth.AuthObject, act auth.AuthAction) error {
+				assert.Equal(t, auth.APIKeySubject(tc.In.ID.String()), sub)
+				return nil
+			}).Maybe()
+
+			svc := apikey.NewService(repo, enforcer)
+			plain, err := svc.CreateKey(context.Background(), userID, tc.In)
+
+			if tc.WantStatus != 0 {
+				assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			prefix, ok := auth.SplitAPIKey(plain)
+			assert.True(t, ok)
+			assert.Equal(t, tc.In.Prefix, prefix)
+			assert.Equal(t, auth.HashAPIKey(plain), tc.In.HashedKey)
+			assert.NotContains(t, tc.In.HashedKey, plain)
+			assert.NotNil(t, tc.In.ExpiresAt)
+		})
+	}
+}
+
+func TestService_VerifyAPIKey(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		Key        func(plain string) string
+		Stored     func(key *model.APIKey)
+		WantStatus int
+		WantTouch  bool
+	}
+
+	testcases := []Testcase{
+		{
+			Name:      "success",
+			Key:       func(plain string) string { return plain },
+			WantTouch: true,
+		},
+		{
+			Name: "recently-used",
+			Key:  func(plain string) string { return plain },
+			Stored: func(key *model.APIKey) {
+				key.LastUsedAt = pointy.Pointer(time.Now().Add(-time.Second))
+			},
+		},
+		{
+			Name:       "wrong-secret",
+			Key:        func(plain string) string { return plain + "x" },
+			WantStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:       "malformed",
+			Key:        func(plain string) string { return "not-a-key" },
+			WantStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:       "unknown-prefix",
+			Key:        func(plain string) string { return "bks_unknown_secret" },
+			WantStatus: http.StatusUnauthorized,
+		},
+		{
+			Name: "revoked",
+			Key:  func(plain string) string { return plain },
+			Stored: func(key *model.APIKey) {
+				key.RevokedAt = pointy.Pointer(time.Now())
+			},
+			WantStatus: http.StatusUnauthorized,
+		},
+		{
+			Name: "expired",
+			Key:  func(plain string) string { return plain },
+			Stored: func(key *model.APIKey) {
+				key.ExpiresAt = pointy.Pointer(time.Now().Add(-time.Minute))
+			},
+			WantStatus: http.StatusUnauthorized,
+		},
+		{
+			Name: "owner-disabled",
+			Key:  func(plain string) string { return plain },
+			Stored: func(key *model.APIKey) {
+				key.User.Status = model.UserDisabled
+			},
+			WantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			plain, prefix := auth.NewAPIKey()
+			stored := &model.APIKey{
+				ID:        uuid.New(),
+				UserID:    uuid.MustParse(userID),
+				Prefix:    prefix,
+				HashedKey: auth.HashAPIKey(plain),
+				Scopes:    "resource:read",
+				ExpiresAt: pointy.Pointer(time.Now().Add(time.Hour)),
+				User:      &model.User{Email: "one@example.com"},
+			}
+			if tc.Stored != nil {
+				tc.Stored(stored)
+			}
+
+			repo := apikey.NewMockRepository(t)
+			repo.EXPECT().GetKeyByPrefix(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, p string) (*model.APIKey, error) {
+				if p == prefix {
+					return stored, nil
+				}
+				return nil, errs.FromGorm(gorm.ErrRecordNotFound)
+			}).Maybe()
+			if tc.WantTouch {
+				repo.EXPECT().TouchKey(mock.Anything, stored.ID.String(), mock.Anything).Return(nil).Once()
+			}
+
+			svc := apikey.NewService(repo, auth.NewMockAuthEnforcer(t))
+			properties, err := svc.VerifyAPIKey(context.Background(), tc.Key(plain))
+
+			if tc.WantStatus != 0 {
+				assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, userID, properties.UserID)
+			assert.Equal(t, "one@example.com", properties.Email)
+			assert.Equal(t, stored.ID.String(), properties.APIKeyID)
+			assert.Equal(t, []auth.Scope{"resource:read"}, properties.Scopes)
+		})
+	}
+}
+
+func TestService_RevokeKey(t *testing.T) {
+	id := uuid.New()
+
+	repo := apikey.NewMockRepository(t)
+	repo.EXPECT().RevokeKey(mock.Anything, id.String()).Return(&model.APIKey{ID: id, Scopes: "resource:read resource:write"}, nil).Once()
+	repo.EXPECT().RevokeKey(mock.Anything, mock.Anything).Return(nil, errs.FromGorm(gorm.ErrRecordNotFound)).Once()
+
+	enforcer := auth.NewMockAuthEnforcer(t)
+	enforcer.EXPECT().RemovePolicy(mock.Anything, auth.APIKeySubject(id.String()), auth.Resource, auth.Read).Return(nil).Once()
+	enforcer.EXPECT().RemovePolicy(mock.Anything, auth.APIKeySubject(id.String()), auth.Resource, auth.Write).Return(nil).Once()
+
+	svc := apikey.NewService(repo, enforcer)
+	assert.NoError(t, svc.RevokeKey(context.Background(), id.String()))
+	assert.Equal(t, http.StatusNotFound, errs.StatusOf(svc.RevokeKey(context.Background(), uuid.NewString())))
+	assert.Equal(t, http.StatusNotFound, errs.StatusOf(svc.RevokeKey(context.Background(), "not-a-uuid")))
+}
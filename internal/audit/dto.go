@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/internal/model"
+)
+
+// ListEventsRequestDTO represents the query filters for listing audit events.
+// From and To are RFC 3339 times, From inclusive and To exclusive.
+type ListEventsRequestDTO struct {
+	UserID string     `form:"user_id"`
+	Type   string     `form:"type"`
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit  int        `form:"limit"`
+}
+
+// ToEventFilter converts ListEventsRequestDTO to an EventFilter.
+func (r *ListEventsRequestDTO) ToEventFilter() *EventFilter {
+	return &EventFilter{
+		UserID: r.UserID,
+		Type:   r.Type,
+		From:   r.From,
+		To:     r.To,
+		Limit:  r.Limit,
+	}
+}
+
+// EventResponseDTO represents an audit event.
+type EventResponseDTO struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Outcome   string     `json:"outcome"`
+	ActorID   string     `json:"actor_id,omitempty"`
+	Target    string     `json:"target,omitempty"`
+	Details   string     `json:"details,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	RequestID string     `json:"request_id,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// NewEventResponseDTO converts an AuditEvent model to EventResponseDTO.
+func NewEventResponseDTO(event *model.AuditEvent) EventResponseDTO {
+	return EventResponseDTO{
+		ID:        event.ID.String(),
+		Type:      event.Type,
+		Outcome:   event.Outcome,
+		ActorID:   event.ActorID,
+		Target:    event.Target,
+		Details:   event.Details,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		RequestID: event.RequestID,
+		CreatedAt: event.CreatedAt,
+	}
+}
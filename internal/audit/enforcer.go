@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+)
+
+// auditedEnforcer wraps an auth.AuthEnforcer, recording every policy, grouping and role change it makes.
+type auditedEnforcer struct {
+	auth.AuthEnforcer
+	recorder Recorder
+}
+
+// NewEnforcer returns enforcer with its policy, grouping and role changes recorded to recorder.
+func NewEnforcer(enforcer auth.AuthEnforcer, recorder Recorder) *auditedEnforcer {
+	return &auditedEnforcer{enforcer, recorder}
+}
+
+func (e *auditedEnforcer) AddPolicy(ctx context.Context, sub string, obj auth.AuthObject, act auth.AuthAction) error {
+	err := e.AuthEnforcer.AddPolicy(ctx, sub, obj, act)
+	e.record(ctx, PolicyAdd, err, sub, obj.String()+" "+act.String())
+	return err
+}
+
+func (e *auditedEnforcer) RemovePolicy(ctx context.Context, sub string, obj auth.AuthObject, act auth.AuthAction) error {
+	err := e.AuthEnforcer.RemovePolicy(ctx, sub, obj, act)
+	e.record(ctx, PolicyRemove, err, sub, obj.String()+" "+act.String())
+	return err
+}
+
+func (e *auditedEnforcer) AddRoleForUser(ctx context.Context, user string, role auth.Role) error {
+	err := e.AuthEnforcer.AddRoleForUser(ctx, user, role)
+	e.record(ctx, RoleGrant, err, user, role.String())
+	return err
+}
+
+func (e *auditedEnforcer) DeleteRoleForUser(ctx context.Context, user string, role auth.Role) error {
+	err := e.AuthEnforcer.DeleteRoleForUser(ctx, user, role)
+	e.record(ctx, RoleRevoke, err, user, role.String())
+	return err
+}
+
+func (e *auditedEnforcer) AddGrouping(ctx context.Context, member string, group string) error {
+	err := e.AuthEnforcer.AddGrouping(ctx, member, group)
+	e.record(ctx, GroupingAdd, err, member, group)
+	return err
+}
+
+func (e *auditedEnforcer) RemoveGrouping(ctx context.Context, member string, group string) error {
+	err := e.AuthEnforcer.RemoveGrouping(ctx, member, group)
+	e.record(ctx, GroupingRemove, err, member, group)
+	return err
+}
+
+func (e *auditedEnforcer) record(ctx context.Context, eventType string, err error, target string, details string) {
+	e.recorder.Record(ctx, &model.AuditEvent{
+		Type:    eventType,
+		Outcome: OutcomeOf(err),
+		Target:  target,
+		Details: details,
+	})
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+// ListEvents godoc
+// @Summary List audit events
+// @Description List security-relevant events, newest first, optionally filtered by user (actor or target), type and time range. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param user_id query string false "User ID, matching events by or about the user"
+// @Param type query string false "Event type, e.g. user.login"
+// @Param from query string false "Earliest time (RFC 3339), inclusive"
+// @Param to query string false "Latest time (RFC 3339), exclusive"
+// @Param limit query int false "Maximum number of events (default 100, max 1000)"
+// @Success 200 {array} EventResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/audit-events [get]
+func (h *Handler) ListEvents(c *gin.Context) {
+	var req ListEventsRequestDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	events, err := h.service.ListEvents(c.Request.Context(), req.ToEventFilter())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	res := make([]EventResponseDTO, len(events))
+	for i := range events {
+		res[i] = NewEventResponseDTO(&events[i])
+	}
+
+	utils.ResponseOk(c, res)
+}
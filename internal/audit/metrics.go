@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+)
+
+// meteredRecorder wraps a Recorder, counting logins, token refreshes and every other event it records.
+type meteredRecorder struct {
+	Recorder
+}
+
+// NewMeteredRecorder returns recorder with every event also counted in metrics.AuthEvents by type and outcome.
+func NewMeteredRecorder(recorder Recorder) *meteredRecorder {
+	return &meteredRecorder{recorder}
+}
+
+func (r *meteredRecorder) Record(ctx context.Context, event *model.AuditEvent) {
+	metrics.AuthEvents.WithLabelValues(event.Type, event.Outcome).Inc()
+	r.Recorder.Record(ctx, event)
+}
@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Event types recorded in the audit log.
+const (
+	Login          = "user.login"
+	LoginTOTP      = "user.login_totp"
+	LoginOIDC      = "user.login_oidc"
+	Register       = "user.register"
+	Logout         = "user.logout"
+	TokenRefresh   = "user.token_refresh"
+	PasswordChange = "user.password_change"
+	EmailChange    = "user.email_change"
+	AccountDelete  = "user.account_delete"
+	AccountDisable = "user.account_disable"
+	AccountEnable  = "user.account_enable"
+	PasswordForce  = "user.password_reset_forced"
+	RoleGrant      = "role.grant"
+	RoleRevoke     = "role.revoke"
+	PolicyAdd      = "policy.add"
+	PolicyRemove   = "policy.remove"
+	GroupingAdd    = "grouping.add"
+	GroupingRemove = "grouping.remove"
+)
+
+// Event outcomes.
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+// EventTypes lists every event type, so queries can reject unknown ones.
+var EventTypes = []string{
+	Login, LoginTOTP, LoginOIDC, Register, Logout, TokenRefresh, PasswordChange, EmailChange, AccountDelete,
+	AccountDisable, AccountEnable, PasswordForce,
+	RoleGrant, RoleRevoke, PolicyAdd, PolicyRemove, GroupingAdd, GroupingRemove,
+}
+
+// Recorder records security-relevant events. Failures are logged, never returned,
+// so that auditing cannot fail the action being audited.
+type Recorder interface {
+	Record(ctx context.Context, event *model.AuditEvent)
+}
+
+// OutcomeOf returns the outcome of an action that returned err.
+func OutcomeOf(err error) string {
+	if err != nil {
+		return Failure
+	}
+
+	return Success
+}
+
+// recorder implements Recorder by storing events with the repository.
+type recorder struct {
+	repo Repository
+}
+
+func NewRecorder(repo Repository) *recorder {
+	return &recorder{repo}
+}
+
+func (r *recorder) Record(ctx context.Context, event *model.AuditEvent) {
+	withRequest(ctx, event)
+	if err := r.repo.CreateEvent(context.WithoutCancel(ctx), event); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("type", event.Type).Str("outcome", event.Outcome).Msg("🚨 failed to record audit event")
+	}
+}
+
+// MemoryRecorder implements Recorder by keeping events in memory, for tests and single instance setups without a database.
+type MemoryRecorder struct {
+	mu     sync.Mutex
+	events []model.AuditEvent
+}
+
+// NewMemoryRecorder creates a new MemoryRecorder instance.
+func NewMemoryRecorder() *MemoryRecorder {
+	return &MemoryRecorder{}
+}
+
+func (r *MemoryRecorder) Record(ctx context.Context, event *model.AuditEvent) {
+	withRequest(ctx, event)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, *event)
+}
+
+// Events returns the recorded events, oldest first.
+func (r *MemoryRecorder) Events() []model.AuditEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]model.AuditEvent, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// withRequest fills the event ID and time, and the actor and client details carried by ctx that the event leaves empty.
+func withRequest(ctx context.Context, event *model.AuditEvent) {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+
+	if event.CreatedAt == nil {
+		now := time.Now()
+		event.CreatedAt = &now
+	}
+
+	if properties, ok := auth.AccessPropertiesFromContext(ctx); ok && event.ActorID == "" {
+		event.ActorID = properties.UserID
+	}
+
+	client := utils.ClientInfoFromContext(ctx)
+	if event.IP == "" {
+		event.IP = client.IP
+	}
+
+	if event.UserAgent == "" {
+		event.UserAgent = client.UserAgent
+	}
+
+	if event.RequestID == "" {
+		event.RequestID = client.RequestID
+	}
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"gorm.io/gorm"
+)
+
+// EventFilter narrows an audit event query. Empty fields match anything.
+// UserID matches events either performed by the user or targeting them.
+type EventFilter struct {
+	UserID string
+	Type   string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+}
+
+// Repository represents the audit event repository interface. Events can only be added, never changed.
+type Repository interface {
+	CreateEvent(ctx context.Context, event *model.AuditEvent) error
+	ListEvents(ctx context.Context, filter *EventFilter) ([]model.AuditEvent, error)
+}
+
+// repository implements the Repository interface.
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) CreateEvent(ctx context.Context, event *model.AuditEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return errs.FromGorm(err)
+	}
+	return nil
+}
+
+// ListEvents returns the events matching filter, newest first.
+func (r *repository) ListEvents(ctx context.Context, filter *EventFilter) ([]model.AuditEvent, error) {
+	query := r.db.WithContext(ctx).Model(&model.AuditEvent{})
+	if filter.UserID != "" {
+		query = query.Where("actor_id = ? OR target = ?", filter.UserID, filter.UserID)
+	}
+
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var events []model.AuditEvent
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Find(&events).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return events, nil
+}
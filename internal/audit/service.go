@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultLimit is how many events a query returns when no limit is given.
+	DefaultLimit = 100
+	// MaxLimit is the most events a single query may return.
+	MaxLimit = 1000
+)
+
+// Service represents the audit log query service interface.
+type Service interface {
+	ListEvents(ctx context.Context, filter *EventFilter) ([]model.AuditEvent, error)
+}
+
+// service implements the Service interface
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *service {
+	return &service{repo}
+}
+
+// ListEvents returns the events matching filter, newest first.
+func (s *service) ListEvents(ctx context.Context, filter *EventFilter) ([]model.AuditEvent, error) {
+	if filter.Type != "" && !slices.Contains(EventTypes, filter.Type) {
+		return nil, errs.BadRequest(fmt.Sprintf("unknown event type %q", filter.Type))
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errs.BadRequest("from must be before to")
+	}
+
+	if filter.Limit < 0 || filter.Limit > MaxLimit {
+		return nil, errs.BadRequest(fmt.Sprintf("limit must be between 1 and %d", MaxLimit))
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultLimit
+	}
+
+	events, err := s.repo.ListEvents(ctx, filter)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list audit events")
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,127 @@
+package audit_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/internal/audit"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+)
+
+const userID = "123e4567-e89b-12d3-a456-426614174000"
+
+func TestService_ListEvents(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		In         *audit.EventFilter
+		WantLimit  int
+		WantStatus int
+	}
+
+	now := time.Now()
+	testcases := []Testcase{
+		{
+			Name:      "default-limit",
+			In:        &audit.EventFilter{UserID: userID, Type: audit.Login},
+			WantLimit: audit.DefaultLimit,
+		},
+		{
+			Name:      "time-range",
+			In:        &audit.EventFilter{From: pointy.Pointer(now.Add(-time.Hour)), To: pointy.Pointer(now), Limit: 10},
+			WantLimit: 10,
+		},
+		{
+			Name:       "unknown-type",
+			In:         &audit.EventFilter{Type: "user.deleted"},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "inverted-range",
+			In:         &audit.EventFilter{From: pointy.Pointer(now), To: pointy.Pointer(now.Add(-time.Hour))},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "limit-too-large",
+			In:         &audit.EventFilter{Limit: audit.MaxLimit + 1},
+			WantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			repo := audit.NewMockRepository(t)
+			if tc.WantStatus == 0 {
+				repo.EXPECT().ListEvents(mock.Anything, tc.In).RunAndReturn(func(ctx context.Context, filter *audit.EventFilter) ([]model.AuditEvent, error) {
+					assert.Equal(t, tc.WantLimit, filter.Limit)
+					return []model.AuditEvent{}, nil
+				}).Once()
+			}
+
+			_, err := audit.NewService(repo).ListEvents(context.Background(), tc.In)
+			assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+		})
+	}
+}
+
+func TestRecorder_Record(t *testing.T) {
+	ctx := utils.WithClientInfo(context.Background(), utils.ClientInfo{IP: "203.0.113.1", UserAgent: "curl/8.0", RequestID: "request-1"})
+	ctx = auth.WithAccessProperties(ctx, &auth.AccessProperties{UserID: userID})
+
+	repo := audit.NewMockRepository(t)
+	repo.EXPECT().CreateEvent(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, event *model.AuditEvent) error {
+		assert.NotEmpty(t, event.ID)
+		assert.NotNil(t, event.CreatedAt)
+		assert.Equal(t, userID, event.ActorID)
+		assert.Equal(t, "203.0.113.1", event.IP)
+		assert.Equal(t, "curl/8.0", event.UserAgent)
+		assert.Equal(t, "request-1", event.RequestID)
+		return nil
+	}).Once()
+	repo.EXPECT().CreateEvent(mock.Anything, mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+
+	recorder := audit.NewRecorder(repo)
+	recorder.Record(ctx, &model.AuditEvent{Type: audit.Logout, Outcome: audit.Success})
+	// Storage failures are only logged.
+	recorder.Record(ctx, &model.AuditEvent{Type: audit.Logout, Outcome: audit.Success})
+}
+
+func TestEnforcer_Record(t *testing.T) {
+	ctx := auth.WithAccessProperties(context.Background(), &auth.AccessProperties{UserID: userID})
+
+	inner := auth.NewMockAuthEnforcer(t)
+	inner.EXPECT().AddRoleForUser(mock.Anything, "member-id", auth.Editor).Return(nil).Once()
+	inner.EXPECT().RemovePolicy(mock.Anything, "role:support", auth.Users, auth.Read).Return(fmt.Errorf("failed to remove policy")).Once()
+	inner.EXPECT().Enforce("member-id", auth.Resource, auth.Write).Return(true, nil).Once()
+
+	recorder := audit.NewMemoryRecorder()
+	enforcer := audit.NewEnforcer(inner, recorder)
+
+	assert.NoError(t, enforcer.AddRoleForUser(ctx, "member-id", auth.Editor))
+	assert.Error(t, enforcer.RemovePolicy(ctx, "role:support", auth.Users, auth.Read))
+	ok, err := enforcer.Enforce("member-id", auth.Resource, auth.Write)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	events := recorder.Events()
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, audit.RoleGrant, events[0].Type)
+		assert.Equal(t, audit.Success, events[0].Outcome)
+		assert.Equal(t, userID, events[0].ActorID)
+		assert.Equal(t, "member-id", events[0].Target)
+		assert.Equal(t, "editor", events[0].Details)
+
+		assert.Equal(t, audit.PolicyRemove, events[1].Type)
+		assert.Equal(t, audit.Failure, events[1].Outcome)
+		assert.Equal(t, "role:support", events[1].Target)
+		assert.Equal(t, "users read", events[1].Details)
+	}
+}
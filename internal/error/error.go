@@ -1,5 +1,10 @@
 package error
 
+import (
+	"errors"
+	"net/http"
+)
+
 // AppError represents an application error.
 type AppError struct {
 	Code        int    `json:"code"`
@@ -12,6 +17,11 @@ func (e *AppError) Error() string {
 	return e.SystemError.Error()
 }
 
+// Unwrap returns the underlying system error.
+func (e *AppError) Unwrap() error {
+	return e.SystemError
+}
+
 // New creates a new application error.
 func New(code int, systemError error, messages ...string) *AppError {
 	message := ""
@@ -25,3 +35,23 @@ func New(code int, systemError error, messages ...string) *AppError {
 		Message:     message,
 	}
 }
+
+// BadRequest creates a 400 application error whose message is shown to the client as is.
+func BadRequest(message string) *AppError {
+	return New(http.StatusBadRequest, errors.New(message), message)
+}
+
+// StatusOf returns the HTTP status of the application error in err's chain, or 0 without one.
+func StatusOf(err error) int {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+
+	return 0
+}
+
+// IsNotFound reports whether err is a record not found error.
+func IsNotFound(err error) bool {
+	return StatusOf(err) == http.StatusNotFound
+}
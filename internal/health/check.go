@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// PostgresCheck pings the PostgreSQL database.
+func PostgresCheck(db *gorm.DB) Check {
+	return Check{
+		Name: "postgres",
+		Run: func(ctx context.Context) error {
+			conn, err := db.DB()
+			if err != nil {
+				return err
+			}
+
+			return conn.PingContext(ctx)
+		},
+	}
+}
+
+// RedisCheck pings Redis.
+func RedisCheck(rdb *redis.Client) Check {
+	return Check{
+		Name: "redis",
+		Run: func(ctx context.Context) error {
+			return rdb.Ping(ctx).Err()
+		},
+	}
+}
+
+// MigrationCheck verifies the database is migrated to version, the newest migration the server knows,
+// and that no migration was left half applied.
+func MigrationCheck(db *gorm.DB, version uint) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			var state struct {
+				Version uint
+				Dirty   bool
+			}
+			if err := db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&state).Error; err != nil {
+				return err
+			}
+
+			switch {
+			case state.Dirty:
+				return fmt.Errorf("migration %d failed and left the database dirty", state.Version)
+			case state.Version < version:
+				return fmt.Errorf("database is at migration %d, expected %d", state.Version, version)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package health
+
+// CheckResponseDTO represents the outcome of one readiness check.
+type CheckResponseDTO struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ReportResponseDTO represents the outcome of a readiness probe.
+type ReportResponseDTO struct {
+	Status string             `json:"status"`
+	Checks []CheckResponseDTO `json:"checks"`
+}
+
+// NewReportResponseDTO converts a Report to a ReportResponseDTO.
+func NewReportResponseDTO(report *Report) ReportResponseDTO {
+	checks := make([]CheckResponseDTO, len(report.Checks))
+	for i, check := range report.Checks {
+		checks[i] = CheckResponseDTO{
+			Name:       check.Name,
+			Status:     string(check.Status),
+			DurationMs: check.Duration.Milliseconds(),
+			Error:      check.Error,
+		}
+	}
+
+	return ReportResponseDTO{Status: string(report.Status), Checks: checks}
+}
@@ -0,0 +1,37 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/tracing"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+// Liveness reports that the server process is running. It checks no dependency, so a failing
+// database does not get the server restarted.
+func (h *Handler) Liveness(c *gin.Context) {
+	utils.ResponseOk(c, gin.H{"status": Up})
+}
+
+// Readiness reports the outcome of every check with 200 when all are up, and 503 otherwise,
+// including once the server starts shutting down.
+func (h *Handler) Readiness(c *gin.Context) {
+	report := h.service.Ready(c.Request.Context())
+	res := NewReportResponseDTO(report)
+
+	if report.Status != Up {
+		c.JSON(http.StatusServiceUnavailable, utils.Response{Success: false, Error: "service is not ready", Result: res, TraceID: tracing.TraceID(c.Request.Context())})
+		return
+	}
+
+	utils.ResponseOk(c, res)
+}
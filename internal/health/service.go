@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/pkg/redact"
+)
+
+// DefaultCheckTimeout bounds a check that does not set its own timeout.
+const DefaultCheckTimeout = 2 * time.Second
+
+// Status is the outcome of a check or of the whole readiness report.
+type Status string
+
+const (
+	Up   = Status("up")
+	Down = Status("down")
+)
+
+// Check probes one dependency the server needs to serve requests.
+type Check struct {
+	Name string
+	// Timeout bounds the check, which is reported down when it runs longer. Defaults to DefaultCheckTimeout.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Name     string
+	Status   Status
+	Duration time.Duration
+	Error    string
+}
+
+// Report is the outcome of a readiness probe. It is up only when every check is.
+type Report struct {
+	Status Status
+	Checks []CheckResult
+}
+
+// Service reports whether the server is ready to receive traffic.
+type Service interface {
+	// Ready runs every check concurrently. Once Drain has been called it reports down without running them.
+	Ready(ctx context.Context) *Report
+	// Drain marks the server as shutting down, so load balancers stop sending it new requests.
+	Drain()
+}
+
+// service implements the Service interface
+type service struct {
+	checks   []Check
+	draining atomic.Bool
+}
+
+func NewService(checks ...Check) *service {
+	return &service{checks: checks}
+}
+
+func (s *service) Ready(ctx context.Context) *Report {
+	if s.draining.Load() {
+		return &Report{
+			Status: Down,
+			Checks: []CheckResult{{Name: "shutdown", Status: Down, Error: "server is shutting down"}},
+		}
+	}
+
+	report := &Report{Status: Up, Checks: make([]CheckResult, len(s.checks))}
+
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == Down {
+			report.Status = Down
+		}
+	}
+
+	return report
+}
+
+func (s *service) Drain() {
+	s.draining.Store(true)
+}
+
+// run runs check within its timeout. A check ignoring its context is abandoned once the timeout passes.
+func run(ctx context.Context, check Check) CheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	result := CheckResult{Name: check.Name, Status: Up, Duration: time.Since(start)}
+	if err != nil {
+		result.Status = Down
+		// Readiness reports are served to anyone, and connection errors may name credentials.
+		result.Error = redact.String(err.Error())
+	}
+
+	return result
+}
@@ -0,0 +1,77 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/internal/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Ready(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		Checks     []health.Check
+		Drain      bool
+		WantStatus health.Status
+		WantChecks map[string]string
+	}
+
+	up := health.Check{Name: "up", Run: func(ctx context.Context) error { return nil }}
+	down := health.Check{Name: "down", Run: func(ctx context.Context) error { return errors.New("connection refused") }}
+	slow := health.Check{Name: "slow", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}}
+
+	testcases := []Testcase{
+		{
+			Name:       "all-up",
+			Checks:     []health.Check{up},
+			WantStatus: health.Up,
+			WantChecks: map[string]string{"up": ""},
+		},
+		{
+			Name:       "one-down",
+			Checks:     []health.Check{up, down},
+			WantStatus: health.Down,
+			WantChecks: map[string]string{"up": "", "down": "connection refused"},
+		},
+		{
+			Name:       "timed-out",
+			Checks:     []health.Check{up, slow},
+			WantStatus: health.Down,
+			WantChecks: map[string]string{"up": "", "slow": "timed out after 10ms"},
+		},
+		{
+			Name:       "draining",
+			Checks:     []health.Check{up},
+			Drain:      true,
+			WantStatus: health.Down,
+			WantChecks: map[string]string{"shutdown": "server is shutting down"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			service := health.NewService(tc.Checks...)
+			if tc.Drain {
+				service.Drain()
+			}
+
+			start := time.Now()
+			report := service.Ready(context.Background())
+			assert.Less(t, time.Since(start), 500*time.Millisecond, "checks are bounded by their timeout")
+
+			assert.Equal(t, tc.WantStatus, report.Status)
+			got := map[string]string{}
+			for _, check := range report.Checks {
+				got[check.Name] = check.Error
+				assert.Equal(t, check.Error == "", check.Status == health.Up)
+			}
+			assert.Equal(t, tc.WantChecks, got)
+		})
+	}
+}
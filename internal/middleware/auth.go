@@ -1,57 +1,275 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/chai-rs/simple-bookstore/infrastructure/tracing"
 	"github.com/chai-rs/simple-bookstore/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// AuthMiddleware checks if the user is authenticated.
-func AuthMiddleware() gin.HandlerFunc {
+// accessPropertiesKey is the gin context key holding the caller resolved by AuthMiddleware.
+const accessPropertiesKey = "access_properties"
+
+// AuthOpts contains optional collaborators for AuthMiddleware.
+type AuthOpts struct {
+	// Tokens, when set, must still hold bearer tokens, so logged out and revoked ones are refused.
+	Tokens auth.Auth
+	// APIKeys, when set, accepts "Authorization: ApiKey <key>" in place of a bearer token.
+	APIKeys auth.APIKeyVerifier
+}
+
+// AuthMiddleware checks if the user is authenticated, either with a bearer token verified by
+// tokenManager or an API key.
+func AuthMiddleware(tokenManager auth.TokenManager, opts ...*AuthOpts) gin.HandlerFunc {
+	option := AuthOpts{}
+	if len(opts) > 0 {
+		option = *opts[0]
+	}
+
 	return func(c *gin.Context) {
-		err := auth.TokenValid(c.Request)
+		if key, ok := auth.ExtractAPIKey(c.Request); ok {
+			if option.APIKeys == nil {
+				utils.ResponseErrorWithStatus(c, http.StatusUnauthorized, "api keys are not accepted")
+				c.Abort()
+				return
+			}
+
+			metadata, err := option.APIKeys.VerifyAPIKey(c.Request.Context(), key)
+			if err != nil {
+				utils.ResponseError(c, err)
+				c.Abort()
+				return
+			}
+
+			setAccessProperties(c, metadata)
+			c.Next()
+			return
+		}
+
+		metadata, err := tokenManager.ExtractTokenMetadata(c.Request)
 		if err != nil {
 			utils.ResponseErrorWithStatus(c, http.StatusUnauthorized, "user hasn't logged in yet")
 			c.Abort()
 			return
 		}
 
+		if option.Tokens != nil {
+			if _, err := option.Tokens.FetchAuth(c.Request.Context(), metadata.TokenUUID); err != nil {
+				utils.ResponseErrorWithStatus(c, http.StatusUnauthorized, "token has been revoked")
+				c.Abort()
+				return
+			}
+		}
+
+		setAccessProperties(c, metadata)
+		c.Next()
+	}
+}
+
+// UserTokenOnly refuses OAuth2 client tokens and API keys, for routes that manage the user's own account.
+func UserTokenOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metadata, err := accessProperties(c)
+		if err != nil {
+			utils.ResponseErrorWithStatus(c, http.StatusUnauthorized, "unauthorized")
+			c.Abort()
+			return
+		}
+
+		if metadata.ClientID != "" {
+			utils.ResponseErrorWithStatus(c, http.StatusForbidden, "not available to third-party clients")
+			c.Abort()
+			return
+		}
+
+		if metadata.APIKeyID != "" {
+			utils.ResponseErrorWithStatus(c, http.StatusForbidden, "not available to api keys")
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
 
 // Authorize checks if the user is authorized.
+// Tokens issued to OAuth2 clients must additionally carry the scope matching obj and act,
+// and API keys must hold the matching policy themselves.
 func Authorize(obj auth.AuthObject, act auth.AuthAction, enforcer auth.AuthEnforcer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		err := auth.TokenValid(c.Request)
+		metadata, err := accessProperties(c)
 		if err != nil {
-			utils.ResponseErrorWithStatus(c, http.StatusUnauthorized, "user hasn't logged in yet")
-			c.Abort()
+			deny(c, http.StatusUnauthorized, obj, "user hasn't logged in yet")
 			return
 		}
 
-		metadata, err := auth.ExtractTokenMetadata(c.Request)
+		ok, err := traceEnforce(c, metadata.UserID, obj, act, func() (bool, error) {
+			return enforcer.Enforce(metadata.UserID, obj, act)
+		})
 		if err != nil {
-			utils.ResponseErrorWithStatus(c, http.StatusUnauthorized, "unauthorized")
-			c.Abort()
+			deny(c, http.StatusUnauthorized, obj, "error occurred while authorizing user")
 			return
 		}
 
-		ok, err := enforcer.Enforce(metadata.UserID, obj, act)
+		if !ok {
+			explainDenied(enforcer, metadata.UserID, &auth.AuthResource{Object: obj}, act)
+			deny(c, http.StatusForbidden, obj, "forbidden")
+			return
+		}
+
+		if !allowCredential(c, enforcer, metadata, obj, act) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ResourceLoader loads the resource targeted by a request. Its errors are sent as the response.
+type ResourceLoader func(c *gin.Context) (*auth.AuthResource, error)
+
+// AuthorizeResource loads the target resource and checks the user may act on that instance,
+// either through a policy on the whole object, on the instance, or as its owner.
+func AuthorizeResource(act auth.AuthAction, enforcer auth.AuthEnforcer, load ResourceLoader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metadata, err := accessProperties(c)
+		if err != nil {
+			deny(c, http.StatusUnauthorized, "", "user hasn't logged in yet")
+			return
+		}
+
+		res, err := load(c)
 		if err != nil {
-			utils.ResponseErrorWithStatus(c, http.StatusUnauthorized, "error occurred while authorizing user")
+			utils.ResponseError(c, err)
 			c.Abort()
 			return
 		}
 
+		ok, err := traceEnforce(c, metadata.UserID, res.Object, act, func() (bool, error) {
+			return enforcer.EnforceResource(metadata.UserID, res, act)
+		})
+		if err != nil {
+			deny(c, http.StatusUnauthorized, res.Object, "error occurred while authorizing user")
+			return
+		}
+
 		if !ok {
-			utils.ResponseErrorWithStatus(c, http.StatusForbidden, "forbidden")
-			c.Abort()
+			explainDenied(enforcer, metadata.UserID, res, act)
+			deny(c, http.StatusForbidden, res.Object, "forbidden")
+			return
+		}
+
+		if !allowCredential(c, enforcer, metadata, res.Object, act) {
 			return
 		}
 
 		c.Next()
 	}
 }
+
+// allowCredential checks that an API key or OAuth2 client token is scoped for act on obj,
+// aborting the request when it is not. Plain user tokens are always allowed.
+func allowCredential(c *gin.Context, enforcer auth.AuthEnforcer, metadata *auth.AccessProperties, obj auth.AuthObject, act auth.AuthAction) bool {
+	if metadata.APIKeyID != "" {
+		ok, err := traceEnforce(c, auth.APIKeySubject(metadata.APIKeyID), obj, act, func() (bool, error) {
+			return enforcer.Enforce(auth.APIKeySubject(metadata.APIKeyID), obj, act)
+		})
+		if err != nil {
+			deny(c, http.StatusUnauthorized, obj, "error occurred while authorizing user")
+			return false
+		}
+
+		if !ok {
+			explainDenied(enforcer, auth.APIKeySubject(metadata.APIKeyID), &auth.AuthResource{Object: obj}, act)
+			deny(c, http.StatusForbidden, obj, "insufficient scope")
+			return false
+		}
+	}
+
+	if metadata.ClientID != "" && !auth.ScopesAllow(metadata.Scopes, obj, act) {
+		deny(c, http.StatusForbidden, obj, "insufficient scope")
+		return false
+	}
+
+	return true
+}
+
+// traceEnforce runs enforce within a span, Casbin enforcement being among the slowest steps of a request.
+func traceEnforce(c *gin.Context, sub string, obj auth.AuthObject, act auth.AuthAction, enforce func() (bool, error)) (bool, error) {
+	_, span := tracing.Start(c.Request.Context(), "casbin.Enforce",
+		attribute.String("auth.subject", sub),
+		attribute.String("auth.object", obj.String()),
+		attribute.String("auth.action", act.String()),
+	)
+
+	ok, err := enforce()
+	span.SetAttributes(attribute.Bool("auth.allowed", ok))
+	tracing.End(span, err)
+
+	return ok, err
+}
+
+// deny rejects a request failing authorization on obj and counts the rejection.
+// obj is empty when the request is rejected before its target is known.
+func deny(c *gin.Context, status int, obj auth.AuthObject, message string) {
+	metrics.AuthorizationDenied.WithLabelValues(strconv.Itoa(status), obj.String()).Inc()
+	utils.ResponseErrorWithStatus(c, status, message)
+	c.Abort()
+}
+
+// explainDenied logs the roles of a denied subject in development mode, to help find the missing policy.
+// Use POST /api/admin/authz/explain to explain a decision in production.
+func explainDenied(enforcer auth.AuthEnforcer, sub string, res *auth.AuthResource, act auth.AuthAction) {
+	if !gin.IsDebugging() {
+		return
+	}
+
+	explanation, err := enforcer.Explain(sub, res, act)
+	if err != nil {
+		log.Warn().Err(err).Msg("⚠️ failed to explain authorization decision")
+		return
+	}
+
+	log.Info().
+		Str("sub", sub).
+		Str("obj", res.Object.String()).
+		Str("id", res.ID).
+		Str("owner", res.Owner).
+		Str("act", act.String()).
+		Strs("roles", explanation.Roles).
+		Msg("🔐 request denied, no policy matched")
+}
+
+// setAccessProperties stores the caller in the gin context and in the request context for services,
+// and adds it to the request logger.
+func setAccessProperties(c *gin.Context, metadata *auth.AccessProperties) {
+	c.Set(accessPropertiesKey, metadata)
+	ctx := auth.WithAccessProperties(c.Request.Context(), metadata)
+
+	fields := log.Ctx(ctx).With().Str("user_id", metadata.UserID)
+	if metadata.APIKeyID != "" {
+		fields = fields.Str("api_key_id", metadata.APIKeyID)
+	}
+	if metadata.ClientID != "" {
+		fields = fields.Str("client_id", metadata.ClientID)
+	}
+	logger := fields.Logger()
+
+	c.Request = c.Request.WithContext(logger.WithContext(ctx))
+}
+
+// accessProperties returns the caller resolved by AuthMiddleware.
+func accessProperties(c *gin.Context) (*auth.AccessProperties, error) {
+	if value, ok := c.Get(accessPropertiesKey); ok {
+		return value.(*auth.AccessProperties), nil
+	}
+
+	return nil, errors.New("request is not authenticated")
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// RequestIDHeader carries the request ID, from the client or a proxy, and back in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs supplied by clients, since they end up in logs and audit events.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware takes the request ID from the X-Request-ID header, or generates one, and returns it in
+// the response. It stores a logger carrying the request ID and route in the request context, so
+// that services logging with log.Ctx(ctx) tie every line to its request.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := requestID(c)
+		ctx := utils.WithRequestID(c.Request.Context(), requestID)
+
+		logger := log.With().
+			Ctx(ctx).
+			Str("request_id", requestID).
+			Str("route", route(c)).
+			Logger()
+		c.Request = c.Request.WithContext(logger.WithContext(ctx))
+
+		c.Next()
+	}
+}
+
+// ClientInfoMiddleware stores the client IP, user agent and request ID in the request context for services.
+// The request ID is the one RequestIDMiddleware assigned, or is resolved the same way when it did not run.
+func ClientInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := utils.WithClientInfo(c.Request.Context(), utils.ClientInfo{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: requestID(c),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// requestID returns the ID of the request, taking it from the X-Request-ID header when it is a plausible ID
+// and generating one otherwise, and sets it on the response.
+func requestID(c *gin.Context) string {
+	if id := utils.RequestIDFromContext(c.Request.Context()); id != "" {
+		return id
+	}
+
+	id := c.GetHeader(RequestIDHeader)
+	if !validRequestID(id) {
+		id = uuid.NewString()
+	}
+	c.Header(RequestIDHeader, id)
+
+	return id
+}
+
+// validRequestID accepts IDs of printable ASCII characters, so that clients cannot forge log lines with them.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,67 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/internal/middleware"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	type Testcase struct {
+		Name      string
+		RequestID string
+		WantKept  bool
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	global := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = global })
+
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware(), middleware.ClientInfoMiddleware())
+	router.GET("/books/:id", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		assert.Equal(t, utils.RequestIDFromContext(ctx), utils.ClientInfoFromContext(ctx).RequestID)
+		log.Ctx(ctx).Info().Msg("handled")
+		c.Status(http.StatusOK)
+	})
+
+	testcases := []Testcase{
+		{Name: "honoured", RequestID: "req-123", WantKept: true},
+		{Name: "generated", RequestID: ""},
+		{Name: "too-long", RequestID: strings.Repeat("a", 129)},
+		{Name: "control-characters", RequestID: "req\nforged log line"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/books/42", nil)
+			req.Header.Set(middleware.RequestIDHeader, tc.RequestID)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(middleware.RequestIDHeader)
+			assert.NotEmpty(t, requestID)
+			assert.Equal(t, tc.WantKept, requestID == tc.RequestID)
+
+			var line map[string]any
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+			assert.Equal(t, requestID, line["request_id"])
+			assert.Equal(t, "/books/:id", line["route"])
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS applies CORS settings that can be replaced while the server runs.
+type CORS struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+// NewCORS creates the CORS middleware for cfg.
+func NewCORS(cfg *config.CORSConfig) (*CORS, error) {
+	m := &CORS{}
+	if err := m.Update(cfg); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Update replaces the CORS settings. The running ones are kept when cfg is invalid.
+func (m *CORS) Update(cfg *config.CORSConfig) error {
+	settings := cors.Config{
+		AllowOrigins:     cfg.AllowedOrigins,
+		AllowMethods:     cfg.AllowedMethods,
+		AllowHeaders:     cfg.AllowedHeaders,
+		ExposeHeaders:    cfg.ExposedHeaders,
+		MaxAge:           time.Duration(cfg.MaxAge),
+		AllowCredentials: true,
+	}
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	handler := cors.New(settings)
+	m.handler.Store(&handler)
+	return nil
+}
+
+// Middleware returns the middleware applying the current CORS settings.
+func (m *CORS) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		(*m.handler.Load())(c)
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureGate returns a middleware rejecting requests with 403 and message while enabled reports false.
+// enabled is checked on every request, so feature flags changed by a configuration reload apply at once.
+func FeatureGate(enabled func() bool, message string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled() {
+			utils.ResponseErrorWithStatus(c, http.StatusForbidden, message)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
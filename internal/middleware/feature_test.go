@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/chai-rs/simple-bookstore/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureGate_Reload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ACCESS_SECRET", "access")
+	t.Setenv("REFRESH_SECRET", "refresh")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("features: []\n"), 0o600))
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	manager := config.NewManager(cfg, path)
+
+	router := gin.New()
+	router.POST("/register", middleware.FeatureGate(func() bool {
+		return !manager.Current().FeatureEnabled(config.ClosedRegistrationFeature)
+	}, "registration is closed"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	register := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/register", nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, register().Code)
+
+	// Enabling the flag closes registration without restarting the server.
+	assert.NoError(t, os.WriteFile(path, []byte("features: [closed_registration]\n"), 0o600))
+	result := manager.Reload(config.AdminTrigger)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, []string{"features"}, result.Applied)
+
+	w := register()
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "registration is closed")
+
+	assert.NoError(t, os.WriteFile(path, []byte("features: []\n"), 0o600))
+	manager.Reload(config.AdminTrigger)
+	assert.Equal(t, http.StatusOK, register().Code)
+}
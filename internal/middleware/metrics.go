@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests matching no route, so unknown paths do not each get their own series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware observes the duration of every request, labelled by its route template such as
+// /api/books/:id rather than the raw path.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Request.Method, route(c), strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// route returns the template of the route the request matched, such as /api/books/:id.
+func route(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+
+	return unmatchedRoute
+}
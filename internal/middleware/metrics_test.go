@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/chai-rs/simple-bookstore/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleCount returns the number of observations of a histogram series.
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	var metric dto.Metric
+	assert.NoError(t, observer.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.MetricsMiddleware())
+	router.GET("/metrics-test/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Requests are labelled by route template, so every ID shares one series, and unknown paths share another.
+	matched := metrics.HTTPRequestDuration.WithLabelValues(http.MethodGet, "/metrics-test/:id", "200")
+	unmatched := metrics.HTTPRequestDuration.WithLabelValues(http.MethodGet, "unmatched", "404")
+	matchedBefore, unmatchedBefore := sampleCount(t, matched), sampleCount(t, unmatched)
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/metrics-test/unknown/path"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, uint64(2), sampleCount(t, matched)-matchedBefore)
+	assert.Equal(t, uint64(1), sampleCount(t, unmatched)-unmatchedBefore)
+
+	body := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(body, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, body.Body.String(), `bookstore_http_request_duration_seconds_count{method="GET",route="/metrics-test/:id",status="200"}`)
+	assert.Contains(t, body.Body.String(), "go_goroutines")
+}
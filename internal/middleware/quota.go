@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/internal/quota"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaMiddleware counts the requests of the caller resolved by AuthMiddleware against the quotas of
+// their plan, refusing them with 429 and a Retry-After header once a quota is used up.
+// It complements RateLimitMiddleware, which smooths out bursts rather than capping totals.
+func QuotaMiddleware(quotas quota.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metadata, err := accessProperties(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		if err := quotas.Consume(c.Request.Context(), metadata); err != nil {
+			var exceeded *quota.ExceededError
+			if errors.As(err, &exceeded) {
+				c.Header("Retry-After", strconv.Itoa(int(time.Until(exceeded.ResetAt).Seconds())+1))
+			}
+
+			utils.ResponseError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,12 +1,107 @@
 package middleware
 
 import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/infrastructure/metrics"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 	"github.com/ulule/limiter/v3"
-	mgin "github.com/ulule/limiter/v3/drivers/middleware/gin"
 )
 
-// RateLimitMiddleware returns a middleware that limits the number of requests per user.
-func RateLimitMiddleware(limiter *limiter.Limiter) gin.HandlerFunc {
-	return mgin.NewMiddleware(limiter)
+// Rate limit headers, as in the IETF RateLimit header fields draft. RateLimit-Reset is in seconds.
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+// RateLimiter counts the requests made under a key against a rate.
+type RateLimiter interface {
+	Get(ctx context.Context, key string) (limiter.Context, error)
+}
+
+// RateLimitKey returns the key a request is counted under.
+type RateLimitKey func(c *gin.Context) string
+
+// KeyByIP counts requests per client IP. X-Forwarded-For is only taken into account from the
+// engine's trusted proxies, so clients cannot pick a fresh IP to escape their limit.
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByCaller counts requests per API key or per user, so callers behind a shared IP do not
+// exhaust each other's limit. Requests AuthMiddleware has not authenticated are counted per IP.
+func KeyByCaller(c *gin.Context) string {
+	value, ok := c.Get(accessPropertiesKey)
+	if !ok {
+		return KeyByIP(c)
+	}
+
+	metadata := value.(*auth.AccessProperties)
+	if metadata.APIKeyID != "" {
+		return "key:" + metadata.APIKeyID
+	}
+
+	return "user:" + metadata.UserID
+}
+
+// RateLimitOpts contains optional settings for RateLimitMiddleware.
+type RateLimitOpts struct {
+	// Policy names the limit, so that several limits sharing a store count separately. Defaults to "default".
+	Policy string
+	// Key picks what requests are counted under. Defaults to KeyByIP.
+	Key RateLimitKey
+}
+
+// withDefaults fills unset options.
+func (o RateLimitOpts) withDefaults() RateLimitOpts {
+	if o.Policy == "" {
+		o.Policy = "default"
+	}
+
+	if o.Key == nil {
+		o.Key = KeyByIP
+	}
+
+	return o
+}
+
+// RateLimitMiddleware returns a middleware that limits the number of requests per key and reports
+// the limit in RateLimit-* headers. Requests are let through when the store is unavailable.
+func RateLimitMiddleware(limiter RateLimiter, opts ...*RateLimitOpts) gin.HandlerFunc {
+	option := RateLimitOpts{}
+	if len(opts) > 0 {
+		option = *opts[0]
+	}
+	option = option.withDefaults()
+
+	return func(c *gin.Context) {
+		result, err := limiter.Get(c.Request.Context(), option.Policy+":"+option.Key(c))
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Str("policy", option.Policy).Msg("🚨 failed to check rate limit")
+			c.Next()
+			return
+		}
+
+		reset := max(result.Reset-time.Now().Unix(), 0)
+		c.Header(RateLimitLimitHeader, strconv.FormatInt(result.Limit, 10))
+		c.Header(RateLimitRemainingHeader, strconv.FormatInt(result.Remaining, 10))
+		c.Header(RateLimitResetHeader, strconv.FormatInt(reset, 10))
+
+		if result.Reached {
+			metrics.RateLimited.WithLabelValues(option.Policy).Inc()
+			c.Header("Retry-After", strconv.FormatInt(reset, 10))
+			utils.ResponseErrorWithStatus(c, http.StatusTooManyRequests, "rate limit exceeded, try again later")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
 }
@@ -0,0 +1,70 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/limiter"
+	"github.com/chai-rs/simple-bookstore/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limit := limiter.NewMemoryLimiter("2-M")
+	router := gin.New()
+	router.GET("/a", middleware.RateLimitMiddleware(limit, &middleware.RateLimitOpts{Policy: "a"}), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/b", middleware.RateLimitMiddleware(limit, &middleware.RateLimitOpts{Policy: "b"}), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/a", "203.0.113.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get(middleware.RateLimitLimitHeader))
+	assert.Equal(t, "1", w.Header().Get(middleware.RateLimitRemainingHeader))
+	assert.NotEmpty(t, w.Header().Get(middleware.RateLimitResetHeader))
+
+	assert.Equal(t, http.StatusOK, get("/a", "203.0.113.1").Code)
+
+	w = get("/a", "203.0.113.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get(middleware.RateLimitRemainingHeader))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, get("/a", "203.0.113.2").Code, "other clients have their own limit")
+	assert.Equal(t, http.StatusOK, get("/b", "203.0.113.1").Code, "other policies have their own limit")
+}
+
+func TestRateLimitMiddleware_ForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limit := limiter.NewMemoryLimiter("1-M")
+	router := gin.New()
+	assert.NoError(t, router.SetTrustedProxies([]string{"10.0.0.1"}))
+	router.GET("/", middleware.RateLimitMiddleware(limit), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(remoteIP, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteIP + ":1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("203.0.113.1", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, get("203.0.113.1", "198.51.100.2"), "a forged X-Forwarded-For must not reset the limit")
+
+	assert.Equal(t, http.StatusOK, get("10.0.0.1", "198.51.100.1"), "clients behind a trusted proxy are counted by their forwarded IP")
+	assert.Equal(t, http.StatusOK, get("10.0.0.1", "198.51.100.2"))
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.1", "198.51.100.2"))
+}
@@ -0,0 +1,57 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/tracing"
+	"github.com/chai-rs/simple-bookstore/internal/middleware"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown := tracing.Init(exporter)
+	t.Cleanup(func() { shutdown(context.Background()) })
+
+	router := gin.New()
+	router.Use(middleware.TracingMiddleware())
+	router.GET("/books/:id", func(c *gin.Context) {
+		_, span := tracing.Start(c.Request.Context(), "child")
+		span.End()
+		utils.ResponseErrorWithStatus(c, http.StatusNotFound, "book not found")
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/books/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var res utils.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, traceID, res.TraceID, "error responses carry the trace ID")
+	assert.Contains(t, w.Header().Get("traceparent"), traceID)
+
+	assert.NoError(t, otel.GetTracerProvider().(*sdktrace.TracerProvider).ForceFlush(context.Background()))
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+
+	child, request := spans[0], spans[1]
+	assert.Equal(t, "GET /books/:id", request.Name, "spans are named by route template")
+	assert.Equal(t, traceID, request.SpanContext.TraceID().String(), "the incoming trace is continued")
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent.SpanID().String())
+	assert.Equal(t, request.SpanContext.SpanID(), child.Parent.SpanID())
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey represents a personal API key for machine-to-machine access on behalf of a user.
+// Scopes is a space separated list. The key itself is only stored hashed, Prefix is kept in clear to look it up.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"column:id;primaryKey"`
+	UserID     uuid.UUID  `gorm:"column:user_id;index"`
+	Name       string     `gorm:"column:name"`
+	Prefix     string     `gorm:"column:prefix;uniqueIndex"`
+	HashedKey  string     `gorm:"column:hashed_key"`
+	Scopes     string     `gorm:"column:scopes"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+	CreatedAt  *time.Time `gorm:"column:created_at"`
+	User       *User      `gorm:"foreignKey:UserID"`
+}
+
+func (k *APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active reports whether the key is neither revoked nor expired at the given time.
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent records a security-relevant event. Events are append-only and deliberately not tied
+// to users by foreign key, so the trail outlives the accounts it mentions.
+// ActorID is the user who acted, when known, and Target what was acted on, e.g. a user ID or policy subject.
+type AuditEvent struct {
+	ID        uuid.UUID  `gorm:"column:id;primaryKey"`
+	Type      string     `gorm:"column:type"`
+	Outcome   string     `gorm:"column:outcome"`
+	ActorID   string     `gorm:"column:actor_id"`
+	Target    string     `gorm:"column:target"`
+	Details   string     `gorm:"column:details"`
+	IP        string     `gorm:"column:ip"`
+	UserAgent string     `gorm:"column:user_agent"`
+	RequestID string     `gorm:"column:request_id"`
+	CreatedAt *time.Time `gorm:"column:created_at"`
+}
+
+func (e *AuditEvent) TableName() string {
+	return "audit_events"
+}
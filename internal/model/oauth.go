@@ -0,0 +1,51 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient represents a third-party application registered to use the OAuth2 authorization server.
+// RedirectURIs, Scopes and GrantTypes are space separated lists.
+type OAuthClient struct {
+	ID           uuid.UUID  `gorm:"column:id;primaryKey"`
+	OwnerID      uuid.UUID  `gorm:"column:owner_id;index"`
+	Name         string     `gorm:"column:name"`
+	HashedSecret string     `gorm:"column:hashed_secret"`
+	Confidential bool       `gorm:"column:confidential"`
+	RedirectURIs string     `gorm:"column:redirect_uris"`
+	Scopes       string     `gorm:"column:scopes"`
+	GrantTypes   string     `gorm:"column:grant_types"`
+	CreatedAt    *time.Time `gorm:"column:created_at"`
+}
+
+func (c *OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// HasRedirectURI reports whether uri is one of the client's registered redirect URIs.
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	return slices.Contains(strings.Fields(c.RedirectURIs), uri)
+}
+
+// HasGrantType reports whether the client may use the given grant type.
+func (c *OAuthClient) HasGrantType(grantType string) bool {
+	return slices.Contains(strings.Fields(c.GrantTypes), grantType)
+}
+
+// OAuthConsent records the scopes a user has granted to a client.
+type OAuthConsent struct {
+	ID        uuid.UUID  `gorm:"column:id;primaryKey"`
+	UserID    uuid.UUID  `gorm:"column:user_id"`
+	ClientID  uuid.UUID  `gorm:"column:client_id"`
+	Scopes    string     `gorm:"column:scopes"`
+	CreatedAt *time.Time `gorm:"column:created_at"`
+	UpdatedAt *time.Time `gorm:"column:updated_at"`
+}
+
+func (c *OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
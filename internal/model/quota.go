@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// QuotaUsage is the number of requests a subject made in one day or month.
+// Subject is "user:<id>" or "key:<id>", see quota.UserSubject and quota.KeySubject.
+type QuotaUsage struct {
+	Subject     string     `gorm:"column:subject;primaryKey"`
+	Period      string     `gorm:"column:period;primaryKey"`
+	PeriodStart time.Time  `gorm:"column:period_start;primaryKey"`
+	Count       int64      `gorm:"column:count"`
+	UpdatedAt   *time.Time `gorm:"column:updated_at"`
+}
+
+func (u *QuotaUsage) TableName() string {
+	return "quota_usage"
+}
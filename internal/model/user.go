@@ -6,14 +6,70 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserStatus tells whether a user may sign in.
+type UserStatus string
+
+const (
+	UserActive   = UserStatus("active")
+	UserDisabled = UserStatus("disabled")
+)
+
 // User represents a user.
+// PendingEmail holds a requested new address until it is verified, only then it replaces Email.
+// PasswordResetRequired is set by administrators to refuse password logins until the password is reset.
+// TOTPLastStep is the time step of the last TOTP code accepted, so that no code is accepted twice.
+// Plan names the request quota the user and their API keys are held to.
 type User struct {
-	ID             uuid.UUID  `gorm:"column:id"`
-	Email          string     `gorm:"column:email"`
-	HashedPassword string     `gorm:"column:hashed_password"`
-	CreatedAt      *time.Time `gorm:"column:created_at"`
+	ID                    uuid.UUID      `gorm:"column:id"`
+	Email                 string         `gorm:"column:email"`
+	HashedPassword        string         `gorm:"column:hashed_password"`
+	TOTPSecret            string         `gorm:"column:totp_secret"`
+	TOTPEnabled           bool           `gorm:"column:totp_enabled"`
+	TOTPLastStep          int64          `gorm:"column:totp_last_step"`
+	EmailVerifiedAt       *time.Time     `gorm:"column:email_verified_at"`
+	DisplayName           string         `gorm:"column:display_name"`
+	AvatarURL             string         `gorm:"column:avatar_url"`
+	Locale                string         `gorm:"column:locale"`
+	Preferences           map[string]any `gorm:"column:preferences;serializer:json"`
+	PendingEmail          string         `gorm:"column:pending_email"`
+	Status                UserStatus     `gorm:"column:status;default:active"`
+	PasswordResetRequired bool           `gorm:"column:password_reset_required"`
+	Plan                  string         `gorm:"column:plan;default:free"`
+	CreatedAt             *time.Time     `gorm:"column:created_at"`
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
+
+// Disabled reports whether the user was disabled by an administrator.
+func (u *User) Disabled() bool {
+	return u.Status == UserDisabled
+}
+
+// RecoveryCode represents a hashed one-time recovery code for two-factor authentication.
+type RecoveryCode struct {
+	ID         uuid.UUID  `gorm:"column:id;primaryKey"`
+	UserID     uuid.UUID  `gorm:"column:user_id;index"`
+	HashedCode string     `gorm:"column:hashed_code"`
+	UsedAt     *time.Time `gorm:"column:used_at"`
+	CreatedAt  *time.Time `gorm:"column:created_at"`
+}
+
+func (r *RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// UserIdentity links a user to an account at an external OpenID Connect provider.
+type UserIdentity struct {
+	ID        uuid.UUID  `gorm:"column:id;primaryKey"`
+	UserID    uuid.UUID  `gorm:"column:user_id;index"`
+	Provider  string     `gorm:"column:provider"`
+	Subject   string     `gorm:"column:subject"`
+	Email     string     `gorm:"column:email"`
+	CreatedAt *time.Time `gorm:"column:created_at"`
+}
+
+func (i *UserIdentity) TableName() string {
+	return "user_identities"
+}
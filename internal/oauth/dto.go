@@ -0,0 +1,160 @@
+package oauth
+
+import (
+	"strings"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+)
+
+// RegisterClientRequestDTO represents the request payload for registering an OAuth2 client.
+type RegisterClientRequestDTO struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes" binding:"required"`
+	GrantTypes   []string `json:"grant_types" binding:"required"`
+	Confidential bool     `json:"confidential"`
+}
+
+// ToClient converts RegisterClientRequestDTO to an OAuthClient model.
+func (r *RegisterClientRequestDTO) ToClient() *model.OAuthClient {
+	return &model.OAuthClient{
+		Name:         r.Name,
+		Confidential: r.Confidential,
+		RedirectURIs: strings.Join(r.RedirectURIs, " "),
+		Scopes:       strings.Join(r.Scopes, " "),
+		GrantTypes:   strings.Join(r.GrantTypes, " "),
+	}
+}
+
+// ClientResponseDTO represents a registered OAuth2 client. ClientSecret is only returned on registration.
+type ClientResponseDTO struct {
+	ClientID     string     `json:"client_id"`
+	ClientSecret string     `json:"client_secret,omitempty" sensitive:"true"`
+	Name         string     `json:"name"`
+	Confidential bool       `json:"confidential"`
+	RedirectURIs []string   `json:"redirect_uris"`
+	Scopes       []string   `json:"scopes"`
+	GrantTypes   []string   `json:"grant_types"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// NewClientResponseDTO converts an OAuthClient model to ClientResponseDTO.
+func NewClientResponseDTO(client *model.OAuthClient) ClientResponseDTO {
+	return ClientResponseDTO{
+		ClientID:     client.ID.String(),
+		Name:         client.Name,
+		Confidential: client.Confidential,
+		RedirectURIs: strings.Fields(client.RedirectURIs),
+		Scopes:       strings.Fields(client.Scopes),
+		GrantTypes:   strings.Fields(client.GrantTypes),
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+// AuthorizeRequestDTO represents the parameters of an authorization request.
+type AuthorizeRequestDTO struct {
+	ResponseType        string `json:"response_type" form:"response_type" binding:"required"`
+	ClientID            string `json:"client_id" form:"client_id" binding:"required"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri"`
+	Scope               string `json:"scope" form:"scope"`
+	State               string `json:"state" form:"state"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge" binding:"required"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method" binding:"required"`
+}
+
+// ToAuthorizationRequest converts AuthorizeRequestDTO to an AuthorizationRequest.
+func (r *AuthorizeRequestDTO) ToAuthorizationRequest(approve *bool) *AuthorizationRequest {
+	return &AuthorizationRequest{
+		ResponseType:        r.ResponseType,
+		ClientID:            r.ClientID,
+		RedirectURI:         r.RedirectURI,
+		Scope:               r.Scope,
+		State:               r.State,
+		CodeChallenge:       r.CodeChallenge,
+		CodeChallengeMethod: r.CodeChallengeMethod,
+		Approve:             approve,
+	}
+}
+
+// ConsentRequestDTO represents the user's answer to a consent prompt.
+type ConsentRequestDTO struct {
+	AuthorizeRequestDTO
+	Approve *bool `json:"approve" binding:"required"`
+}
+
+// AuthorizeResponseDTO represents the response payload of an authorization request. When
+// consent_required is false, the user agent should be sent to redirect_uri.
+type AuthorizeResponseDTO struct {
+	ClientID        string   `json:"client_id"`
+	ClientName      string   `json:"client_name"`
+	Scopes          []string `json:"scopes"`
+	ConsentRequired bool     `json:"consent_required"`
+	RedirectURI     string   `json:"redirect_uri,omitempty"`
+}
+
+// NewAuthorizeResponseDTO converts an AuthorizationResult to AuthorizeResponseDTO.
+func NewAuthorizeResponseDTO(result *AuthorizationResult) AuthorizeResponseDTO {
+	return AuthorizeResponseDTO{
+		ClientID:        result.Client.ID.String(),
+		ClientName:      result.Client.Name,
+		Scopes:          strings.Fields(auth.JoinScopes(result.Scopes)),
+		ConsentRequired: result.ConsentRequired,
+		RedirectURI:     result.RedirectURI,
+	}
+}
+
+// TokenRequestDTO represents the form parameters of a token request.
+type TokenRequestDTO struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code" sensitive:"true"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier" sensitive:"true"`
+	RefreshToken string `form:"refresh_token" sensitive:"true"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret" sensitive:"true"`
+}
+
+// TokenResponseDTO represents a successful token response (RFC 6749 section 5.1).
+type TokenResponseDTO struct {
+	AccessToken  string `json:"access_token" sensitive:"true"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty" sensitive:"true"`
+	Scope        string `json:"scope"`
+}
+
+// TokenParamRequestDTO represents the form parameters of an introspection or revocation request.
+type TokenParamRequestDTO struct {
+	Token         string `form:"token" binding:"required" sensitive:"true"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret" sensitive:"true"`
+}
+
+// IntrospectionResponseDTO represents a token introspection response (RFC 7662 section 2.2).
+type IntrospectionResponseDTO struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// ErrorResponseDTO represents an OAuth2 error response (RFC 6749 section 5.2).
+type ErrorResponseDTO struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// ConsentResponseDTO represents the scopes a user has granted to a client.
+type ConsentResponseDTO struct {
+	ClientID  string     `json:"client_id"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
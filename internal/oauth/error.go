@@ -0,0 +1,38 @@
+package oauth
+
+import "net/http"
+
+// Error codes defined by RFC 6749 section 5.2.
+const (
+	InvalidRequest       = "invalid_request"
+	InvalidClient        = "invalid_client"
+	InvalidGrant         = "invalid_grant"
+	UnauthorizedClient   = "unauthorized_client"
+	UnsupportedGrantType = "unsupported_grant_type"
+	InvalidScope         = "invalid_scope"
+	AccessDenied         = "access_denied"
+	ServerError          = "server_error"
+)
+
+// Error is an OAuth2 error returned by the token, introspection and revocation endpoints.
+type Error struct {
+	Status      int
+	Code        string
+	Description string
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func newError(code string, description string) *Error {
+	status := http.StatusBadRequest
+	switch code {
+	case InvalidClient:
+		status = http.StatusUnauthorized
+	case ServerError:
+		status = http.StatusInternalServerError
+	}
+
+	return &Error{Status: status, Code: code, Description: description}
+}
@@ -0,0 +1,396 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+// RegisterClient godoc
+// @Summary Register OAuth2 client
+// @Description Register a third-party client. The client secret of confidential clients is only returned once.
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body RegisterClientRequestDTO true "Client registration"
+// @Success 201 {object} ClientResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /oauth/clients [post]
+func (h *Handler) RegisterClient(c *gin.Context) {
+	var req RegisterClientRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	client := req.ToClient()
+	secret, err := h.service.RegisterClient(c.Request.Context(), metadata.UserID, client)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	res := NewClientResponseDTO(client)
+	res.ClientSecret = secret
+	utils.ResponseCreated(c, res)
+}
+
+// ListClients godoc
+// @Summary List OAuth2 clients
+// @Description List the clients registered by the current user
+// @Tags oauth
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} ClientResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /oauth/clients [get]
+func (h *Handler) ListClients(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	clients, err := h.service.ListClients(c.Request.Context(), metadata.UserID)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	res := make([]ClientResponseDTO, len(clients))
+	for i := range clients {
+		res[i] = NewClientResponseDTO(&clients[i])
+	}
+
+	utils.ResponseOk(c, res)
+}
+
+// DeleteClient godoc
+// @Summary Delete OAuth2 client
+// @Description Delete a client registered by the current user
+// @Tags oauth
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Success 200 {object} nil
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /oauth/clients/{id} [delete]
+func (h *Handler) DeleteClient(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.DeleteClient(c.Request.Context(), metadata.UserID, c.Param("id")); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// Authorize godoc
+// @Summary Start OAuth2 authorization
+// @Description Validate an authorization request for the current user. If the user already consented to the scopes, redirect_uri carries the authorization code.
+// @Tags oauth
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string false "Registered redirect URI"
+// @Param scope query string false "Space separated scopes"
+// @Param state query string false "Opaque client state"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {object} AuthorizeResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /oauth/authorize [get]
+func (h *Handler) Authorize(c *gin.Context) {
+	var req AuthorizeRequestDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	h.authorize(c, req.ToAuthorizationRequest(nil))
+}
+
+// Consent godoc
+// @Summary Answer OAuth2 consent prompt
+// @Description Approve or deny the scopes of an authorization request. redirect_uri carries the authorization code or an access_denied error.
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body ConsentRequestDTO true "Authorization request and answer"
+// @Success 200 {object} AuthorizeResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /oauth/authorize [post]
+func (h *Handler) Consent(c *gin.Context) {
+	var req ConsentRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	h.authorize(c, req.ToAuthorizationRequest(req.Approve))
+}
+
+func (h *Handler) authorize(c *gin.Context, req *AuthorizationRequest) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	result, err := h.service.Authorize(c.Request.Context(), metadata, req)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, NewAuthorizeResponseDTO(result))
+}
+
+// Token godoc
+// @Summary Issue OAuth2 tokens
+// @Description Token endpoint supporting the authorization_code (with PKCE), client_credentials and refresh_token grants. Clients authenticate with HTTP Basic or client_id/client_secret form parameters.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Grant type"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI used in the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Space separated scopes"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 {object} TokenResponseDTO
+// @Failure 400 {object} ErrorResponseDTO
+// @Failure 401 {object} ErrorResponseDTO
+// @Failure 500 {object} ErrorResponseDTO
+// @Failure 429 {object} utils.Response
+// @Router /oauth/token [post]
+func (h *Handler) Token(c *gin.Context) {
+	var req TokenRequestDTO
+	if err := c.ShouldBind(&req); err != nil {
+		responseOAuthError(c, newError(InvalidRequest, "grant_type is required"))
+		return
+	}
+
+	result, err := h.service.Token(c.Request.Context(), &TokenRequest{
+		ClientCredentials: clientCredentials(c, req.ClientID, req.ClientSecret),
+		GrantType:         req.GrantType,
+		Code:              req.Code,
+		RedirectURI:       req.RedirectURI,
+		CodeVerifier:      req.CodeVerifier,
+		RefreshToken:      req.RefreshToken,
+		Scope:             req.Scope,
+	})
+	if err != nil {
+		responseOAuthError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, TokenResponseDTO{
+		AccessToken:  result.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    result.ExpiresIn,
+		RefreshToken: result.RefreshToken,
+		Scope:        result.Scope,
+	})
+}
+
+// Introspect godoc
+// @Summary Introspect OAuth2 token
+// @Description Token introspection (RFC 7662). Only tokens issued to the calling client are reported as active.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Token"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 {object} IntrospectionResponseDTO
+// @Failure 400 {object} ErrorResponseDTO
+// @Failure 401 {object} ErrorResponseDTO
+// @Failure 429 {object} utils.Response
+// @Router /oauth/introspect [post]
+func (h *Handler) Introspect(c *gin.Context) {
+	var req TokenParamRequestDTO
+	if err := c.ShouldBind(&req); err != nil {
+		responseOAuthError(c, newError(InvalidRequest, "token is required"))
+		return
+	}
+
+	credentials := clientCredentials(c, req.ClientID, req.ClientSecret)
+	result, err := h.service.Introspect(c.Request.Context(), &credentials, req.Token)
+	if err != nil {
+		responseOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, IntrospectionResponseDTO{
+		Active:    result.Active,
+		Scope:     result.Scope,
+		ClientID:  result.ClientID,
+		Subject:   result.Subject,
+		Username:  result.Email,
+		TokenType: result.TokenType,
+		ExpiresAt: result.ExpiresAt,
+	})
+}
+
+// Revoke godoc
+// @Summary Revoke OAuth2 token
+// @Description Token revocation (RFC 7009). Responds 200 for unknown tokens as well.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Param token formData string true "Token"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200
+// @Failure 400 {object} ErrorResponseDTO
+// @Failure 401 {object} ErrorResponseDTO
+// @Failure 429 {object} utils.Response
+// @Router /oauth/revoke [post]
+func (h *Handler) Revoke(c *gin.Context) {
+	var req TokenParamRequestDTO
+	if err := c.ShouldBind(&req); err != nil {
+		responseOAuthError(c, newError(InvalidRequest, "token is required"))
+		return
+	}
+
+	credentials := clientCredentials(c, req.ClientID, req.ClientSecret)
+	if err := h.service.Revoke(c.Request.Context(), &credentials, req.Token); err != nil {
+		responseOAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ListConsents godoc
+// @Summary List OAuth2 consents
+// @Description List the clients the current user has granted access to
+// @Tags oauth
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} ConsentResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /oauth/consents [get]
+func (h *Handler) ListConsents(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	consents, err := h.service.ListConsents(c.Request.Context(), metadata.UserID)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	res := make([]ConsentResponseDTO, len(consents))
+	for i, consent := range consents {
+		res[i] = ConsentResponseDTO{
+			ClientID:  consent.ClientID.String(),
+			Scopes:    strings.Fields(consent.Scopes),
+			CreatedAt: consent.CreatedAt,
+			UpdatedAt: consent.UpdatedAt,
+		}
+	}
+
+	utils.ResponseOk(c, res)
+}
+
+// RevokeConsent godoc
+// @Summary Revoke OAuth2 consent
+// @Description Withdraw the access granted to a client
+// @Tags oauth
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param client_id path string true "Client ID"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /oauth/consents/{client_id} [delete]
+func (h *Handler) RevokeConsent(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.RevokeConsent(c.Request.Context(), metadata.UserID, c.Param("client_id")); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// clientCredentials reads client credentials from HTTP Basic authentication, falling back to form parameters.
+func clientCredentials(c *gin.Context, clientID, clientSecret string) ClientCredentials {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		return ClientCredentials{ClientID: id, ClientSecret: secret}
+	}
+
+	return ClientCredentials{ClientID: clientID, ClientSecret: clientSecret}
+}
+
+// responseOAuthError sends an error response in the format defined by RFC 6749 section 5.2.
+func responseOAuthError(c *gin.Context, err error) {
+	var oauthErr *Error
+	if !errors.As(err, &oauthErr) {
+		oauthErr = newError(ServerError, "internal server error")
+	}
+
+	if oauthErr.Code == InvalidClient {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(oauthErr.Status, ErrorResponseDTO{
+		Error:            oauthErr.Code,
+		ErrorDescription: oauthErr.Description,
+	})
+}
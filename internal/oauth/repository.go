@@ -0,0 +1,123 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository represents the OAuth2 client and consent repository interface.
+type Repository interface {
+	CreateClient(ctx context.Context, client *model.OAuthClient) error
+	GetClient(ctx context.Context, id string) (*model.OAuthClient, error)
+	ListClients(ctx context.Context, ownerID string) ([]model.OAuthClient, error)
+	DeleteClient(ctx context.Context, id string, ownerID string) error
+	GetConsent(ctx context.Context, userID string, clientID string) (*model.OAuthConsent, error)
+	SaveConsent(ctx context.Context, consent *model.OAuthConsent) error
+	ListConsents(ctx context.Context, userID string) ([]model.OAuthConsent, error)
+	DeleteConsent(ctx context.Context, userID string, clientID string) error
+}
+
+// repository implements the Repository interface.
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) CreateClient(ctx context.Context, client *model.OAuthClient) error {
+	if err := r.db.WithContext(ctx).Create(client).Error; err != nil {
+		return errs.FromGorm(err)
+	}
+	return nil
+}
+
+// GetClient returns the client unless its owner is disabled: such clients can neither authenticate
+// nor be authorized until the owner is enabled again.
+func (r *repository) GetClient(ctx context.Context, id string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.db.WithContext(ctx).
+		Joins("JOIN users ON users.id = oauth_clients.owner_id AND users.status <> ?", model.UserDisabled).
+		Where("oauth_clients.id = ?", id).
+		First(&client).Error
+	if err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return &client, nil
+}
+
+func (r *repository) ListClients(ctx context.Context, ownerID string) ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	if err := r.db.WithContext(ctx).Where("owner_id = ?", ownerID).Order("created_at").Find(&clients).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return clients, nil
+}
+
+func (r *repository) DeleteClient(ctx context.Context, id string, ownerID string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND owner_id = ?", id, ownerID).Delete(&model.OAuthClient{})
+	if result.Error != nil {
+		return errs.FromGorm(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.New(http.StatusNotFound, gorm.ErrRecordNotFound, "client not found")
+	}
+
+	return nil
+}
+
+func (r *repository) GetConsent(ctx context.Context, userID string, clientID string) (*model.OAuthConsent, error) {
+	var consent model.OAuthConsent
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND client_id = ?", userID, clientID).First(&consent).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return &consent, nil
+}
+
+func (r *repository) SaveConsent(ctx context.Context, consent *model.OAuthConsent) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "client_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"scopes":     consent.Scopes,
+			"updated_at": time.Now(),
+		}),
+	}).Create(consent).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+func (r *repository) ListConsents(ctx context.Context, userID string) ([]model.OAuthConsent, error) {
+	var consents []model.OAuthConsent
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&consents).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return consents, nil
+}
+
+func (r *repository) DeleteConsent(ctx context.Context, userID string, clientID string) error {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND client_id = ?", userID, clientID).Delete(&model.OAuthConsent{})
+	if result.Error != nil {
+		return errs.FromGorm(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.New(http.StatusNotFound, gorm.ErrRecordNotFound, "consent not found")
+	}
+
+	return nil
+}
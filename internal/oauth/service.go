@@ -0,0 +1,730 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Grant types supported by the token endpoint.
+const (
+	AuthorizationCodeGrant = "authorization_code"
+	ClientCredentialsGrant = "client_credentials"
+	RefreshTokenGrant      = "refresh_token"
+)
+
+// AuthorizationCodeTTL is how long an authorization code may be exchanged for tokens.
+const AuthorizationCodeTTL = time.Minute
+
+// Service represents the OAuth2 authorization server interface.
+type Service interface {
+	RegisterClient(ctx context.Context, ownerID string, client *model.OAuthClient) (string, error)
+	ListClients(ctx context.Context, ownerID string) ([]model.OAuthClient, error)
+	DeleteClient(ctx context.Context, ownerID string, clientID string) error
+	Authorize(ctx context.Context, user *auth.AccessProperties, req *AuthorizationRequest) (*AuthorizationResult, error)
+	Token(ctx context.Context, req *TokenRequest) (*TokenResult, error)
+	Introspect(ctx context.Context, client *ClientCredentials, token string) (*Introspection, error)
+	Revoke(ctx context.Context, client *ClientCredentials, token string) error
+	ListConsents(ctx context.Context, userID string) ([]model.OAuthConsent, error)
+	RevokeConsent(ctx context.Context, userID string, clientID string) error
+}
+
+// AuthorizationRequest represents an authorization request (RFC 6749 section 4.1.1) with PKCE (RFC 7636).
+// Approve is nil while the user has not answered the consent prompt.
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Approve             *bool
+}
+
+// AuthorizationResult represents the outcome of an authorization request.
+// When ConsentRequired is set the user must approve the scopes first, otherwise
+// RedirectURI carries either the authorization code or an access_denied error.
+type AuthorizationResult struct {
+	Client          *model.OAuthClient
+	Scopes          []auth.Scope
+	ConsentRequired bool
+	RedirectURI     string
+}
+
+// ClientCredentials identifies the client calling the token, introspection or revocation endpoint.
+type ClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// TokenRequest represents a token request for any supported grant type.
+type TokenRequest struct {
+	ClientCredentials
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// TokenResult represents a successful token response.
+type TokenResult struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+	Scope        string
+}
+
+// Introspection represents a token introspection response (RFC 7662 section 2.2).
+type Introspection struct {
+	Active    bool
+	Scope     string
+	ClientID  string
+	Subject   string
+	Email     string
+	TokenType string
+	ExpiresAt int64
+}
+
+// ServiceOpts contains optional collaborators for the OAuth2 service.
+// Unset fields fall back to in-memory implementations.
+type ServiceOpts struct {
+	AuthorizationCodes auth.AuthorizationCodes
+	PasswordHasher     *crypto.PasswordHasher
+}
+
+// service implements the Service interface
+type service struct {
+	repo         Repository
+	auth         auth.Auth
+	tokenManager auth.TokenManager
+	enforcer     auth.AuthEnforcer
+	codes        auth.AuthorizationCodes
+	passwords    *crypto.PasswordHasher
+}
+
+func NewService(repo Repository, auth auth.Auth, tokenManager auth.TokenManager, enforcer auth.AuthEnforcer, opts ...*ServiceOpts) *service {
+	option := ServiceOpts{}
+	if len(opts) > 0 {
+		option = *opts[0]
+	}
+	option = option.withDefaults()
+
+	return &service{repo, auth, tokenManager, enforcer, option.AuthorizationCodes, option.PasswordHasher}
+}
+
+// withDefaults fills unset collaborators with in-memory implementations.
+func (o ServiceOpts) withDefaults() ServiceOpts {
+	if o.AuthorizationCodes == nil {
+		o.AuthorizationCodes = auth.NewMemoryAuthorizationCodes()
+	}
+
+	if o.PasswordHasher == nil {
+		o.PasswordHasher = crypto.NewPasswordHasher(nil)
+	}
+
+	return o
+}
+
+func (s *service) RegisterClient(ctx context.Context, ownerID string, client *model.OAuthClient) (string, error) {
+	scopes, err := s.validateClient(ownerID, client)
+	if err != nil {
+		return "", err
+	}
+
+	client.ID = uuid.New()
+	client.OwnerID = uuid.MustParse(ownerID)
+	client.Scopes = auth.JoinScopes(scopes)
+
+	secret := ""
+	if client.Confidential {
+		secret = rand.Text()
+		client.HashedSecret, err = s.passwords.Hash(ctx, secret)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to hash client secret")
+			return "", err
+		}
+	}
+
+	if err := s.repo.CreateClient(ctx, client); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to create oauth client")
+		return "", err
+	}
+
+	// Clients acting on their own behalf are authorized like users, so they get one policy per scope.
+	if client.HasGrantType(ClientCredentialsGrant) {
+		for _, scope := range scopes {
+			obj, act := scope.Split()
+			if err := s.enforcer.AddPolicy(ctx, auth.ClientSubject(client.ID.String()), obj, act); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("client_id", client.ID.String()).Msg("🚨 failed to add client policy")
+				return "", err
+			}
+		}
+	}
+
+	return secret, nil
+}
+
+func (s *service) ListClients(ctx context.Context, ownerID string) ([]model.OAuthClient, error) {
+	clients, err := s.repo.ListClients(ctx, ownerID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list oauth clients")
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+func (s *service) DeleteClient(ctx context.Context, ownerID string, clientID string) error {
+	client, err := s.getClient(ctx, clientID)
+	if err != nil {
+		return err
+	}
+
+	if client.OwnerID.String() != ownerID {
+		return errs.New(http.StatusNotFound, fmt.Errorf("client %s is not owned by %s", clientID, ownerID), "client not found")
+	}
+
+	if err := s.repo.DeleteClient(ctx, clientID, ownerID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to delete oauth client")
+		return err
+	}
+
+	if client.HasGrantType(ClientCredentialsGrant) {
+		scopes, _ := auth.ParseScopes(client.Scopes)
+		for _, scope := range scopes {
+			obj, act := scope.Split()
+			if err := s.enforcer.RemovePolicy(ctx, auth.ClientSubject(clientID), obj, act); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("client_id", clientID).Msg("🚨 failed to remove client policy")
+				return err
+			}
+		}
+	}
+
+	// Tokens issued to the client, on behalf of users or its own, stop working with it.
+	if err := s.auth.DeleteUserTokens(ctx, auth.ClientSubject(clientID), ""); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("client_id", clientID).Msg("🚨 failed to revoke client tokens")
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) Authorize(ctx context.Context, user *auth.AccessProperties, req *AuthorizationRequest) (*AuthorizationResult, error) {
+	client, err := s.getClient(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.HasGrantType(AuthorizationCodeGrant) {
+		return nil, errs.BadRequest("client may not use the authorization code grant")
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(strings.Fields(client.RedirectURIs)) == 1 {
+		redirectURI = client.RedirectURIs
+	}
+
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, errs.BadRequest("redirect_uri is not registered for this client")
+	}
+
+	if req.ResponseType != "code" {
+		return nil, errs.BadRequest("response_type must be code")
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return nil, errs.BadRequest("PKCE with code_challenge_method S256 is required")
+	}
+
+	scopes, err := s.requestedScopes(client, req.Scope)
+	if err != nil {
+		return nil, errs.BadRequest(err.Error())
+	}
+
+	result := &AuthorizationResult{Client: client, Scopes: scopes}
+	granted, err := s.grantedScopes(ctx, user.UserID, client.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.Approve == nil && !auth.ScopesCover(granted, scopes):
+		result.ConsentRequired = true
+		return result, nil
+	case req.Approve != nil && !*req.Approve:
+		result.RedirectURI = withQuery(redirectURI, map[string]string{"error": AccessDenied, "state": req.State})
+		return result, nil
+	case req.Approve != nil:
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				granted = append(granted, scope)
+			}
+		}
+
+		err := s.repo.SaveConsent(ctx, &model.OAuthConsent{
+			ID:       uuid.New(),
+			UserID:   uuid.MustParse(user.UserID),
+			ClientID: client.ID,
+			Scopes:   auth.JoinScopes(granted),
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to save consent")
+			return nil, err
+		}
+	}
+
+	code := rand.Text()
+	err = s.codes.Save(ctx, code, &auth.AuthorizationGrant{
+		ClientID:      client.ID.String(),
+		UserID:        user.UserID,
+		Email:         user.Email,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+	}, AuthorizationCodeTTL)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to save authorization code")
+		return nil, err
+	}
+
+	result.RedirectURI = withQuery(redirectURI, map[string]string{"code": code, "state": req.State})
+	return result, nil
+}
+
+func (s *service) Token(ctx context.Context, req *TokenRequest) (*TokenResult, error) {
+	client, err := s.authenticateClient(ctx, &req.ClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case AuthorizationCodeGrant, ClientCredentialsGrant, RefreshTokenGrant:
+	default:
+		return nil, newError(UnsupportedGrantType, "grant_type is not supported")
+	}
+
+	if !client.HasGrantType(req.GrantType) {
+		return nil, newError(UnauthorizedClient, "client may not use this grant type")
+	}
+
+	switch req.GrantType {
+	case AuthorizationCodeGrant:
+		return s.exchangeCode(ctx, client, req)
+	case ClientCredentialsGrant:
+		scopes, err := s.requestedScopes(client, req.Scope)
+		if err != nil {
+			return nil, newError(InvalidScope, err.Error())
+		}
+
+		subject := auth.ClientSubject(client.ID.String())
+		return s.issueTokens(ctx, client, subject, "", scopes, false)
+	default:
+		return s.refresh(ctx, client, req)
+	}
+}
+
+func (s *service) Introspect(ctx context.Context, credentials *ClientCredentials, token string) (*Introspection, error) {
+	client, err := s.authenticateClient(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := client.ID.String()
+	if access, ok := s.lookupAccessToken(ctx, token); ok && access.ClientID == clientID {
+		return &Introspection{
+			Active:    true,
+			Scope:     auth.JoinScopes(access.Scopes),
+			ClientID:  clientID,
+			Subject:   access.UserID,
+			Email:     access.Email,
+			TokenType: "access_token",
+			ExpiresAt: access.ExpiresAt,
+		}, nil
+	}
+
+	if refresh, ok := s.lookupRefreshToken(ctx, token); ok && refresh.ClientID == clientID {
+		return &Introspection{
+			Active:    true,
+			Scope:     auth.JoinScopes(refresh.Scopes),
+			ClientID:  clientID,
+			Subject:   refresh.Subject,
+			Email:     refresh.Email,
+			TokenType: "refresh_token",
+			ExpiresAt: refresh.ExpiresAt,
+		}, nil
+	}
+
+	return &Introspection{Active: false}, nil
+}
+
+// Revoke revokes an access or refresh token issued to the client. As required by RFC 7009,
+// unknown tokens and tokens of other clients are ignored rather than reported.
+func (s *service) Revoke(ctx context.Context, credentials *ClientCredentials, token string) error {
+	client, err := s.authenticateClient(ctx, credentials)
+	if err != nil {
+		return err
+	}
+
+	clientID := client.ID.String()
+	if access, ok := s.lookupAccessToken(ctx, token); ok && access.ClientID == clientID {
+		if err := s.auth.DeleteAccessToken(ctx, access); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("client_id", clientID).Msg("⚠️ failed to revoke access token")
+		}
+		return nil
+	}
+
+	if refresh, ok := s.lookupRefreshToken(ctx, token); ok && refresh.ClientID == clientID {
+		s.revokeRefreshToken(ctx, refresh)
+	}
+
+	return nil
+}
+
+func (s *service) ListConsents(ctx context.Context, userID string) ([]model.OAuthConsent, error) {
+	consents, err := s.repo.ListConsents(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list consents")
+		return nil, err
+	}
+
+	return consents, nil
+}
+
+// RevokeConsent removes the scopes a user granted to a client. Outstanding refresh tokens stop
+// working immediately, outstanding access tokens expire on their own.
+func (s *service) RevokeConsent(ctx context.Context, userID string, clientID string) error {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return errs.BadRequest("invalid client id")
+	}
+
+	if err := s.repo.DeleteConsent(ctx, userID, clientID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to delete consent")
+		return err
+	}
+
+	return nil
+}
+
+// exchangeCode redeems an authorization code, verifying the PKCE code verifier.
+func (s *service) exchangeCode(ctx context.Context, client *model.OAuthClient, req *TokenRequest) (*TokenResult, error) {
+	grant, err := s.codes.Take(ctx, req.Code)
+	if err != nil || grant.ClientID != client.ID.String() {
+		return nil, newError(InvalidGrant, "authorization code is invalid or expired")
+	}
+
+	// RFC 6749 section 4.1.3: a redirect_uri sent with the authorization request must be repeated
+	// identically; one omitted there may only name the registered URI it defaulted to.
+	if req.RedirectURI != grant.RedirectURI && (grant.RedirectURI != "" || !client.HasRedirectURI(req.RedirectURI)) {
+		return nil, newError(InvalidGrant, "redirect_uri does not match the authorization request")
+	}
+
+	challenge := sha256.Sum256([]byte(req.CodeVerifier))
+	if subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(challenge[:])), []byte(grant.CodeChallenge)) != 1 {
+		return nil, newError(InvalidGrant, "code_verifier does not match the code challenge")
+	}
+
+	return s.issueTokens(ctx, client, grant.UserID, grant.Email, grant.Scopes, client.HasGrantType(RefreshTokenGrant))
+}
+
+// refresh rotates a refresh token. A refresh token can only be used once, and stops working
+// once the user revokes their consent.
+func (s *service) refresh(ctx context.Context, client *model.OAuthClient, req *TokenRequest) (*TokenResult, error) {
+	refresh, ok := s.lookupRefreshToken(ctx, req.RefreshToken)
+	if !ok || refresh.ClientID != client.ID.String() {
+		return nil, newError(InvalidGrant, "refresh token is invalid or expired")
+	}
+
+	scopes := refresh.Scopes
+	if req.Scope != "" {
+		requested, err := auth.ParseScopes(req.Scope)
+		if err != nil || !auth.ScopesCover(refresh.Scopes, requested) {
+			return nil, newError(InvalidScope, "scope exceeds the original grant")
+		}
+		scopes = requested
+	}
+
+	granted, err := s.grantedScopes(ctx, refresh.Subject, client.ID.String())
+	if err != nil {
+		return nil, newError(ServerError, "failed to load consent")
+	}
+
+	if !auth.ScopesCover(granted, scopes) {
+		return nil, newError(InvalidGrant, "consent has been revoked")
+	}
+
+	if err := s.auth.DeleteRefreshToken(ctx, refresh.RefreshUUID); err != nil {
+		return nil, newError(InvalidGrant, "refresh token is invalid or expired")
+	}
+
+	if err := s.auth.DeleteAccessToken(ctx, &auth.AccessProperties{TokenUUID: refresh.AccessUUID, UserID: refresh.Subject}); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("⚠️ failed to revoke rotated access token")
+	}
+
+	return s.issueTokens(ctx, client, refresh.Subject, refresh.Email, scopes, true)
+}
+
+// issueTokens creates and stores a token pair issued to the client.
+func (s *service) issueTokens(ctx context.Context, client *model.OAuthClient, subject, email string, scopes []auth.Scope, withRefresh bool) (*TokenResult, error) {
+	ts, err := s.tokenManager.CreateClientToken(subject, email, client.ID.String(), scopes, withRefresh)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to create client token")
+		return nil, newError(ServerError, "failed to create token")
+	}
+
+	if err := s.auth.CreateAuth(ctx, subject, ts); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to create auth")
+		return nil, newError(ServerError, "failed to create token")
+	}
+
+	// Tokens issued on behalf of a user are linked to the client too, so deleting it revokes them.
+	if subject != auth.ClientSubject(client.ID.String()) {
+		if err := s.auth.LinkTokens(ctx, auth.ClientSubject(client.ID.String()), ts); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to link client token")
+			return nil, newError(ServerError, "failed to create token")
+		}
+	}
+
+	return &TokenResult{
+		AccessToken:  ts.AccessToken,
+		RefreshToken: ts.RefreshToken,
+		ExpiresIn:    int64(auth.ClientAccessTokenTTL.Seconds()),
+		Scope:        auth.JoinScopes(scopes),
+	}, nil
+}
+
+// authenticateClient verifies the client secret of confidential clients. Public clients
+// authenticate with their client ID only and rely on PKCE.
+func (s *service) authenticateClient(ctx context.Context, credentials *ClientCredentials) (*model.OAuthClient, error) {
+	if _, err := uuid.Parse(credentials.ClientID); err != nil {
+		return nil, newError(InvalidClient, "client authentication failed")
+	}
+
+	client, err := s.repo.GetClient(ctx, credentials.ClientID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("client_id", credentials.ClientID).Msg("🚨 failed to get oauth client")
+		return nil, newError(InvalidClient, "client authentication failed")
+	}
+
+	if client.Confidential {
+		if ok, _ := s.passwords.Compare(ctx, credentials.ClientSecret, client.HashedSecret); !ok {
+			log.Ctx(ctx).Error().Str("client_id", credentials.ClientID).Msg("🚨 invalid client secret")
+			return nil, newError(InvalidClient, "client authentication failed")
+		}
+	} else if credentials.ClientSecret != "" {
+		return nil, newError(InvalidClient, "public clients have no secret")
+	}
+
+	return client, nil
+}
+
+// lookupAccessToken returns the properties of a live access token.
+func (s *service) lookupAccessToken(ctx context.Context, token string) (*auth.AccessProperties, bool) {
+	access, err := s.tokenManager.VerifyAccessToken(token)
+	if err != nil {
+		return nil, false
+	}
+
+	if _, err := s.auth.FetchAuth(ctx, access.TokenUUID); err != nil {
+		return nil, false
+	}
+
+	return access, true
+}
+
+// lookupRefreshToken returns the properties of a live refresh token issued to a client.
+func (s *service) lookupRefreshToken(ctx context.Context, token string) (*auth.ClientRefreshProperties, bool) {
+	refresh, err := s.tokenManager.VerifyClientRefreshToken(token)
+	if err != nil {
+		return nil, false
+	}
+
+	if _, err := s.auth.FetchAuth(ctx, refresh.RefreshUUID); err != nil {
+		return nil, false
+	}
+
+	return refresh, true
+}
+
+// revokeRefreshToken deletes a refresh token together with the access token issued alongside it.
+func (s *service) revokeRefreshToken(ctx context.Context, refresh *auth.ClientRefreshProperties) {
+	if err := s.auth.DeleteRefreshToken(ctx, refresh.RefreshUUID); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("⚠️ failed to revoke refresh token")
+	}
+
+	if err := s.auth.DeleteAccessToken(ctx, &auth.AccessProperties{TokenUUID: refresh.AccessUUID, UserID: refresh.Subject}); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("⚠️ failed to revoke access token")
+	}
+}
+
+// getClient loads a client, reporting malformed and unknown IDs alike as not found.
+func (s *service) getClient(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, errs.New(http.StatusNotFound, err, "client not found")
+	}
+
+	client, err := s.repo.GetClient(ctx, clientID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("client_id", clientID).Msg("🚨 failed to get oauth client")
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// grantedScopes returns the scopes the user has already consented to for the client.
+func (s *service) grantedScopes(ctx context.Context, userID string, clientID string) ([]auth.Scope, error) {
+	consent, err := s.repo.GetConsent(ctx, userID, clientID)
+	if errs.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get consent")
+		return nil, err
+	}
+
+	return auth.ParseScopes(consent.Scopes)
+}
+
+// requestedScopes parses the requested scopes, defaulting to every scope registered for the client.
+func (s *service) requestedScopes(client *model.OAuthClient, raw string) ([]auth.Scope, error) {
+	allowed, err := auth.ParseScopes(client.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(raw) == "" {
+		return allowed, nil
+	}
+
+	requested, err := auth.ParseScopes(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auth.ScopesCover(allowed, requested) {
+		return nil, fmt.Errorf("scope is not registered for this client")
+	}
+
+	return requested, nil
+}
+
+// validateClient checks a client registration and returns its parsed scopes.
+func (s *service) validateClient(ownerID string, client *model.OAuthClient) ([]auth.Scope, error) {
+	grantTypes := strings.Fields(client.GrantTypes)
+	if len(grantTypes) == 0 {
+		return nil, errs.BadRequest("at least one grant type is required")
+	}
+
+	for _, grantType := range grantTypes {
+		switch grantType {
+		case AuthorizationCodeGrant, ClientCredentialsGrant, RefreshTokenGrant:
+		default:
+			return nil, errs.BadRequest(fmt.Sprintf("unsupported grant type %q", grantType))
+		}
+	}
+
+	if client.HasGrantType(RefreshTokenGrant) && !client.HasGrantType(AuthorizationCodeGrant) {
+		return nil, errs.BadRequest("refresh_token requires the authorization_code grant")
+	}
+
+	if client.HasGrantType(ClientCredentialsGrant) && !client.Confidential {
+		return nil, errs.BadRequest("client_credentials requires a confidential client")
+	}
+
+	redirectURIs := strings.Fields(client.RedirectURIs)
+	if client.HasGrantType(AuthorizationCodeGrant) && len(redirectURIs) == 0 {
+		return nil, errs.BadRequest("authorization_code requires at least one redirect URI")
+	}
+
+	for _, redirectURI := range redirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			return nil, errs.BadRequest(err.Error())
+		}
+	}
+
+	scopes, err := auth.ParseScopes(client.Scopes)
+	if err != nil {
+		return nil, errs.BadRequest(err.Error())
+	}
+
+	if len(scopes) == 0 {
+		return nil, errs.BadRequest("at least one scope is required")
+	}
+
+	// A client acting on its own may never be granted more than its owner holds.
+	if client.HasGrantType(ClientCredentialsGrant) {
+		for _, scope := range scopes {
+			obj, act := scope.Split()
+			ok, err := s.enforcer.Enforce(ownerID, obj, act)
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				return nil, errs.New(http.StatusForbidden, fmt.Errorf("owner lacks %s", scope), fmt.Sprintf("you are not allowed to grant %s", scope))
+			}
+		}
+	}
+
+	return scopes, nil
+}
+
+// validateRedirectURI accepts absolute HTTPS URIs, and plain HTTP only for loopback addresses.
+func validateRedirectURI(raw string) error {
+	uri, err := url.Parse(raw)
+	if err != nil || !uri.IsAbs() || uri.Host == "" {
+		return fmt.Errorf("redirect URI %q must be absolute", raw)
+	}
+
+	if uri.Fragment != "" {
+		return fmt.Errorf("redirect URI %q must not contain a fragment", raw)
+	}
+
+	switch uri.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if host := uri.Hostname(); host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("redirect URI %q must use https", raw)
+}
+
+// withQuery appends the non-empty parameters to a redirect URI.
+func withQuery(raw string, params map[string]string) string {
+	uri, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	query := uri.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	uri.RawQuery = query.Encode()
+
+	return uri.String()
+}
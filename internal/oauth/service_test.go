@@ -0,0 +1,451 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/chai-rs/simple-bookstore/internal/oauth"
+	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
+	"gorm.io/gorm"
+)
+
+const (
+	ownerID     = "123e4567-e89b-12d3-a456-426614174000"
+	userID      = "123e4567-e89b-12d3-a456-426614174001"
+	redirectURI = "https://partner.example.com/callback"
+	verifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+// tokenManager signs and verifies the tokens of the tests.
+var tokenManager = auth.NewTokenManager(auth.NewKeys("access-secret", "refresh-secret"))
+
+func TestService_RegisterClient(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		In         *model.OAuthClient
+		Allowed    bool
+		WantSecret bool
+		WantStatus int
+	}
+
+	testcases := []Testcase{
+		{
+			Name: "public-authorization-code",
+			In: &model.OAuthClient{
+				Name:         "partner",
+				RedirectURIs: redirectURI + " http://localhost:8080/callback",
+				Scopes:       "resource:read",
+				GrantTypes:   "authorization_code refresh_token",
+			},
+		},
+		{
+			Name: "confidential-client-credentials",
+			In: &model.OAuthClient{
+				Name:         "partner",
+				Confidential: true,
+				Scopes:       "resource:read resource:write",
+				GrantTypes:   "client_credentials",
+			},
+			Allowed:    true,
+			WantSecret: true,
+		},
+		{
+			Name: "public-client-credentials",
+			In: &model.OAuthClient{
+				Name:       "partner",
+				Scopes:     "resource:read",
+				GrantTypes: "client_credentials",
+			},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name: "insecure-redirect-uri",
+			In: &model.OAuthClient{
+				Name:         "partner",
+				RedirectURIs: "http://partner.example.com/callback",
+				Scopes:       "resource:read",
+				GrantTypes:   "authorization_code",
+			},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name: "unknown-scope",
+			In: &model.OAuthClient{
+				Name:         "partner",
+				RedirectURIs: redirectURI,
+				Scopes:       "orders:read",
+				GrantTypes:   "authorization_code",
+			},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name: "owner-lacks-permission",
+			In: &model.OAuthClient{
+				Name:         "partner",
+				Confidential: true,
+				Scopes:       "resource:write",
+				GrantTypes:   "client_credentials",
+			},
+			WantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			repo := oauth.NewMockRepository(t)
+			repo.EXPECT().CreateClient(mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			enforcer := auth.NewMockAuthEnforcer(t)
+			enforcer.EXPECT().Enforce(ownerID, mock.Anything, mock.Anything).Return(tc.Allowed, nil).Maybe()
+			enforcer.EXPECT().AddPolicy(mock.Anything, mock.Anything, mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, sub string, obj auth.AuthObject, act auth.AuthAction) error {
+				assert.Equal(t, auth.ClientSubject(tc.In.ID.String()), sub)
+				return nil
+			}).Maybe()
+
+			svc := oauth.NewService(repo, auth.NewMemoryAuth(), tokenManager, enforcer)
+			secret, err := svc.RegisterClient(context.Background(), ownerID, tc.In)
+
+			if tc.WantStatus != 0 {
+				assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.WantSecret, secret != "")
+			if tc.WantSecret {
+				ok, _ := crypto.ComparePassword(secret, tc.In.HashedSecret)
+				assert.True(t, ok)
+			}
+		})
+	}
+}
+
+func TestService_AuthorizationCode(t *testing.T) {
+	ctx := context.Background()
+	client := &model.OAuthClient{
+		ID:           uuid.New(),
+		OwnerID:      uuid.MustParse(ownerID),
+		Name:         "partner",
+		RedirectURIs: redirectURI,
+		Scopes:       "resource:read resource:write",
+		GrantTypes:   "authorization_code refresh_token",
+	}
+
+	repo, memoryAuth := newRepository(t, client)
+	svc := oauth.NewService(repo, memoryAuth, tokenManager, auth.NewMockAuthEnforcer(t))
+	user := &auth.AccessProperties{UserID: userID, Email: "one@example.com"}
+	credentials := oauth.ClientCredentials{ClientID: client.ID.String()}
+
+	request := &oauth.AuthorizationRequest{
+		ResponseType:        "code",
+		ClientID:            client.ID.String(),
+		Scope:               "resource:read",
+		State:               "xyz",
+		CodeChallenge:       challengeOf(verifier),
+		CodeChallengeMethod: "S256",
+	}
+
+	// Without consent the user is asked first.
+	result, err := svc.Authorize(ctx, user, request)
+	assert.NoError(t, err)
+	assert.True(t, result.ConsentRequired)
+	assert.Empty(t, result.RedirectURI)
+
+	// Denying sends the user back with an error.
+	request.Approve = pointy.Pointer(false)
+	result, err = svc.Authorize(ctx, user, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "access_denied", queryOf(t, result.RedirectURI).Get("error"))
+
+	// Approving records consent and issues a code, which a wrong verifier cannot redeem.
+	request.Approve = pointy.Pointer(true)
+	result, err = svc.Authorize(ctx, user, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "xyz", queryOf(t, result.RedirectURI).Get("state"))
+
+	_, err = svc.Token(ctx, &oauth.TokenRequest{
+		ClientCredentials: credentials,
+		GrantType:         oauth.AuthorizationCodeGrant,
+		Code:              queryOf(t, result.RedirectURI).Get("code"),
+		CodeVerifier:      "wrong-verifier",
+	})
+	assert.Equal(t, oauth.InvalidGrant, codeOf(err))
+
+	// A redirect_uri sent with the authorization request must be repeated.
+	request.RedirectURI = redirectURI
+	result, err = svc.Authorize(ctx, user, request)
+	assert.NoError(t, err)
+
+	_, err = svc.Token(ctx, &oauth.TokenRequest{
+		ClientCredentials: credentials,
+		GrantType:         oauth.AuthorizationCodeGrant,
+		Code:              queryOf(t, result.RedirectURI).Get("code"),
+		CodeVerifier:      verifier,
+	})
+	assert.Equal(t, oauth.InvalidGrant, codeOf(err))
+
+	// Once consented, the code is issued without asking again.
+	request.Approve = nil
+	result, err = svc.Authorize(ctx, user, request)
+	assert.NoError(t, err)
+	assert.False(t, result.ConsentRequired)
+
+	tokens, err := svc.Token(ctx, &oauth.TokenRequest{
+		ClientCredentials: credentials,
+		GrantType:         oauth.AuthorizationCodeGrant,
+		Code:              queryOf(t, result.RedirectURI).Get("code"),
+		RedirectURI:       redirectURI,
+		CodeVerifier:      verifier,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "resource:read", tokens.Scope)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	access, err := tokenManager.VerifyAccessToken(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, access.UserID)
+	assert.Equal(t, client.ID.String(), access.ClientID)
+	assert.True(t, auth.ScopesAllow(access.Scopes, auth.Resource, auth.Read))
+	assert.False(t, auth.ScopesAllow(access.Scopes, auth.Resource, auth.Write))
+
+	// Refresh tokens rotate and cannot be replayed.
+	refreshed, err := svc.Token(ctx, &oauth.TokenRequest{
+		ClientCredentials: credentials,
+		GrantType:         oauth.RefreshTokenGrant,
+		RefreshToken:      tokens.RefreshToken,
+	})
+	assert.NoError(t, err)
+
+	_, err = svc.Token(ctx, &oauth.TokenRequest{
+		ClientCredentials: credentials,
+		GrantType:         oauth.RefreshTokenGrant,
+		RefreshToken:      tokens.RefreshToken,
+	})
+	assert.Equal(t, oauth.InvalidGrant, codeOf(err))
+
+	introspection, err := svc.Introspect(ctx, &credentials, tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.False(t, introspection.Active)
+
+	introspection, err = svc.Introspect(ctx, &credentials, refreshed.AccessToken)
+	assert.NoError(t, err)
+	assert.True(t, introspection.Active)
+	assert.Equal(t, userID, introspection.Subject)
+	assert.Equal(t, "access_token", introspection.TokenType)
+
+	// Revocation deactivates the access token.
+	assert.NoError(t, svc.Revoke(ctx, &credentials, refreshed.AccessToken))
+	introspection, err = svc.Introspect(ctx, &credentials, refreshed.AccessToken)
+	assert.NoError(t, err)
+	assert.False(t, introspection.Active)
+
+	// Withdrawn consent stops refresh tokens.
+	assert.NoError(t, svc.RevokeConsent(ctx, userID, client.ID.String()))
+	_, err = svc.Token(ctx, &oauth.TokenRequest{
+		ClientCredentials: credentials,
+		GrantType:         oauth.RefreshTokenGrant,
+		RefreshToken:      refreshed.RefreshToken,
+	})
+	assert.Equal(t, oauth.InvalidGrant, codeOf(err))
+}
+
+func TestService_ClientCredentials(t *testing.T) {
+	type Testcase struct {
+		Name     string
+		In       *oauth.TokenRequest
+		WantCode string
+	}
+
+	ctx := context.Background()
+	client := &model.OAuthClient{
+		ID:           uuid.New(),
+		OwnerID:      uuid.MustParse(ownerID),
+		Name:         "partner",
+		Confidential: true,
+		HashedSecret: crypto.MustHashPassword("client-secret"),
+		Scopes:       "resource:read",
+		GrantTypes:   "client_credentials",
+	}
+
+	testcases := []Testcase{
+		{
+			Name: "success",
+			In: &oauth.TokenRequest{
+				ClientCredentials: oauth.ClientCredentials{ClientID: client.ID.String(), ClientSecret: "client-secret"},
+				GrantType:         oauth.ClientCredentialsGrant,
+			},
+		},
+		{
+			Name: "wrong-secret",
+			In: &oauth.TokenRequest{
+				ClientCredentials: oauth.ClientCredentials{ClientID: client.ID.String(), ClientSecret: "wrong"},
+				GrantType:         oauth.ClientCredentialsGrant,
+			},
+			WantCode: oauth.InvalidClient,
+		},
+		{
+			Name: "unregistered-scope",
+			In: &oauth.TokenRequest{
+				ClientCredentials: oauth.ClientCredentials{ClientID: client.ID.String(), ClientSecret: "client-secret"},
+				GrantType:         oauth.ClientCredentialsGrant,
+				Scope:             "resource:write",
+			},
+			WantCode: oauth.InvalidScope,
+		},
+		{
+			Name: "unregistered-grant",
+			In: &oauth.TokenRequest{
+				ClientCredentials: oauth.ClientCredentials{ClientID: client.ID.String(), ClientSecret: "client-secret"},
+				GrantType:         oauth.AuthorizationCodeGrant,
+			},
+			WantCode: oauth.UnauthorizedClient,
+		},
+		{
+			Name: "unsupported-grant",
+			In: &oauth.TokenRequest{
+				ClientCredentials: oauth.ClientCredentials{ClientID: client.ID.String(), ClientSecret: "client-secret"},
+				GrantType:         "password",
+			},
+			WantCode: oauth.UnsupportedGrantType,
+		},
+	}
+
+	repo, memoryAuth := newRepository(t, client)
+	svc := oauth.NewService(repo, memoryAuth, tokenManager, auth.NewMockAuthEnforcer(t))
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tokens, err := svc.Token(ctx, tc.In)
+			if tc.WantCode != "" {
+				assert.Equal(t, tc.WantCode, codeOf(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Empty(t, tokens.RefreshToken)
+
+			introspection, err := svc.Introspect(ctx, &tc.In.ClientCredentials, tokens.AccessToken)
+			assert.NoError(t, err)
+			assert.True(t, introspection.Active)
+			assert.Equal(t, auth.ClientSubject(client.ID.String()), introspection.Subject)
+		})
+	}
+}
+
+func TestService_DeleteClient(t *testing.T) {
+	ctx := context.Background()
+	client := &model.OAuthClient{
+		ID:           uuid.New(),
+		OwnerID:      uuid.MustParse(ownerID),
+		Name:         "partner",
+		Confidential: true,
+		HashedSecret: crypto.MustHashPassword("client-secret"),
+		RedirectURIs: redirectURI,
+		Scopes:       "resource:read",
+		GrantTypes:   "authorization_code refresh_token client_credentials",
+	}
+
+	repo, memoryAuth := newRepository(t, client)
+	repo.EXPECT().DeleteClient(mock.Anything, client.ID.String(), ownerID).Return(nil).Once()
+	enforcer := auth.NewMockAuthEnforcer(t)
+	enforcer.EXPECT().RemovePolicy(mock.Anything, auth.ClientSubject(client.ID.String()), auth.Resource, auth.Read).Return(nil).Once()
+
+	svc := oauth.NewService(repo, memoryAuth, tokenManager, enforcer)
+	user := &auth.AccessProperties{UserID: userID, Email: "one@example.com"}
+	credentials := oauth.ClientCredentials{ClientID: client.ID.String(), ClientSecret: "client-secret"}
+
+	result, err := svc.Authorize(ctx, user, &oauth.AuthorizationRequest{
+		ResponseType:        "code",
+		ClientID:            client.ID.String(),
+		Scope:               "resource:read",
+		CodeChallenge:       challengeOf(verifier),
+		CodeChallengeMethod: "S256",
+		Approve:             pointy.Pointer(true),
+	})
+	assert.NoError(t, err)
+
+	delegated, err := svc.Token(ctx, &oauth.TokenRequest{
+		ClientCredentials: credentials,
+		GrantType:         oauth.AuthorizationCodeGrant,
+		Code:              queryOf(t, result.RedirectURI).Get("code"),
+		CodeVerifier:      verifier,
+	})
+	assert.NoError(t, err)
+
+	own, err := svc.Token(ctx, &oauth.TokenRequest{
+		ClientCredentials: credentials,
+		GrantType:         oauth.ClientCredentialsGrant,
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.DeleteClient(ctx, ownerID, client.ID.String()))
+
+	// Both the tokens issued on behalf of the user and the client's own are revoked.
+	for _, token := range []string{delegated.AccessToken, delegated.RefreshToken, own.AccessToken} {
+		introspection, err := svc.Introspect(ctx, &credentials, token)
+		assert.NoError(t, err)
+		assert.False(t, introspection.Active)
+	}
+}
+
+// newRepository returns a mock repository serving the given client and keeping consents in memory.
+func newRepository(t *testing.T, client *model.OAuthClient) (*oauth.MockRepository, *auth.MemoryAuth) {
+	t.Helper()
+
+	consents := map[string]*model.OAuthConsent{}
+	notFound := errs.FromGorm(gorm.ErrRecordNotFound)
+
+	repo := oauth.NewMockRepository(t)
+	repo.EXPECT().GetClient(mock.Anything, client.ID.String()).Return(client, nil).Maybe()
+	repo.EXPECT().GetConsent(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, userID string, clientID string) (*model.OAuthConsent, error) {
+		if consent, ok := consents[userID+clientID]; ok {
+			return consent, nil
+		}
+		return nil, notFound
+	}).Maybe()
+	repo.EXPECT().SaveConsent(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, consent *model.OAuthConsent) error {
+		consents[consent.UserID.String()+consent.ClientID.String()] = consent
+		return nil
+	}).Maybe()
+	repo.EXPECT().DeleteConsent(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, userID string, clientID string) error {
+		delete(consents, userID+clientID)
+		return nil
+	}).Maybe()
+
+	return repo, auth.NewMemoryAuth()
+}
+
+func challengeOf(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func queryOf(t *testing.T, raw string) url.Values {
+	t.Helper()
+
+	uri, err := url.Parse(raw)
+	assert.NoError(t, err)
+	return uri.Query()
+}
+
+// codeOf returns the OAuth2 error code carried by an error.
+func codeOf(err error) string {
+	var oauthErr *oauth.Error
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+
+	return ""
+}
@@ -0,0 +1,109 @@
+package policy
+
+import "github.com/chai-rs/simple-bookstore/infrastructure/auth"
+
+// PolicyDTO represents a policy allowing a subject to perform an action on an object.
+// Subjects are user IDs, "role:<name>", "client:<id>", "apikey:<id>" or "@owner";
+// objects may target a single instance as "<object>/<id>".
+type PolicyDTO struct {
+	Subject string `json:"subject" form:"subject" binding:"required"`
+	Object  string `json:"object" form:"object" binding:"required"`
+	Action  string `json:"action" form:"action" binding:"required"`
+}
+
+// ToPolicy converts PolicyDTO to an auth.Policy.
+func (r *PolicyDTO) ToPolicy() auth.Policy {
+	return auth.Policy{
+		Subject: r.Subject,
+		Object:  auth.AuthObject(r.Object),
+		Action:  auth.AuthAction(r.Action),
+	}
+}
+
+// NewPolicyDTO converts an auth.Policy to PolicyDTO.
+func NewPolicyDTO(policy auth.Policy) PolicyDTO {
+	return PolicyDTO{
+		Subject: policy.Subject,
+		Object:  policy.Object.String(),
+		Action:  policy.Action.String(),
+	}
+}
+
+// PolicyFilterDTO represents the query filters for listing policies. Empty fields match anything.
+type PolicyFilterDTO struct {
+	Subject string `form:"subject"`
+	Object  string `form:"object"`
+	Action  string `form:"action"`
+}
+
+// ToPolicy converts PolicyFilterDTO to an auth.Policy filter.
+func (r *PolicyFilterDTO) ToPolicy() auth.Policy {
+	return auth.Policy{
+		Subject: r.Subject,
+		Object:  auth.AuthObject(r.Object),
+		Action:  auth.AuthAction(r.Action),
+	}
+}
+
+// GroupingDTO represents a grouping making a member inherit the permissions of a role.
+type GroupingDTO struct {
+	Member string `json:"member" form:"member" binding:"required"`
+	Group  string `json:"group" form:"group" binding:"required"`
+}
+
+// ToGrouping converts GroupingDTO to an auth.Grouping.
+func (r *GroupingDTO) ToGrouping() auth.Grouping {
+	return auth.Grouping{Member: r.Member, Group: r.Group}
+}
+
+// NewGroupingDTO converts an auth.Grouping to GroupingDTO.
+func NewGroupingDTO(grouping auth.Grouping) GroupingDTO {
+	return GroupingDTO{Member: grouping.Member, Group: grouping.Group}
+}
+
+// GroupingFilterDTO represents the query filters for listing groupings. Empty fields match anything.
+type GroupingFilterDTO struct {
+	Member string `form:"member"`
+	Group  string `form:"group"`
+}
+
+// ToGrouping converts GroupingFilterDTO to an auth.Grouping filter.
+func (r *GroupingFilterDTO) ToGrouping() auth.Grouping {
+	return auth.Grouping{Member: r.Member, Group: r.Group}
+}
+
+// ExplainRequestDTO represents a request to evaluate. Set ID and Owner to evaluate a single resource.
+type ExplainRequestDTO struct {
+	Subject string `json:"subject" binding:"required"`
+	Object  string `json:"object" binding:"required"`
+	Action  string `json:"action" binding:"required"`
+	ID      string `json:"id"`
+	Owner   string `json:"owner"`
+}
+
+// ToAuthResource converts ExplainRequestDTO to the auth.AuthResource it targets.
+func (r *ExplainRequestDTO) ToAuthResource() *auth.AuthResource {
+	return &auth.AuthResource{
+		Object: auth.AuthObject(r.Object),
+		ID:     r.ID,
+		Owner:  r.Owner,
+	}
+}
+
+// ExplainResponseDTO represents an authorization decision. Matched is the policy that allowed it.
+type ExplainResponseDTO struct {
+	Allowed bool       `json:"allowed"`
+	Matched *PolicyDTO `json:"matched,omitempty"`
+	Roles   []string   `json:"roles"`
+}
+
+// NewExplainResponseDTO converts an auth.Explanation to ExplainResponseDTO.
+func NewExplainResponseDTO(explanation *auth.Explanation) ExplainResponseDTO {
+	res := ExplainResponseDTO{Allowed: explanation.Allowed, Roles: explanation.Roles}
+	if explanation.Matched != nil {
+		matched := NewPolicyDTO(*explanation.Matched)
+		res.Matched = &matched
+	}
+
+	return res
+}
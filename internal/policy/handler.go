@@ -0,0 +1,239 @@
+package policy
+
+import (
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+// ListPolicies godoc
+// @Summary List policies
+// @Description List the policy rules, optionally filtered by subject, object and action. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param subject query string false "Subject"
+// @Param object query string false "Object"
+// @Param action query string false "Action"
+// @Success 200 {array} PolicyDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/policies [get]
+func (h *Handler) ListPolicies(c *gin.Context) {
+	var req PolicyFilterDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	policies, err := h.service.ListPolicies(c.Request.Context(), req.ToPolicy())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	res := make([]PolicyDTO, len(policies))
+	for i, policy := range policies {
+		res[i] = NewPolicyDTO(policy)
+	}
+
+	utils.ResponseOk(c, res)
+}
+
+// AddPolicy godoc
+// @Summary Add policy
+// @Description Allow a subject to perform an action on an object. The change is applied on every instance. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body PolicyDTO true "Policy"
+// @Success 201 {object} PolicyDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/policies [post]
+func (h *Handler) AddPolicy(c *gin.Context) {
+	var req PolicyDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.AddPolicy(c.Request.Context(), req.ToPolicy()); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseCreated(c, req)
+}
+
+// RemovePolicy godoc
+// @Summary Remove policy
+// @Description Remove a policy rule. Seeded role policies cannot be removed. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param subject query string true "Subject"
+// @Param object query string true "Object"
+// @Param action query string true "Action"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/policies [delete]
+func (h *Handler) RemovePolicy(c *gin.Context) {
+	var req PolicyDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.RemovePolicy(c.Request.Context(), req.ToPolicy()); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// ListGroupings godoc
+// @Summary List groupings
+// @Description List the grouping rules, optionally filtered by member and group. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param member query string false "Member"
+// @Param group query string false "Group"
+// @Success 200 {array} GroupingDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/groupings [get]
+func (h *Handler) ListGroupings(c *gin.Context) {
+	var req GroupingFilterDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	groupings, err := h.service.ListGroupings(c.Request.Context(), req.ToGrouping())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	res := make([]GroupingDTO, len(groupings))
+	for i, grouping := range groupings {
+		res[i] = NewGroupingDTO(grouping)
+	}
+
+	utils.ResponseOk(c, res)
+}
+
+// AddGrouping godoc
+// @Summary Add grouping
+// @Description Make a member, a user ID or another role, inherit the permissions of a role. The change is applied on every instance. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body GroupingDTO true "Grouping"
+// @Success 201 {object} GroupingDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/groupings [post]
+func (h *Handler) AddGrouping(c *gin.Context) {
+	var req GroupingDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.AddGrouping(c.Request.Context(), req.ToGrouping()); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseCreated(c, req)
+}
+
+// RemoveGrouping godoc
+// @Summary Remove grouping
+// @Description Remove a grouping rule. Seeded role inheritances cannot be removed. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param member query string true "Member"
+// @Param group query string true "Group"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/groupings [delete]
+func (h *Handler) RemoveGrouping(c *gin.Context) {
+	var req GroupingDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.RemoveGrouping(c.Request.Context(), req.ToGrouping()); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// Explain godoc
+// @Summary Explain authorization decision
+// @Description Evaluate whether a subject may perform an action on an object, or on a single resource when id and owner are given, without performing it. Returns the matched policy and the subject's roles. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body ExplainRequestDTO true "Request to evaluate"
+// @Success 200 {object} ExplainResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/authz/explain [post]
+func (h *Handler) Explain(c *gin.Context) {
+	var req ExplainRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	explanation, err := h.service.Explain(c.Request.Context(), req.Subject, req.ToAuthResource(), auth.AuthAction(req.Action))
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, NewExplainResponseDTO(explanation))
+}
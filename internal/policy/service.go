@@ -0,0 +1,224 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/rs/zerolog/log"
+)
+
+// Service represents the policy administration service interface.
+type Service interface {
+	ListPolicies(ctx context.Context, filter auth.Policy) ([]auth.Policy, error)
+	AddPolicy(ctx context.Context, policy auth.Policy) error
+	RemovePolicy(ctx context.Context, policy auth.Policy) error
+	ListGroupings(ctx context.Context, filter auth.Grouping) ([]auth.Grouping, error)
+	AddGrouping(ctx context.Context, grouping auth.Grouping) error
+	RemoveGrouping(ctx context.Context, grouping auth.Grouping) error
+	Explain(ctx context.Context, sub string, res *auth.AuthResource, act auth.AuthAction) (*auth.Explanation, error)
+}
+
+// service implements the Service interface
+type service struct {
+	enforcer auth.AuthEnforcer
+}
+
+func NewService(enforcer auth.AuthEnforcer) *service {
+	return &service{enforcer}
+}
+
+func (s *service) ListPolicies(ctx context.Context, filter auth.Policy) ([]auth.Policy, error) {
+	policies, err := s.enforcer.GetPolicies(filter)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list policies")
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// AddPolicy adds a policy on a known object, or on a single instance of it as "<object>/<id>".
+func (s *service) AddPolicy(ctx context.Context, policy auth.Policy) error {
+	if err := validatePolicy(policy); err != nil {
+		return err
+	}
+
+	exists, err := s.enforcer.GetPolicies(policy)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get policy")
+		return err
+	}
+
+	if len(exists) > 0 {
+		return errs.New(http.StatusConflict, fmt.Errorf("policy already exists"), "policy already exists")
+	}
+
+	if err := s.enforcer.AddPolicy(ctx, policy.Subject, policy.Object, policy.Action); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to add policy")
+		return err
+	}
+
+	log.Ctx(ctx).Info().Str("sub", policy.Subject).Str("obj", policy.Object.String()).Str("act", policy.Action.String()).Msg("🔐 added policy")
+	return nil
+}
+
+// RemovePolicy removes a policy. The seeded role and owner policies cannot be removed.
+func (s *service) RemovePolicy(ctx context.Context, policy auth.Policy) error {
+	if err := validatePolicy(policy); err != nil {
+		return err
+	}
+
+	if auth.IsSeededPolicy(policy) {
+		return errs.New(http.StatusConflict, fmt.Errorf("policy is seeded"), "seeded policies cannot be removed")
+	}
+
+	exists, err := s.enforcer.GetPolicies(policy)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get policy")
+		return err
+	}
+
+	if len(exists) == 0 {
+		return errs.New(http.StatusNotFound, fmt.Errorf("policy not found"), "policy not found")
+	}
+
+	if err := s.enforcer.RemovePolicy(ctx, policy.Subject, policy.Object, policy.Action); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to remove policy")
+		return err
+	}
+
+	log.Ctx(ctx).Info().Str("sub", policy.Subject).Str("obj", policy.Object.String()).Str("act", policy.Action.String()).Msg("🔐 removed policy")
+	return nil
+}
+
+func (s *service) ListGroupings(ctx context.Context, filter auth.Grouping) ([]auth.Grouping, error) {
+	groupings, err := s.enforcer.GetGroupings(filter)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list groupings")
+		return nil, err
+	}
+
+	return groupings, nil
+}
+
+// AddGrouping makes a member, a user ID or another role, inherit the permissions of a role.
+func (s *service) AddGrouping(ctx context.Context, grouping auth.Grouping) error {
+	if err := validateGrouping(grouping); err != nil {
+		return err
+	}
+
+	exists, err := s.enforcer.GetGroupings(grouping)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get grouping")
+		return err
+	}
+
+	if len(exists) > 0 {
+		return errs.New(http.StatusConflict, fmt.Errorf("grouping already exists"), "grouping already exists")
+	}
+
+	if err := s.enforcer.AddGrouping(ctx, grouping.Member, grouping.Group); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to add grouping")
+		return err
+	}
+
+	log.Ctx(ctx).Info().Str("member", grouping.Member).Str("group", grouping.Group).Msg("🔐 added grouping")
+	return nil
+}
+
+// RemoveGrouping removes a grouping. The seeded role inheritances cannot be removed.
+func (s *service) RemoveGrouping(ctx context.Context, grouping auth.Grouping) error {
+	if err := validateGrouping(grouping); err != nil {
+		return err
+	}
+
+	if auth.IsSeededGrouping(grouping) {
+		return errs.New(http.StatusConflict, fmt.Errorf("grouping is seeded"), "seeded groupings cannot be removed")
+	}
+
+	exists, err := s.enforcer.GetGroupings(grouping)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get grouping")
+		return err
+	}
+
+	if len(exists) == 0 {
+		return errs.New(http.StatusNotFound, fmt.Errorf("grouping not found"), "grouping not found")
+	}
+
+	if err := s.enforcer.RemoveGrouping(ctx, grouping.Member, grouping.Group); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to remove grouping")
+		return err
+	}
+
+	log.Ctx(ctx).Info().Str("member", grouping.Member).Str("group", grouping.Group).Msg("🔐 removed grouping")
+	return nil
+}
+
+// Explain evaluates a request without performing it and reports the policy that matched, if any.
+func (s *service) Explain(ctx context.Context, sub string, res *auth.AuthResource, act auth.AuthAction) (*auth.Explanation, error) {
+	if strings.TrimSpace(sub) == "" {
+		return nil, errs.BadRequest("subject is required")
+	}
+
+	if !slices.Contains(auth.Objects, res.Object) {
+		return nil, errs.BadRequest(fmt.Sprintf("unknown object %q", res.Object))
+	}
+
+	if !slices.Contains(auth.Actions, act) {
+		return nil, errs.BadRequest(fmt.Sprintf("unknown action %q", act))
+	}
+
+	explanation, err := s.enforcer.Explain(sub, res, act)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to explain authorization decision")
+		return nil, err
+	}
+
+	return explanation, nil
+}
+
+// validatePolicy checks that a policy names a subject, a known object and a known action.
+func validatePolicy(policy auth.Policy) error {
+	if strings.TrimSpace(policy.Subject) == "" {
+		return errs.BadRequest("subject is required")
+	}
+
+	base, id, instance := strings.Cut(policy.Object.String(), "/")
+	if !slices.Contains(auth.Objects, auth.AuthObject(base)) || instance && id == "" {
+		return errs.BadRequest(fmt.Sprintf("unknown object %q", policy.Object))
+	}
+
+	if !slices.Contains(auth.Actions, policy.Action) {
+		return errs.BadRequest(fmt.Sprintf("unknown action %q", policy.Action))
+	}
+
+	return nil
+}
+
+// validateGrouping checks that a grouping makes a member inherit a seeded role other than itself.
+func validateGrouping(grouping auth.Grouping) error {
+	if strings.TrimSpace(grouping.Member) == "" {
+		return errs.BadRequest("member is required")
+	}
+
+	role, ok := auth.RoleFromSubject(grouping.Group)
+	if !ok {
+		return errs.BadRequest(fmt.Sprintf("group must be a role subject such as %q", auth.DefaultRole.Subject()))
+	}
+
+	if _, err := auth.ParseRole(role.String()); err != nil {
+		return errs.BadRequest(err.Error())
+	}
+
+	if grouping.Member == grouping.Group {
+		return errs.BadRequest("a role cannot inherit itself")
+	}
+
+	return nil
+}
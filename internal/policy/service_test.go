@@ -0,0 +1,211 @@
+package policy_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_AddPolicy(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		In         auth.Policy
+		Existing   []auth.Policy
+		WantStatus int
+	}
+
+	testcases := []Testcase{
+		{
+			Name: "success",
+			In:   auth.Policy{Subject: "role:support", Object: auth.Users, Action: auth.Read},
+		},
+		{
+			Name: "instance",
+			In:   auth.Policy{Subject: "role:support", Object: auth.Resource + "/42", Action: auth.Write},
+		},
+		{
+			Name:       "no-subject",
+			In:         auth.Policy{Object: auth.Users, Action: auth.Read},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "unknown-object",
+			In:         auth.Policy{Subject: "role:support", Object: "orders", Action: auth.Read},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "empty-instance",
+			In:         auth.Policy{Subject: "role:support", Object: auth.Resource + "/", Action: auth.Read},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "unknown-action",
+			In:         auth.Policy{Subject: "role:support", Object: auth.Users, Action: "delete"},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "exists",
+			In:         auth.Policy{Subject: "role:support", Object: auth.Users, Action: auth.Read},
+			Existing:   []auth.Policy{{Subject: "role:support", Object: auth.Users, Action: auth.Read}},
+			WantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			enforcer := auth.NewMockAuthEnforcer(t)
+			enforcer.EXPECT().GetPolicies(tc.In).Return(tc.Existing, nil).Maybe()
+			if tc.WantStatus == 0 {
+				enforcer.EXPECT().AddPolicy(mock.Anything, tc.In.Subject, tc.In.Object, tc.In.Action).Return(nil).Once()
+			}
+
+			err := policy.NewService(enforcer).AddPolicy(context.Background(), tc.In)
+			assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+		})
+	}
+}
+
+func TestService_RemovePolicy(t *testing.T) {
+	custom := auth.Policy{Subject: "role:support", Object: auth.Users, Action: auth.Read}
+	seeded := auth.Policy{Subject: auth.Admin.Subject(), Object: auth.Policies, Action: auth.Write}
+
+	enforcer := auth.NewMockAuthEnforcer(t)
+	enforcer.EXPECT().GetPolicies(custom).Return([]auth.Policy{custom}, nil).Once()
+	enforcer.EXPECT().GetPolicies(custom).Return(nil, nil).Once()
+	enforcer.EXPECT().RemovePolicy(mock.Anything, custom.Subject, custom.Object, custom.Action).Return(nil).Once()
+
+	svc := policy.NewService(enforcer)
+	assert.NoError(t, svc.RemovePolicy(context.Background(), custom))
+	assert.Equal(t, http.StatusNotFound, errs.StatusOf(svc.RemovePolicy(context.Background(), custom)))
+	assert.Equal(t, http.StatusConflict, errs.StatusOf(svc.RemovePolicy(context.Background(), seeded)))
+}
+
+func TestService_AddGrouping(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		In         auth.Grouping
+		WantStatus int
+	}
+
+	testcases := []Testcase{
+		{
+			Name: "user",
+			In:   auth.Grouping{Member: "123e4567-e89b-12d3-a456-426614174000", Group: auth.Editor.Subject()},
+		},
+		{
+			Name: "role",
+			In:   auth.Grouping{Member: "role:support", Group: auth.Customer.Subject()},
+		},
+		{
+			Name:       "no-member",
+			In:         auth.Grouping{Group: auth.Editor.Subject()},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "not-a-role",
+			In:         auth.Grouping{Member: "role:support", Group: "support"},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "unknown-role",
+			In:         auth.Grouping{Member: "role:support", Group: "role:owner"},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "itself",
+			In:         auth.Grouping{Member: auth.Editor.Subject(), Group: auth.Editor.Subject()},
+			WantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			enforcer := auth.NewMockAuthEnforcer(t)
+			enforcer.EXPECT().GetGroupings(tc.In).Return(nil, nil).Maybe()
+			if tc.WantStatus == 0 {
+				enforcer.EXPECT().AddGrouping(mock.Anything, tc.In.Member, tc.In.Group).Return(nil).Once()
+			}
+
+			err := policy.NewService(enforcer).AddGrouping(context.Background(), tc.In)
+			assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+		})
+	}
+}
+
+func TestService_RemoveGrouping(t *testing.T) {
+	custom := auth.Grouping{Member: "role:support", Group: auth.Customer.Subject()}
+	seeded := auth.Grouping{Member: auth.Admin.Subject(), Group: auth.Editor.Subject()}
+
+	enforcer := auth.NewMockAuthEnforcer(t)
+	enforcer.EXPECT().GetGroupings(custom).Return([]auth.Grouping{custom}, nil).Once()
+	enforcer.EXPECT().RemoveGrouping(mock.Anything, custom.Member, custom.Group).Return(nil).Once()
+	enforcer.EXPECT().GetGroupings(mock.Anything).Return(nil, nil).Once()
+
+	svc := policy.NewService(enforcer)
+	assert.NoError(t, svc.RemoveGrouping(context.Background(), custom))
+	assert.Equal(t, http.StatusConflict, errs.StatusOf(svc.RemoveGrouping(context.Background(), seeded)))
+	assert.Equal(t, http.StatusNotFound, errs.StatusOf(svc.RemoveGrouping(context.Background(), custom)))
+}
+
+func TestService_Explain(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		Sub        string
+		Res        *auth.AuthResource
+		Act        auth.AuthAction
+		WantStatus int
+	}
+
+	testcases := []Testcase{
+		{
+			Name: "success",
+			Sub:  "123e4567-e89b-12d3-a456-426614174000",
+			Res:  &auth.AuthResource{Object: auth.APIKeys, ID: "key-1", Owner: "123e4567-e89b-12d3-a456-426614174000"},
+			Act:  auth.Write,
+		},
+		{
+			Name:       "no-subject",
+			Res:        &auth.AuthResource{Object: auth.Resource},
+			Act:        auth.Read,
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "unknown-object",
+			Sub:        "role:editor",
+			Res:        &auth.AuthResource{Object: "orders"},
+			Act:        auth.Read,
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "unknown-action",
+			Sub:        "role:editor",
+			Res:        &auth.AuthResource{Object: auth.Resource},
+			Act:        "delete",
+			WantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			enforcer := auth.NewMockAuthEnforcer(t)
+			if tc.WantStatus == 0 {
+				enforcer.EXPECT().Explain(tc.Sub, tc.Res, tc.Act).Return(&auth.Explanation{Allowed: true}, nil).Once()
+			}
+
+			explanation, err := policy.NewService(enforcer).Explain(context.Background(), tc.Sub, tc.Res, tc.Act)
+			if tc.WantStatus != 0 {
+				assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, explanation.Allowed)
+		})
+	}
+}
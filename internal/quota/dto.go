@@ -0,0 +1,60 @@
+package quota
+
+import (
+	"time"
+
+	counter "github.com/chai-rs/simple-bookstore/infrastructure/quota"
+)
+
+// PeriodResponseDTO represents the quota of one period. Limit is omitted when the period is unlimited.
+type PeriodResponseDTO struct {
+	Period  string    `json:"period"`
+	Limit   *int64    `json:"limit,omitempty"`
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// SubjectUsageResponseDTO represents the requests counted for the user or one of their API keys.
+type SubjectUsageResponseDTO struct {
+	Subject string           `json:"subject"`
+	Name    string           `json:"name,omitempty"`
+	Used    map[string]int64 `json:"used"`
+}
+
+// UsageResponseDTO represents the plan and quota usage of the current user.
+type UsageResponseDTO struct {
+	Plan     string                    `json:"plan"`
+	Periods  []PeriodResponseDTO       `json:"periods"`
+	Subjects []SubjectUsageResponseDTO `json:"subjects"`
+}
+
+// NewUsageResponseDTO converts a Report to UsageResponseDTO.
+func NewUsageResponseDTO(report *Report) UsageResponseDTO {
+	res := UsageResponseDTO{
+		Plan:     report.Plan.String(),
+		Periods:  make([]PeriodResponseDTO, len(counter.Periods)),
+		Subjects: make([]SubjectUsageResponseDTO, len(report.Subjects)),
+	}
+
+	for i, period := range counter.Periods {
+		res.Periods[i] = PeriodResponseDTO{Period: string(period), ResetAt: period.End(report.At)}
+		if limit, ok := report.Limits[period]; ok {
+			res.Periods[i].Limit = &limit
+		}
+	}
+
+	for i, subject := range report.Subjects {
+		used := make(map[string]int64, len(subject.Usage))
+		for period, count := range subject.Usage {
+			used[string(period)] = count
+		}
+
+		res.Subjects[i] = SubjectUsageResponseDTO{Subject: subject.Subject, Name: subject.Name, Used: used}
+	}
+
+	return res
+}
+
+// PlanRequestDTO represents the request payload for changing a user's plan.
+type PlanRequestDTO struct {
+	Plan string `json:"plan" binding:"required"`
+}
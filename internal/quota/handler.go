@@ -0,0 +1,79 @@
+package quota
+
+import (
+	"net/http"
+
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+// GetUsage godoc
+// @Summary Get quota usage
+// @Description Get the plan of the current user, its quotas and the requests counted this day and month for the user and each of their API keys
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} UsageResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/me/usage [get]
+func (h *Handler) GetUsage(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	report, err := h.service.GetUsage(c.Request.Context(), metadata.UserID)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, NewUsageResponseDTO(report))
+}
+
+// SetPlan godoc
+// @Summary Set plan
+// @Description Change the plan (free, partner or unlimited) whose quotas a user and their API keys are held to. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Param request body PlanRequestDTO true "Plan"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users/{id}/plan [put]
+func (h *Handler) SetPlan(c *gin.Context) {
+	var req PlanRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	plan, err := ParsePlan(req.Plan)
+	if err != nil {
+		utils.ResponseErrorWithStatus(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.SetPlan(c.Request.Context(), c.Param("id"), plan); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
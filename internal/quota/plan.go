@@ -0,0 +1,50 @@
+package quota
+
+import (
+	"fmt"
+	"strings"
+
+	counter "github.com/chai-rs/simple-bookstore/infrastructure/quota"
+)
+
+// Plan is a tier of request quotas a user and their API keys are held to.
+type Plan string
+
+func (p Plan) String() string {
+	return string(p)
+}
+
+const (
+	Free      = Plan("free")
+	Partner   = Plan("partner")
+	Unlimited = Plan("unlimited")
+)
+
+// DefaultPlan is the plan of every new user.
+const DefaultPlan = Free
+
+// Limits is the most requests allowed in each period. Periods without a limit are unlimited.
+type Limits map[counter.Period]int64
+
+// Plans lists the plans and their quotas.
+var Plans = map[Plan]Limits{
+	Free: {
+		counter.Day:   1_000,
+		counter.Month: 10_000,
+	},
+	Partner: {
+		counter.Day:   100_000,
+		counter.Month: 1_000_000,
+	},
+	Unlimited: {},
+}
+
+// ParsePlan parses a plan name, rejecting unknown plans.
+func ParsePlan(raw string) (Plan, error) {
+	plan := Plan(strings.ToLower(strings.TrimSpace(raw)))
+	if _, ok := Plans[plan]; !ok {
+		return "", fmt.Errorf("unknown plan %q", raw)
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,96 @@
+package quota
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository represents the quota repository interface.
+type Repository interface {
+	SaveUsage(ctx context.Context, usage []model.QuotaUsage) error
+	GetPlan(ctx context.Context, userID string) (Plan, error)
+	SetPlan(ctx context.Context, userID string, plan Plan) error
+	ListActiveKeys(ctx context.Context, userID string) ([]model.APIKey, error)
+	GetClientOwner(ctx context.Context, clientID string) (string, error)
+}
+
+// repository implements the Repository interface.
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+// SaveUsage stores request counts. Counts only ever grow within a period, so the highest one wins
+// when instances flush concurrently.
+func (r *repository) SaveUsage(ctx context.Context, usage []model.QuotaUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "subject"}, {Name: "period"}, {Name: "period_start"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "count"}, Value: gorm.Expr("GREATEST(quota_usage.count, excluded.count)")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+		},
+	}).Create(&usage).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetPlan(ctx context.Context, userID string) (Plan, error) {
+	var user model.User
+	if err := r.db.WithContext(ctx).Select("plan").Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", errs.FromGorm(err)
+	}
+
+	return Plan(user.Plan), nil
+}
+
+func (r *repository) SetPlan(ctx context.Context, userID string, plan Plan) error {
+	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("plan", plan.String())
+	if result.Error != nil {
+		return errs.FromGorm(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.New(http.StatusNotFound, gorm.ErrRecordNotFound, "user not found")
+	}
+
+	return nil
+}
+
+// ListActiveKeys returns the user's API keys that are neither revoked nor expired.
+func (r *repository) ListActiveKeys(ctx context.Context, userID string) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.WithContext(ctx).Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Order("created_at").
+		Find(&keys).Error
+	if err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return keys, nil
+}
+
+// GetClientOwner returns the ID of the user who registered the OAuth2 client.
+func (r *repository) GetClientOwner(ctx context.Context, clientID string) (string, error) {
+	var client model.OAuthClient
+	if err := r.db.WithContext(ctx).Select("owner_id").Where("id = ?", clientID).First(&client).Error; err != nil {
+		return "", errs.FromGorm(err)
+	}
+
+	return client.OwnerID.String(), nil
+}
@@ -0,0 +1,301 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	counter "github.com/chai-rs/simple-bookstore/infrastructure/quota"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// FlushInterval is how often request counts are copied from the counter to the database.
+	FlushInterval = time.Minute
+	// PlanCacheTTL is how long a user's plan, and the owner of an OAuth2 client, are cached, so a plan
+	// change takes up to this long to apply everywhere.
+	PlanCacheTTL = time.Minute
+)
+
+// ExceededError is returned when a request is refused because a quota is used up.
+type ExceededError struct {
+	Period  counter.Period
+	Limit   int64
+	ResetAt time.Time
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %d requests exceeded, resets at %s", e.Period, e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+// UserSubject is what the requests of a user are counted under, whether made with a session, an API key
+// or through one of their OAuth2 clients. The plan's quotas apply to it, so minting keys does not raise them.
+func UserSubject(userID string) string {
+	return "user:" + userID
+}
+
+// KeySubject is what the requests made with an API key are also counted under, to break the usage of its owner down.
+func KeySubject(keyID string) string {
+	return "key:" + keyID
+}
+
+// SubjectUsage is the request count of one subject of a user in the current periods.
+type SubjectUsage struct {
+	Subject string
+	// Name is the API key name, empty for the user themselves.
+	Name  string
+	Usage counter.Usage
+}
+
+// Report is the quota usage of a user and each of their API keys. The first subject is the user,
+// counting every request against the plan; the others are the share of each API key.
+type Report struct {
+	Plan     Plan
+	Limits   Limits
+	Subjects []SubjectUsage
+	At       time.Time
+}
+
+// Service represents the quota service interface.
+type Service interface {
+	Consume(ctx context.Context, properties *auth.AccessProperties) error
+	GetUsage(ctx context.Context, userID string) (*Report, error)
+	SetPlan(ctx context.Context, userID string, plan Plan) error
+	Flush(ctx context.Context) error
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// ServiceOpts contains optional collaborators for the quota service.
+type ServiceOpts struct {
+	Counter counter.Counter
+}
+
+// withDefaults fills unset collaborators with in-memory implementations.
+func (o ServiceOpts) withDefaults() ServiceOpts {
+	if o.Counter == nil {
+		o.Counter = counter.NewMemoryCounter()
+	}
+
+	return o
+}
+
+// service implements the Service interface
+type service struct {
+	repo    Repository
+	counter counter.Counter
+	plans   sync.Map
+	owners  sync.Map
+}
+
+// cached is a value held in the plan or owner cache until expiresAt.
+type cached[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func NewService(repo Repository, opts ...*ServiceOpts) *service {
+	option := ServiceOpts{}
+	if len(opts) > 0 {
+		option = *opts[0]
+	}
+	option = option.withDefaults()
+
+	return &service{repo: repo, counter: option.Counter}
+}
+
+// Consume counts a request of the caller against the quotas of the user they act for, refusing it
+// when one is used up. Requests are let through when the plan or counter is unavailable.
+func (s *service) Consume(ctx context.Context, properties *auth.AccessProperties) error {
+	userID, err := s.ownerOf(ctx, properties)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("client_id", properties.ClientID).Msg("🚨 failed to get owner of oauth client")
+		return nil
+	}
+
+	plan, err := s.planOf(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to get plan")
+		return nil
+	}
+
+	now := time.Now()
+	subject := UserSubject(userID)
+	usage, err := s.counter.Increment(ctx, subject, now)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("subject", subject).Msg("🚨 failed to count request")
+		return nil
+	}
+
+	// Check the month first, its reset is the later one.
+	limits := Plans[plan]
+	for _, period := range []counter.Period{counter.Month, counter.Day} {
+		limit, ok := limits[period]
+		if !ok || usage[period] <= limit {
+			continue
+		}
+
+		// Refused requests don't count against the quota.
+		if err := s.counter.Decrement(ctx, subject, now); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("subject", subject).Msg("⚠️ failed to uncount refused request")
+		}
+
+		exceeded := &ExceededError{Period: period, Limit: limit, ResetAt: period.End(now)}
+		return errs.New(http.StatusTooManyRequests, exceeded, exceeded.Error())
+	}
+
+	if properties.APIKeyID != "" {
+		if _, err := s.counter.Increment(ctx, KeySubject(properties.APIKeyID), now); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("api_key_id", properties.APIKeyID).Msg("⚠️ failed to count api key request")
+		}
+	}
+
+	return nil
+}
+
+// GetUsage returns the plan of the user and the usage of the user and each of their active API keys.
+func (s *service) GetUsage(ctx context.Context, userID string) (*Report, error) {
+	plan, err := s.planOf(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get plan")
+		return nil, err
+	}
+
+	keys, err := s.repo.ListActiveKeys(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list api keys")
+		return nil, err
+	}
+
+	report := &Report{Plan: plan, Limits: Plans[plan], At: time.Now()}
+	subjects := []SubjectUsage{{Subject: UserSubject(userID)}}
+	for _, key := range keys {
+		subjects = append(subjects, SubjectUsage{Subject: KeySubject(key.ID.String()), Name: key.Name})
+	}
+
+	for _, subject := range subjects {
+		subject.Usage, err = s.counter.Get(ctx, subject.Subject, report.At)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get usage")
+			return nil, err
+		}
+
+		report.Subjects = append(report.Subjects, subject)
+	}
+
+	return report, nil
+}
+
+func (s *service) SetPlan(ctx context.Context, userID string, plan Plan) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		return errs.New(http.StatusNotFound, err, "user not found")
+	}
+
+	if err := s.repo.SetPlan(ctx, userID, plan); err != nil {
+		if !errs.IsNotFound(err) {
+			log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to set plan")
+		}
+		return err
+	}
+
+	s.plans.Delete(userID)
+	log.Ctx(ctx).Info().Str("user_id", userID).Str("plan", plan.String()).Msg("🔐 changed plan")
+	return nil
+}
+
+// Flush copies the counts changed since the last flush to the database.
+func (s *service) Flush(ctx context.Context) error {
+	counts, err := s.counter.Drain(ctx)
+	if len(counts) > 0 {
+		now := time.Now()
+		usage := make([]model.QuotaUsage, len(counts))
+		for i, count := range counts {
+			usage[i] = model.QuotaUsage{
+				Subject:     count.Subject,
+				Period:      string(count.Period),
+				PeriodStart: count.PeriodStart,
+				Count:       count.Count,
+				UpdatedAt:   &now,
+			}
+		}
+
+		if saveErr := s.repo.SaveUsage(ctx, usage); saveErr != nil {
+			err = errors.Join(err, saveErr)
+		}
+	}
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int("counts", len(counts)).Msg("🚨 failed to flush quota usage")
+		return err
+	}
+
+	return nil
+}
+
+// Run flushes the counts every interval until ctx is done.
+func (s *service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Flush(ctx)
+		}
+	}
+}
+
+// ownerOf returns the ID of the user the caller acts for. Clients using the client credentials grant
+// act for themselves, under the "client:<id>" subject, so their requests count against their owner,
+// cached for PlanCacheTTL.
+func (s *service) ownerOf(ctx context.Context, properties *auth.AccessProperties) (string, error) {
+	if properties.ClientID == "" || properties.UserID != auth.ClientSubject(properties.ClientID) {
+		return properties.UserID, nil
+	}
+
+	if value, ok := s.owners.Load(properties.ClientID); ok {
+		owner := value.(cached[string])
+		if time.Now().Before(owner.expiresAt) {
+			return owner.value, nil
+		}
+	}
+
+	ownerID, err := s.repo.GetClientOwner(ctx, properties.ClientID)
+	if err != nil {
+		return "", err
+	}
+
+	s.owners.Store(properties.ClientID, cached[string]{value: ownerID, expiresAt: time.Now().Add(PlanCacheTTL)})
+	return ownerID, nil
+}
+
+// planOf returns the plan of the user, caching it for PlanCacheTTL.
+func (s *service) planOf(ctx context.Context, userID string) (Plan, error) {
+	if value, ok := s.plans.Load(userID); ok {
+		plan := value.(cached[Plan])
+		if time.Now().Before(plan.expiresAt) {
+			return plan.value, nil
+		}
+	}
+
+	plan, err := s.repo.GetPlan(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	// Plans removed from the code fall back to the default.
+	if _, ok := Plans[plan]; !ok {
+		plan = DefaultPlan
+	}
+
+	s.plans.Store(userID, cached[Plan]{value: plan, expiresAt: time.Now().Add(PlanCacheTTL)})
+	return plan, nil
+}
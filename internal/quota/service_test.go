@@ -0,0 +1,194 @@
+package quota_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	counter "github.com/chai-rs/simple-bookstore/infrastructure/quota"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/chai-rs/simple-bookstore/internal/quota"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+const userID = "123e4567-e89b-12d3-a456-426614174000"
+
+func TestService_Consume(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		Plan       quota.Plan
+		Used       int
+		WantPeriod counter.Period
+	}
+
+	testcases := []Testcase{
+		{
+			Name: "within-quota",
+			Plan: quota.Free,
+		},
+		{
+			Name:       "daily-quota-used-up",
+			Plan:       quota.Free,
+			Used:       int(quota.Plans[quota.Free][counter.Day]),
+			WantPeriod: counter.Day,
+		},
+		{
+			Name: "unlimited",
+			Plan: quota.Unlimited,
+			Used: int(quota.Plans[quota.Free][counter.Month]),
+		},
+		{
+			Name:       "unknown-plan-falls-back-to-default",
+			Plan:       "retired",
+			Used:       int(quota.Plans[quota.DefaultPlan][counter.Day]),
+			WantPeriod: counter.Day,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := context.Background()
+			properties := &auth.AccessProperties{UserID: userID}
+
+			repo := quota.NewMockRepository(t)
+			repo.EXPECT().GetPlan(mock.Anything, userID).Return(tc.Plan, nil).Once()
+
+			memoryCounter := counter.NewMemoryCounter()
+			svc := quota.NewService(repo, &quota.ServiceOpts{Counter: memoryCounter})
+			for range tc.Used {
+				assert.NoError(t, svc.Consume(ctx, properties))
+			}
+
+			err := svc.Consume(ctx, properties)
+			if tc.WantPeriod == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.Equal(t, http.StatusTooManyRequests, errs.StatusOf(err))
+
+			var exceeded *quota.ExceededError
+			if assert.ErrorAs(t, err, &exceeded) {
+				assert.Equal(t, tc.WantPeriod, exceeded.Period)
+				assert.True(t, exceeded.ResetAt.After(time.Now()))
+			}
+
+			usage, err := memoryCounter.Get(ctx, quota.UserSubject(userID), exceeded.ResetAt.Add(-1))
+			assert.NoError(t, err)
+			assert.Equal(t, int64(tc.Used), usage[counter.Day], "refused requests must not be counted")
+		})
+	}
+}
+
+func TestService_Consume_APIKey(t *testing.T) {
+	ctx := context.Background()
+	keyID := uuid.NewString()
+
+	repo := quota.NewMockRepository(t)
+	repo.EXPECT().GetPlan(mock.Anything, userID).Return(quota.Partner, nil).Once()
+	repo.EXPECT().ListActiveKeys(mock.Anything, userID).Return([]model.APIKey{{ID: uuid.MustParse(keyID), Name: "ci"}}, nil).Once()
+
+	svc := quota.NewService(repo)
+	assert.NoError(t, svc.Consume(ctx, &auth.AccessProperties{UserID: userID, APIKeyID: keyID}))
+	assert.NoError(t, svc.Consume(ctx, &auth.AccessProperties{UserID: userID, APIKeyID: keyID}))
+	assert.NoError(t, svc.Consume(ctx, &auth.AccessProperties{UserID: userID}))
+
+	report, err := svc.GetUsage(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, quota.Partner, report.Plan)
+	if assert.Len(t, report.Subjects, 2) {
+		assert.Equal(t, "user:"+userID, report.Subjects[0].Subject)
+		assert.Equal(t, int64(3), report.Subjects[0].Usage[counter.Month], "key requests count against the user")
+		assert.Equal(t, "key:"+keyID, report.Subjects[1].Subject)
+		assert.Equal(t, "ci", report.Subjects[1].Name)
+		assert.Equal(t, int64(2), report.Subjects[1].Usage[counter.Month])
+	}
+}
+
+func TestService_Consume_KeysShareQuota(t *testing.T) {
+	ctx := context.Background()
+
+	repo := quota.NewMockRepository(t)
+	repo.EXPECT().GetPlan(mock.Anything, userID).Return(quota.Free, nil).Once()
+
+	svc := quota.NewService(repo)
+	for range quota.Plans[quota.Free][counter.Day] {
+		assert.NoError(t, svc.Consume(ctx, &auth.AccessProperties{UserID: userID, APIKeyID: uuid.NewString()}))
+	}
+
+	// A fresh key does not come with a fresh quota.
+	err := svc.Consume(ctx, &auth.AccessProperties{UserID: userID, APIKeyID: uuid.NewString()})
+	assert.Equal(t, http.StatusTooManyRequests, errs.StatusOf(err))
+}
+
+func TestService_Consume_ClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	clientID := uuid.NewString()
+	properties := &auth.AccessProperties{UserID: auth.ClientSubject(clientID), ClientID: clientID}
+
+	repo := quota.NewMockRepository(t)
+	repo.EXPECT().GetClientOwner(mock.Anything, clientID).Return(userID, nil).Once()
+	repo.EXPECT().GetPlan(mock.Anything, userID).Return(quota.Partner, nil).Once()
+	repo.EXPECT().ListActiveKeys(mock.Anything, userID).Return(nil, nil).Once()
+
+	svc := quota.NewService(repo)
+	assert.NoError(t, svc.Consume(ctx, properties))
+	assert.NoError(t, svc.Consume(ctx, properties))
+
+	report, err := svc.GetUsage(ctx, userID)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Subjects, 1) {
+		assert.Equal(t, int64(2), report.Subjects[0].Usage[counter.Month], "client requests count against its owner")
+	}
+}
+
+func TestService_Flush(t *testing.T) {
+	ctx := context.Background()
+
+	repo := quota.NewMockRepository(t)
+	repo.EXPECT().GetPlan(mock.Anything, userID).Return(quota.Free, nil).Once()
+	repo.EXPECT().SaveUsage(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, usage []model.QuotaUsage) error {
+		assert.Len(t, usage, len(counter.Periods))
+		for _, u := range usage {
+			assert.Equal(t, "user:"+userID, u.Subject)
+			assert.Equal(t, int64(2), u.Count)
+		}
+		return nil
+	}).Once()
+
+	svc := quota.NewService(repo)
+	assert.NoError(t, svc.Consume(ctx, &auth.AccessProperties{UserID: userID}))
+	assert.NoError(t, svc.Consume(ctx, &auth.AccessProperties{UserID: userID}))
+	assert.NoError(t, svc.Flush(ctx))
+	assert.NoError(t, svc.Flush(ctx), "nothing changed since the last flush")
+}
+
+func TestService_SetPlan(t *testing.T) {
+	ctx := context.Background()
+
+	repo := quota.NewMockRepository(t)
+	repo.EXPECT().GetPlan(mock.Anything, userID).Return(quota.Free, nil).Once()
+	repo.EXPECT().GetPlan(mock.Anything, userID).Return(quota.Partner, nil).Once()
+	repo.EXPECT().SetPlan(mock.Anything, userID, quota.Partner).Return(nil).Once()
+	repo.EXPECT().SetPlan(mock.Anything, mock.Anything, mock.Anything).Return(errs.FromGorm(gorm.ErrRecordNotFound)).Once()
+	repo.EXPECT().ListActiveKeys(mock.Anything, userID).Return(nil, nil)
+
+	svc := quota.NewService(repo)
+	report, err := svc.GetUsage(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, quota.Free, report.Plan)
+
+	assert.NoError(t, svc.SetPlan(ctx, userID, quota.Partner))
+	report, err = svc.GetUsage(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, quota.Partner, report.Plan, "the cached plan must be dropped")
+
+	assert.Equal(t, http.StatusNotFound, errs.StatusOf(svc.SetPlan(ctx, uuid.NewString(), quota.Partner)))
+	assert.Equal(t, http.StatusNotFound, errs.StatusOf(svc.SetPlan(ctx, "not-a-uuid", quota.Partner)))
+}
@@ -0,0 +1,27 @@
+package settings
+
+import (
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/config"
+)
+
+// ReloadResponseDTO represents the outcome of a configuration reload.
+type ReloadResponseDTO struct {
+	Trigger         string    `json:"trigger"`
+	At              time.Time `json:"at"`
+	Applied         []string  `json:"applied"`
+	RequiresRestart []string  `json:"requires_restart"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// NewReloadResponseDTO converts a config.ReloadResult to a ReloadResponseDTO.
+func NewReloadResponseDTO(result *config.ReloadResult) ReloadResponseDTO {
+	return ReloadResponseDTO{
+		Trigger:         string(result.Trigger),
+		At:              result.At,
+		Applied:         result.Applied,
+		RequiresRestart: result.RequiresRestart,
+		Error:           result.Error,
+	}
+}
@@ -0,0 +1,57 @@
+package settings
+
+import (
+	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Reloader reloads the running configuration and remembers the outcomes.
+type Reloader interface {
+	Reload(trigger config.ReloadTrigger) config.ReloadResult
+	History() []config.ReloadResult
+}
+
+type Handler struct {
+	reloader Reloader
+}
+
+func NewHandler(reloader Reloader) *Handler {
+	return &Handler{reloader}
+}
+
+// ListReloads godoc
+// @Summary List configuration reloads
+// @Description List the outcomes of the most recent configuration reloads, newest first, whether triggered by a config file change, SIGHUP or an admin. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} ReloadResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/config/reloads [get]
+func (h *Handler) ListReloads(c *gin.Context) {
+	history := h.reloader.History()
+
+	res := make([]ReloadResponseDTO, len(history))
+	for i := range history {
+		res[i] = NewReloadResponseDTO(&history[i])
+	}
+
+	utils.ResponseOk(c, res)
+}
+
+// Reload godoc
+// @Summary Reload configuration
+// @Description Reload the config file and environment. Changed log level, rate limits, CORS lists, feature flags and secrets are applied at once, other changed settings are reported as requiring a restart. An invalid configuration is rejected as a whole and reported in the error field. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} ReloadResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/config/reload [post]
+func (h *Handler) Reload(c *gin.Context) {
+	result := h.reloader.Reload(config.AdminTrigger)
+	utils.ResponseOk(c, NewReloadResponseDTO(&result))
+}
@@ -1,54 +1,286 @@
 package user
 
 import (
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
 	"github.com/chai-rs/simple-bookstore/internal/model"
-	"github.com/chai-rs/simple-bookstore/pkg/crypto"
 )
 
 // LoginRequestDTO represents the request payload for user login.
 type LoginRequestDTO struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Password string `json:"password" binding:"required" sensitive:"true"`
 }
 
 // LoginResponseDTO represents the response payload after successful login.
+// When two-factor authentication is enabled, only MFARequired and ChallengeToken are set.
 type LoginResponseDTO struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken    string `json:"access_token,omitempty" sensitive:"true"`
+	RefreshToken   string `json:"refresh_token,omitempty" sensitive:"true"`
+	MFARequired    bool   `json:"mfa_required,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty" sensitive:"true"`
 }
 
-// RegisterRequestDTO represents the request payload for user registration.
-type RegisterRequestDTO struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+// LoginTOTPRequestDTO represents the request payload for completing a two-factor login.
+type LoginTOTPRequestDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" sensitive:"true"`
+	Code           string `json:"code" binding:"required" sensitive:"true"`
 }
 
-// ToUser converts RegisterRequestDTO to a User model with hashed password.
-func (r *RegisterRequestDTO) ToUser() (*model.User, error) {
-	hashedPassword, err := crypto.HashPassword(r.Password)
-	if err != nil {
-		return nil, err
-	}
+// OIDCCallbackRequestDTO represents the query parameters an OpenID Connect provider redirects back with.
+type OIDCCallbackRequestDTO struct {
+	Code             string `form:"code" sensitive:"true"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
 
-	return &model.User{
-		Email:          r.Email,
-		HashedPassword: hashedPassword,
-	}, nil
+// RegisterRequestDTO represents the request payload for user registration.
+type RegisterRequestDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required" sensitive:"true"`
 }
 
 // RegisterResponseDTO represents the response payload after successful registration.
 type RegisterResponseDTO struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string `json:"access_token" sensitive:"true"`
+	RefreshToken string `json:"refresh_token" sensitive:"true"`
 }
 
 // RefreshTokenRequestDTO represents the request payload for refreshing access token.
 type RefreshTokenRequestDTO struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string `json:"refresh_token" binding:"required" sensitive:"true"`
 }
 
 // RefreshTokenResponseDTO represents the response payload after successful token refresh.
 type RefreshTokenResponseDTO struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string `json:"access_token" sensitive:"true"`
+	RefreshToken string `json:"refresh_token" sensitive:"true"`
+}
+
+// EnrollTOTPResponseDTO represents the response payload after starting two-factor enrolment.
+type EnrollTOTPResponseDTO struct {
+	Secret string `json:"secret" sensitive:"true"`
+	URI    string `json:"uri" sensitive:"true"`
+}
+
+// TOTPCodeRequestDTO represents a request payload carrying a TOTP or recovery code.
+type TOTPCodeRequestDTO struct {
+	Code string `json:"code" binding:"required" sensitive:"true"`
+}
+
+// ActivateTOTPResponseDTO represents the response payload after activating two-factor authentication.
+type ActivateTOTPResponseDTO struct {
+	RecoveryCodes []string `json:"recovery_codes" sensitive:"true"`
+}
+
+// ForgotPasswordRequestDTO represents the request payload for requesting a password reset email.
+type ForgotPasswordRequestDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequestDTO represents the request payload for resetting a password.
+type ResetPasswordRequestDTO struct {
+	Token    string `json:"token" binding:"required" sensitive:"true"`
+	Password string `json:"password" binding:"required" sensitive:"true"`
+}
+
+// VerifyEmailRequestDTO represents the request payload for verifying an email address.
+type VerifyEmailRequestDTO struct {
+	Token string `json:"token" binding:"required" sensitive:"true"`
+}
+
+// SendEmailVerificationRequestDTO represents the request payload for resending a verification email.
+type SendEmailVerificationRequestDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// RoleRequestDTO represents the request payload for granting a role.
+type RoleRequestDTO struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// RolesResponseDTO represents the roles assigned to a user.
+type RolesResponseDTO struct {
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
+
+// NewRolesResponseDTO converts a user's roles to RolesResponseDTO.
+func NewRolesResponseDTO(userID string, roles []auth.Role) RolesResponseDTO {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.String()
+	}
+
+	return RolesResponseDTO{UserID: userID, Roles: names}
+}
+
+// ProfileResponseDTO represents the profile of the current user.
+// PendingEmail is set while a change of email awaits confirmation.
+type ProfileResponseDTO struct {
+	ID            string         `json:"id"`
+	Email         string         `json:"email"`
+	EmailVerified bool           `json:"email_verified"`
+	PendingEmail  string         `json:"pending_email,omitempty"`
+	DisplayName   string         `json:"display_name"`
+	AvatarURL     string         `json:"avatar_url"`
+	Locale        string         `json:"locale"`
+	Preferences   map[string]any `json:"preferences"`
+	TOTPEnabled   bool           `json:"totp_enabled"`
+	CreatedAt     *time.Time     `json:"created_at,omitempty"`
+}
+
+// NewProfileResponseDTO converts a User model to ProfileResponseDTO.
+func NewProfileResponseDTO(user *model.User) ProfileResponseDTO {
+	preferences := user.Preferences
+	if preferences == nil {
+		preferences = map[string]any{}
+	}
+
+	return ProfileResponseDTO{
+		ID:            user.ID.String(),
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		PendingEmail:  user.PendingEmail,
+		DisplayName:   user.DisplayName,
+		AvatarURL:     user.AvatarURL,
+		Locale:        user.Locale,
+		Preferences:   preferences,
+		TOTPEnabled:   user.TOTPEnabled,
+		CreatedAt:     user.CreatedAt,
+	}
+}
+
+// UpdateProfileRequestDTO represents the request payload for updating the current user's profile.
+// Omitted fields are left unchanged; preferences, when given, replace all stored preferences.
+type UpdateProfileRequestDTO struct {
+	DisplayName *string        `json:"display_name"`
+	AvatarURL   *string        `json:"avatar_url"`
+	Locale      *string        `json:"locale"`
+	Preferences map[string]any `json:"preferences"`
+}
+
+// ToProfileUpdate converts UpdateProfileRequestDTO to a ProfileUpdate.
+func (r *UpdateProfileRequestDTO) ToProfileUpdate() *ProfileUpdate {
+	return &ProfileUpdate{
+		DisplayName: r.DisplayName,
+		AvatarURL:   r.AvatarURL,
+		Locale:      r.Locale,
+		Preferences: r.Preferences,
+	}
+}
+
+// ChangePasswordRequestDTO represents the request payload for changing the password.
+// CurrentPassword may be omitted by users who only sign in with a social login.
+type ChangePasswordRequestDTO struct {
+	CurrentPassword string `json:"current_password" sensitive:"true"`
+	NewPassword     string `json:"new_password" binding:"required" sensitive:"true"`
+}
+
+// ChangeEmailRequestDTO represents the request payload for changing the email address.
+type ChangeEmailRequestDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" sensitive:"true"`
+}
+
+// ConfirmEmailChangeRequestDTO represents the request payload for confirming a new email address.
+type ConfirmEmailChangeRequestDTO struct {
+	Token string `json:"token" binding:"required" sensitive:"true"`
+}
+
+// DeleteAccountRequestDTO represents the request payload for deleting the current user's account.
+type DeleteAccountRequestDTO struct {
+	Password string `json:"password" sensitive:"true"`
+}
+
+// ListUsersRequestDTO represents the query parameters for searching users.
+type ListUsersRequestDTO struct {
+	Query  string `form:"q"`
+	Status string `form:"status"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+// ToUserFilter converts ListUsersRequestDTO to a UserFilter.
+func (r *ListUsersRequestDTO) ToUserFilter() *UserFilter {
+	return &UserFilter{
+		Query:  r.Query,
+		Status: model.UserStatus(r.Status),
+		Limit:  r.Limit,
+		Offset: r.Offset,
+	}
+}
+
+// UserResponseDTO represents a user as seen by administrators.
+type UserResponseDTO struct {
+	ID                    string     `json:"id"`
+	Email                 string     `json:"email"`
+	DisplayName           string     `json:"display_name"`
+	Status                string     `json:"status"`
+	Plan                  string     `json:"plan"`
+	EmailVerified         bool       `json:"email_verified"`
+	TOTPEnabled           bool       `json:"totp_enabled"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             *time.Time `json:"created_at,omitempty"`
+}
+
+// NewUserResponseDTO converts a User model to UserResponseDTO.
+func NewUserResponseDTO(user *model.User) UserResponseDTO {
+	return UserResponseDTO{
+		ID:                    user.ID.String(),
+		Email:                 user.Email,
+		DisplayName:           user.DisplayName,
+		Status:                string(user.Status),
+		Plan:                  user.Plan,
+		EmailVerified:         user.EmailVerifiedAt != nil,
+		TOTPEnabled:           user.TOTPEnabled,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt,
+	}
+}
+
+// UserListResponseDTO represents a page of users and how many match the search in total.
+type UserListResponseDTO struct {
+	Users  []UserResponseDTO `json:"users"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// NewUserListResponseDTO converts a page of User models to UserListResponseDTO.
+func NewUserListResponseDTO(users []model.User, total int64, filter *UserFilter) UserListResponseDTO {
+	res := UserListResponseDTO{
+		Users:  make([]UserResponseDTO, len(users)),
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	for i := range users {
+		res.Users[i] = NewUserResponseDTO(&users[i])
+	}
+
+	return res
+}
+
+// UserDetailsResponseDTO represents a user with their profile and roles, as seen by administrators.
+type UserDetailsResponseDTO struct {
+	UserResponseDTO
+	PendingEmail string   `json:"pending_email,omitempty"`
+	AvatarURL    string   `json:"avatar_url"`
+	Locale       string   `json:"locale"`
+	Roles        []string `json:"roles"`
+}
+
+// NewUserDetailsResponseDTO converts a User model and their roles to UserDetailsResponseDTO.
+func NewUserDetailsResponseDTO(user *model.User, roles []auth.Role) UserDetailsResponseDTO {
+	return UserDetailsResponseDTO{
+		UserResponseDTO: NewUserResponseDTO(user),
+		PendingEmail:    user.PendingEmail,
+		AvatarURL:       user.AvatarURL,
+		Locale:          user.Locale,
+		Roles:           NewRolesResponseDTO(user.ID.String(), roles).Roles,
+	}
 }
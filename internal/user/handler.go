@@ -1,6 +1,11 @@
 package user
 
 import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
 	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
 	"github.com/chai-rs/simple-bookstore/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -23,6 +28,9 @@ func NewHandler(service Service) *Handler {
 // @Param request body LoginRequestDTO true "Login credentials"
 // @Success 200 {object} LoginResponseDTO
 // @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 423 {object} utils.Response
+// @Failure 429 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /users/login [post]
 func (h *Handler) Login(c *gin.Context) {
@@ -32,8 +40,51 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.service.Login(c.Request.Context(), req.Email, req.Password)
+	result, err := h.service.Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
+		setRetryAfter(c, err)
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if result.ChallengeToken != "" {
+		utils.ResponseOk(c, LoginResponseDTO{
+			MFARequired:    true,
+			ChallengeToken: result.ChallengeToken,
+		})
+		return
+	}
+
+	utils.ResponseOk(c, LoginResponseDTO{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+	})
+}
+
+// LoginTOTP godoc
+// @Summary Complete two-factor login
+// @Description Exchange a login challenge token and a TOTP or recovery code for access and refresh tokens
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body LoginTOTPRequestDTO true "Challenge token and code"
+// @Success 200 {object} LoginResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 423 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/login/totp [post]
+func (h *Handler) LoginTOTP(c *gin.Context) {
+	var req LoginTOTPRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.LoginTOTP(c.Request.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		setRetryAfter(c, err)
 		utils.ResponseError(c, err)
 		return
 	}
@@ -44,6 +95,73 @@ func (h *Handler) Login(c *gin.Context) {
 	})
 }
 
+// OIDCLogin godoc
+// @Summary Start social login
+// @Description Redirect to an OpenID Connect provider using the authorization code flow with PKCE
+// @Tags users
+// @Param provider path string true "Provider name"
+// @Success 302
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/oauth/{provider}/login [get]
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	authURL, err := h.service.OIDCAuthURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback godoc
+// @Summary Complete social login
+// @Description Exchange the authorization code returned by an OpenID Connect provider for access and refresh tokens
+// @Tags users
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} LoginResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/oauth/{provider}/callback [get]
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	var req OIDCCallbackRequestDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if req.Error != "" || req.Code == "" {
+		utils.ResponseErrorWithStatus(c, http.StatusUnauthorized, "sign in was cancelled or denied by the provider")
+		return
+	}
+
+	result, err := h.service.OIDCCallback(c.Request.Context(), c.Param("provider"), req.State, req.Code)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if result.ChallengeToken != "" {
+		utils.ResponseOk(c, LoginResponseDTO{
+			MFARequired:    true,
+			ChallengeToken: result.ChallengeToken,
+		})
+		return
+	}
+
+	utils.ResponseOk(c, LoginResponseDTO{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+	})
+}
+
 // Register godoc
 // @Summary Register new user
 // @Description Register a new user and return access and refresh tokens
@@ -53,6 +171,7 @@ func (h *Handler) Login(c *gin.Context) {
 // @Param request body RegisterRequestDTO true "User registration data"
 // @Success 200 {object} RegisterResponseDTO
 // @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /users/register [post]
 func (h *Handler) Register(c *gin.Context) {
@@ -62,13 +181,7 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := req.ToUser()
-	if err != nil {
-		utils.ResponseError(c, err)
-		return
-	}
-
-	accessToken, refreshToken, err := h.service.Register(c.Request.Context(), user)
+	accessToken, refreshToken, err := h.service.Register(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
 		utils.ResponseError(c, err)
 		return
@@ -92,7 +205,7 @@ func (h *Handler) Register(c *gin.Context) {
 // @Failure 500 {object} utils.Response
 // @Router /users/logout [post]
 func (h *Handler) Logout(c *gin.Context) {
-	metadata, err := auth.ExtractTokenMetadata(c.Request)
+	metadata, err := utils.AccessProperties(c.Request.Context())
 	if err != nil {
 		utils.ResponseError(c, err)
 		return
@@ -115,6 +228,8 @@ func (h *Handler) Logout(c *gin.Context) {
 // @Param request body RefreshTokenRequestDTO true "Refresh token"
 // @Success 200 {object} RefreshTokenResponseDTO
 // @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /users/refresh [post]
 func (h *Handler) RefreshToken(c *gin.Context) {
@@ -135,3 +250,640 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 		RefreshToken: refreshToken,
 	})
 }
+
+// EnrollTOTP godoc
+// @Summary Start two-factor enrolment
+// @Description Generate a new TOTP secret and otpauth URI for the current user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} EnrollTOTPResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/totp/enroll [post]
+func (h *Handler) EnrollTOTP(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	secret, uri, err := h.service.EnrollTOTP(c.Request.Context(), metadata.UserID)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, EnrollTOTPResponseDTO{
+		Secret: secret,
+		URI:    uri,
+	})
+}
+
+// ActivateTOTP godoc
+// @Summary Activate two-factor authentication
+// @Description Verify a TOTP code for the enrolled secret and return one-time recovery codes
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body TOTPCodeRequestDTO true "TOTP code"
+// @Success 200 {object} ActivateTOTPResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/totp/activate [post]
+func (h *Handler) ActivateTOTP(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	var req TOTPCodeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	codes, err := h.service.ActivateTOTP(c.Request.Context(), metadata.UserID, req.Code)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, ActivateTOTPResponseDTO{
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTOTP godoc
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication after verifying a TOTP or recovery code
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body TOTPCodeRequestDTO true "TOTP or recovery code"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/totp/disable [post]
+func (h *Handler) DisableTOTP(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	var req TOTPCodeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.DisableTOTP(c.Request.Context(), metadata.UserID, req.Code); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Send a password reset link to the given email if an account exists
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequestDTO true "Account email"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/password/forgot [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using a password reset token
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequestDTO true "Reset token and new password"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/password/reset [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// VerifyEmail godoc
+// @Summary Verify email
+// @Description Confirm ownership of an email address using a verification token
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequestDTO true "Verification token"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/email/verify [post]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// SendEmailVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link to the given email if an unverified account exists
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body SendEmailVerificationRequestDTO true "Account email"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/email/verify/resend [post]
+func (h *Handler) SendEmailVerification(c *gin.Context) {
+	var req SendEmailVerificationRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.SendEmailVerification(c.Request.Context(), req.Email); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// GetRoles godoc
+// @Summary Get user roles
+// @Description Get the roles assigned to a user. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} RolesResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users/{id}/roles [get]
+func (h *Handler) GetRoles(c *gin.Context) {
+	userID := c.Param("id")
+	roles, err := h.service.GetRoles(c.Request.Context(), userID)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, NewRolesResponseDTO(userID, roles))
+}
+
+// GrantRole godoc
+// @Summary Grant role
+// @Description Assign a role (customer, editor or admin) to a user. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Param request body RoleRequestDTO true "Role"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users/{id}/roles [post]
+func (h *Handler) GrantRole(c *gin.Context) {
+	var req RoleRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		utils.ResponseErrorWithStatus(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.GrantRole(c.Request.Context(), c.Param("id"), role); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// RevokeRole godoc
+// @Summary Revoke role
+// @Description Remove a role from a user. Admins cannot revoke their own admin role. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Param role path string true "Role"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users/{id}/roles/{role} [delete]
+func (h *Handler) RevokeRole(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	role, err := auth.ParseRole(c.Param("role"))
+	if err != nil {
+		utils.ResponseErrorWithStatus(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.RevokeRole(c.Request.Context(), metadata.UserID, c.Param("id"), role); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// setRetryAfter adds a Retry-After header when a login was refused because of previous failures.
+func setRetryAfter(c *gin.Context, err error) {
+	var blocked *auth.LoginBlockedError
+	if errors.As(err, &blocked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	}
+}
+
+// GetProfile godoc
+// @Summary Get profile
+// @Description Get the profile of the current user
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} ProfileResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/me [get]
+func (h *Handler) GetProfile(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	user, err := h.service.GetProfile(c.Request.Context(), metadata.UserID)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, NewProfileResponseDTO(user))
+}
+
+// UpdateProfile godoc
+// @Summary Update profile
+// @Description Update the display name, avatar URL, locale or preferences of the current user. Omitted fields are left unchanged.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body UpdateProfileRequestDTO true "Profile fields"
+// @Success 200 {object} ProfileResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/me [patch]
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	user, err := h.service.UpdateProfile(c.Request.Context(), metadata.UserID, req.ToProfileUpdate())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, NewProfileResponseDTO(user))
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the password of the current user. Every other session is signed out.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body ChangePasswordRequestDTO true "Current and new password"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/me/password [post]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.ChangePassword(c.Request.Context(), metadata, req.CurrentPassword, req.NewPassword); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// ChangeEmail godoc
+// @Summary Change email
+// @Description Request a change of email address. A confirmation link is sent to the new address and the change only applies once it is opened.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body ChangeEmailRequestDTO true "New email and current password"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/me/email [post]
+func (h *Handler) ChangeEmail(c *gin.Context) {
+	var req ChangeEmailRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.ChangeEmail(c.Request.Context(), metadata.UserID, req.Password, req.Email); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm email change
+// @Description Apply a requested change of email address using the token sent to the new address
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body ConfirmEmailChangeRequestDTO true "Email change token"
+// @Success 200 {object} nil
+// @Failure 400 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/email/change/confirm [post]
+func (h *Handler) ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailChangeRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.ConfirmEmailChange(c.Request.Context(), req.Token); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// DeleteAccount godoc
+// @Summary Delete account
+// @Description Permanently delete the current user with their personal data, roles, API keys and sessions
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body DeleteAccountRequestDTO true "Current password"
+// @Success 200 {object} nil
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /users/me [delete]
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	var req DeleteAccountRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.DeleteAccount(c.Request.Context(), metadata, req.Password); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// ListUsers godoc
+// @Summary List users
+// @Description Search users by part of their email or display name, optionally filtered by status. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param q query string false "Part of the email or display name"
+// @Param status query string false "Account status, active or disabled"
+// @Param limit query int false "Maximum number of users (default 20, max 100)"
+// @Param offset query int false "Number of users to skip"
+// @Success 200 {object} UserListResponseDTO
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users [get]
+func (h *Handler) ListUsers(c *gin.Context) {
+	var req ListUsersRequestDTO
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	filter := req.ToUserFilter()
+	users, total, err := h.service.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, NewUserListResponseDTO(users, total, filter))
+}
+
+// GetUser godoc
+// @Summary Get user
+// @Description Get a user with their profile, status and roles. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} UserDetailsResponseDTO
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users/{id} [get]
+func (h *Handler) GetUser(c *gin.Context) {
+	user, err := h.service.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	roles, err := h.service.GetRoles(c.Request.Context(), user.ID.String())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, NewUserDetailsResponseDTO(user, roles))
+}
+
+// DisableUser godoc
+// @Summary Disable user
+// @Description Block a user from signing in and sign out all of their sessions. Admins cannot disable themselves. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} nil
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users/{id}/disable [post]
+func (h *Handler) DisableUser(c *gin.Context) {
+	metadata, err := utils.AccessProperties(c.Request.Context())
+	if err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	if err := h.service.DisableUser(c.Request.Context(), metadata.UserID, c.Param("id")); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// EnableUser godoc
+// @Summary Enable user
+// @Description Allow a disabled user to sign in again. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} nil
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users/{id}/enable [post]
+func (h *Handler) EnableUser(c *gin.Context) {
+	if err := h.service.EnableUser(c.Request.Context(), c.Param("id")); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
+
+// ForcePasswordReset godoc
+// @Summary Force password reset
+// @Description Sign out all sessions of a user and refuse password logins until they reset their password with the link emailed to them. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} nil
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/users/{id}/password/reset [post]
+func (h *Handler) ForcePasswordReset(c *gin.Context) {
+	if err := h.service.ForcePasswordReset(c.Request.Context(), c.Param("id")); err != nil {
+		utils.ResponseError(c, err)
+		return
+	}
+
+	utils.ResponseOk(c, nil)
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/mailer"
+)
+
+// passwordResetMessage builds the email carrying a password reset link.
+func passwordResetMessage(appURL, email, token string) *mailer.Message {
+	link := fmt.Sprintf("%s/reset-password?token=%s", appURL, url.QueryEscape(token))
+	return &mailer.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("We received a request to reset your password.\n\n"+
+			"Open the link below within %s to choose a new one:\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n", PasswordResetTTL, link),
+	}
+}
+
+// forcedPasswordResetMessage builds the email sent when an administrator requires a new password.
+func forcedPasswordResetMessage(appURL, email, token string) *mailer.Message {
+	link := fmt.Sprintf("%s/reset-password?token=%s", appURL, url.QueryEscape(token))
+	return &mailer.Message{
+		To:      email,
+		Subject: "Choose a new password",
+		Body: fmt.Sprintf("An administrator has required you to choose a new password, "+
+			"you have been signed out and cannot sign in with your old one.\n\n"+
+			"Open the link below within %s to choose a new one:\n%s\n\n"+
+			"If the link has expired, request a new one from the forgot password page.\n", ForcedPasswordResetTTL, link),
+	}
+}
+
+// emailVerificationMessage builds the email carrying an email verification link.
+func emailVerificationMessage(appURL, email, token string) *mailer.Message {
+	link := fmt.Sprintf("%s/verify-email?token=%s", appURL, url.QueryEscape(token))
+	return &mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Please confirm that this is your email address.\n\n"+
+			"Open the link below within %s to verify it:\n%s\n", EmailVerificationTTL, link),
+	}
+}
+
+// emailChangeMessage builds the email sent to a new address, carrying the link that confirms the change.
+func emailChangeMessage(appURL, email, token string) *mailer.Message {
+	link := fmt.Sprintf("%s/confirm-email-change?token=%s", appURL, url.QueryEscape(token))
+	return &mailer.Message{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("We received a request to use this address for your account.\n\n"+
+			"Open the link below within %s to confirm it:\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n", EmailChangeTTL, link),
+	}
+}
+
+// emailChangeNoticeMessage warns the current address that a change to another one was requested.
+func emailChangeNoticeMessage(email, newEmail string) *mailer.Message {
+	return &mailer.Message{
+		To:      email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("A request was made to change the email address of your account to %s.\n\n"+
+			"If this was not you, reset your password right away.\n", newEmail),
+	}
+}
+
+// passwordChangedMessage tells the user their password was changed and other sessions signed out.
+func passwordChangedMessage(email string) *mailer.Message {
+	return &mailer.Message{
+		To:      email,
+		Subject: "Your password was changed",
+		Body: "The password of your account was changed and your other sessions were signed out.\n\n" +
+			"If this was not you, reset your password right away.\n",
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 
 	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -84,6 +85,98 @@ func (_c *MockRepository_Create_Call) RunAndReturn(run func(ctx context.Context,
 	return _c
 }
 
+// CreateIdentity provides a mock function for the type MockRepository
+func (_mock *MockRepository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	ret := _mock.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateIdentity")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.UserIdentity) error); ok {
+		r0 = returnFunc(ctx, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_CreateIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateIdentity'
+type MockRepository_CreateIdentity_Call struct {
+	*mock.Call
+}
+
+// CreateIdentity is a helper method to define mock.On call
+//   - ctx
+//   - identity
+func (_e *MockRepository_Expecter) CreateIdentity(ctx interface{}, identity interface{}) *MockRepository_CreateIdentity_Call {
+	return &MockRepository_CreateIdentity_Call{Call: _e.mock.On("CreateIdentity", ctx, identity)}
+}
+
+func (_c *MockRepository_CreateIdentity_Call) Run(run func(ctx context.Context, identity *model.UserIdentity)) *MockRepository_CreateIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.UserIdentity))
+	})
+	return _c
+}
+
+func (_c *MockRepository_CreateIdentity_Call) Return(err error) *MockRepository_CreateIdentity_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_CreateIdentity_Call) RunAndReturn(run func(ctx context.Context, identity *model.UserIdentity) error) *MockRepository_CreateIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function for the type MockRepository
+func (_mock *MockRepository) Delete(ctx context.Context, id string) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockRepository_Expecter) Delete(ctx interface{}, id interface{}) *MockRepository_Delete_Call {
+	return &MockRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *MockRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *MockRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Delete_Call) Return(err error) *MockRepository_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, id string) error) *MockRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByEmail provides a mock function for the type MockRepository
 func (_mock *MockRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	ret := _mock.Called(ctx, email)
@@ -197,3 +290,810 @@ func (_c *MockRepository_GetByID_Call) RunAndReturn(run func(ctx context.Context
 	_c.Call.Return(run)
 	return _c
 }
+
+// GetIdentity provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetIdentity(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+	ret := _mock.Called(ctx, provider, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdentity")
+	}
+
+	var r0 *model.UserIdentity
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*model.UserIdentity, error)); ok {
+		return returnFunc(ctx, provider, subject)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *model.UserIdentity); ok {
+		r0 = returnFunc(ctx, provider, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserIdentity)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, provider, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetIdentity'
+type MockRepository_GetIdentity_Call struct {
+	*mock.Call
+}
+
+// GetIdentity is a helper method to define mock.On call
+//   - ctx
+//   - provider
+//   - subject
+func (_e *MockRepository_Expecter) GetIdentity(ctx interface{}, provider interface{}, subject interface{}) *MockRepository_GetIdentity_Call {
+	return &MockRepository_GetIdentity_Call{Call: _e.mock.On("GetIdentity", ctx, provider, subject)}
+}
+
+func (_c *MockRepository_GetIdentity_Call) Run(run func(ctx context.Context, provider string, subject string)) *MockRepository_GetIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetIdentity_Call) Return(userIdentity *model.UserIdentity, err error) *MockRepository_GetIdentity_Call {
+	_c.Call.Return(userIdentity, err)
+	return _c
+}
+
+func (_c *MockRepository_GetIdentity_Call) RunAndReturn(run func(ctx context.Context, provider string, subject string) (*model.UserIdentity, error)) *MockRepository_GetIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUnusedRecoveryCodes provides a mock function for the type MockRepository
+func (_mock *MockRepository) GetUnusedRecoveryCodes(ctx context.Context, userID string) ([]model.RecoveryCode, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnusedRecoveryCodes")
+	}
+
+	var r0 []model.RecoveryCode
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]model.RecoveryCode, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []model.RecoveryCode); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.RecoveryCode)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_GetUnusedRecoveryCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUnusedRecoveryCodes'
+type MockRepository_GetUnusedRecoveryCodes_Call struct {
+	*mock.Call
+}
+
+// GetUnusedRecoveryCodes is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockRepository_Expecter) GetUnusedRecoveryCodes(ctx interface{}, userID interface{}) *MockRepository_GetUnusedRecoveryCodes_Call {
+	return &MockRepository_GetUnusedRecoveryCodes_Call{Call: _e.mock.On("GetUnusedRecoveryCodes", ctx, userID)}
+}
+
+func (_c *MockRepository_GetUnusedRecoveryCodes_Call) Run(run func(ctx context.Context, userID string)) *MockRepository_GetUnusedRecoveryCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetUnusedRecoveryCodes_Call) Return(recoveryCodes []model.RecoveryCode, err error) *MockRepository_GetUnusedRecoveryCodes_Call {
+	_c.Call.Return(recoveryCodes, err)
+	return _c
+}
+
+func (_c *MockRepository_GetUnusedRecoveryCodes_Call) RunAndReturn(run func(ctx context.Context, userID string) ([]model.RecoveryCode, error)) *MockRepository_GetUnusedRecoveryCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockRepository
+func (_mock *MockRepository) List(ctx context.Context, filter *UserFilter) ([]model.User, int64, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []model.User
+	var r1 int64
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *UserFilter) ([]model.User, int64, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *UserFilter) []model.User); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *UserFilter) int64); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *UserFilter) error); ok {
+		r2 = returnFunc(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx
+//   - filter
+func (_e *MockRepository_Expecter) List(ctx interface{}, filter interface{}) *MockRepository_List_Call {
+	return &MockRepository_List_Call{Call: _e.mock.On("List", ctx, filter)}
+}
+
+func (_c *MockRepository_List_Call) Run(run func(ctx context.Context, filter *UserFilter)) *MockRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*UserFilter))
+	})
+	return _c
+}
+
+func (_c *MockRepository_List_Call) Return(users []model.User, n int64, err error) *MockRepository_List_Call {
+	_c.Call.Return(users, n, err)
+	return _c
+}
+
+func (_c *MockRepository_List_Call) RunAndReturn(run func(ctx context.Context, filter *UserFilter) ([]model.User, int64, error)) *MockRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeyIDs provides a mock function for the type MockRepository
+func (_mock *MockRepository) ListAPIKeyIDs(ctx context.Context, userID string) ([]string, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeyIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_ListAPIKeyIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeyIDs'
+type MockRepository_ListAPIKeyIDs_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeyIDs is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockRepository_Expecter) ListAPIKeyIDs(ctx interface{}, userID interface{}) *MockRepository_ListAPIKeyIDs_Call {
+	return &MockRepository_ListAPIKeyIDs_Call{Call: _e.mock.On("ListAPIKeyIDs", ctx, userID)}
+}
+
+func (_c *MockRepository_ListAPIKeyIDs_Call) Run(run func(ctx context.Context, userID string)) *MockRepository_ListAPIKeyIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_ListAPIKeyIDs_Call) Return(ss []string, err error) *MockRepository_ListAPIKeyIDs_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockRepository_ListAPIKeyIDs_Call) RunAndReturn(run func(ctx context.Context, userID string) ([]string, error)) *MockRepository_ListAPIKeyIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOAuthClientIDs provides a mock function for the type MockRepository
+func (_mock *MockRepository) ListOAuthClientIDs(ctx context.Context, ownerID string) ([]string, error) {
+	ret := _mock.Called(ctx, ownerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOAuthClientIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return returnFunc(ctx, ownerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = returnFunc(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_ListOAuthClientIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOAuthClientIDs'
+type MockRepository_ListOAuthClientIDs_Call struct {
+	*mock.Call
+}
+
+// ListOAuthClientIDs is a helper method to define mock.On call
+//   - ctx
+//   - ownerID
+func (_e *MockRepository_Expecter) ListOAuthClientIDs(ctx interface{}, ownerID interface{}) *MockRepository_ListOAuthClientIDs_Call {
+	return &MockRepository_ListOAuthClientIDs_Call{Call: _e.mock.On("ListOAuthClientIDs", ctx, ownerID)}
+}
+
+func (_c *MockRepository_ListOAuthClientIDs_Call) Run(run func(ctx context.Context, ownerID string)) *MockRepository_ListOAuthClientIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_ListOAuthClientIDs_Call) Return(ss []string, err error) *MockRepository_ListOAuthClientIDs_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockRepository_ListOAuthClientIDs_Call) RunAndReturn(run func(ctx context.Context, ownerID string) ([]string, error)) *MockRepository_ListOAuthClientIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkEmailVerified provides a mock function for the type MockRepository
+func (_mock *MockRepository) MarkEmailVerified(ctx context.Context, id string) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkEmailVerified")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_MarkEmailVerified_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkEmailVerified'
+type MockRepository_MarkEmailVerified_Call struct {
+	*mock.Call
+}
+
+// MarkEmailVerified is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockRepository_Expecter) MarkEmailVerified(ctx interface{}, id interface{}) *MockRepository_MarkEmailVerified_Call {
+	return &MockRepository_MarkEmailVerified_Call{Call: _e.mock.On("MarkEmailVerified", ctx, id)}
+}
+
+func (_c *MockRepository_MarkEmailVerified_Call) Run(run func(ctx context.Context, id string)) *MockRepository_MarkEmailVerified_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_MarkEmailVerified_Call) Return(err error) *MockRepository_MarkEmailVerified_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_MarkEmailVerified_Call) RunAndReturn(run func(ctx context.Context, id string) error) *MockRepository_MarkEmailVerified_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReplaceRecoveryCodes provides a mock function for the type MockRepository
+func (_mock *MockRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []model.RecoveryCode) error {
+	ret := _mock.Called(ctx, userID, codes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceRecoveryCodes")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []model.RecoveryCode) error); ok {
+		r0 = returnFunc(ctx, userID, codes)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_ReplaceRecoveryCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplaceRecoveryCodes'
+type MockRepository_ReplaceRecoveryCodes_Call struct {
+	*mock.Call
+}
+
+// ReplaceRecoveryCodes is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - codes
+func (_e *MockRepository_Expecter) ReplaceRecoveryCodes(ctx interface{}, userID interface{}, codes interface{}) *MockRepository_ReplaceRecoveryCodes_Call {
+	return &MockRepository_ReplaceRecoveryCodes_Call{Call: _e.mock.On("ReplaceRecoveryCodes", ctx, userID, codes)}
+}
+
+func (_c *MockRepository_ReplaceRecoveryCodes_Call) Run(run func(ctx context.Context, userID string, codes []model.RecoveryCode)) *MockRepository_ReplaceRecoveryCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]model.RecoveryCode))
+	})
+	return _c
+}
+
+func (_c *MockRepository_ReplaceRecoveryCodes_Call) Return(err error) *MockRepository_ReplaceRecoveryCodes_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_ReplaceRecoveryCodes_Call) RunAndReturn(run func(ctx context.Context, userID string, codes []model.RecoveryCode) error) *MockRepository_ReplaceRecoveryCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPasswordResetRequired provides a mock function for the type MockRepository
+func (_mock *MockRepository) SetPasswordResetRequired(ctx context.Context, id string, required bool) error {
+	ret := _mock.Called(ctx, id, required)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPasswordResetRequired")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = returnFunc(ctx, id, required)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_SetPasswordResetRequired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPasswordResetRequired'
+type MockRepository_SetPasswordResetRequired_Call struct {
+	*mock.Call
+}
+
+// SetPasswordResetRequired is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - required
+func (_e *MockRepository_Expecter) SetPasswordResetRequired(ctx interface{}, id interface{}, required interface{}) *MockRepository_SetPasswordResetRequired_Call {
+	return &MockRepository_SetPasswordResetRequired_Call{Call: _e.mock.On("SetPasswordResetRequired", ctx, id, required)}
+}
+
+func (_c *MockRepository_SetPasswordResetRequired_Call) Run(run func(ctx context.Context, id string, required bool)) *MockRepository_SetPasswordResetRequired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockRepository_SetPasswordResetRequired_Call) Return(err error) *MockRepository_SetPasswordResetRequired_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_SetPasswordResetRequired_Call) RunAndReturn(run func(ctx context.Context, id string, required bool) error) *MockRepository_SetPasswordResetRequired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPendingEmail provides a mock function for the type MockRepository
+func (_mock *MockRepository) SetPendingEmail(ctx context.Context, id string, email string) error {
+	ret := _mock.Called(ctx, id, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPendingEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, id, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_SetPendingEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPendingEmail'
+type MockRepository_SetPendingEmail_Call struct {
+	*mock.Call
+}
+
+// SetPendingEmail is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - email
+func (_e *MockRepository_Expecter) SetPendingEmail(ctx interface{}, id interface{}, email interface{}) *MockRepository_SetPendingEmail_Call {
+	return &MockRepository_SetPendingEmail_Call{Call: _e.mock.On("SetPendingEmail", ctx, id, email)}
+}
+
+func (_c *MockRepository_SetPendingEmail_Call) Run(run func(ctx context.Context, id string, email string)) *MockRepository_SetPendingEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_SetPendingEmail_Call) Return(err error) *MockRepository_SetPendingEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_SetPendingEmail_Call) RunAndReturn(run func(ctx context.Context, id string, email string) error) *MockRepository_SetPendingEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateEmail provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdateEmail(ctx context.Context, id string, email string) error {
+	ret := _mock.Called(ctx, id, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, id, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_UpdateEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateEmail'
+type MockRepository_UpdateEmail_Call struct {
+	*mock.Call
+}
+
+// UpdateEmail is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - email
+func (_e *MockRepository_Expecter) UpdateEmail(ctx interface{}, id interface{}, email interface{}) *MockRepository_UpdateEmail_Call {
+	return &MockRepository_UpdateEmail_Call{Call: _e.mock.On("UpdateEmail", ctx, id, email)}
+}
+
+func (_c *MockRepository_UpdateEmail_Call) Run(run func(ctx context.Context, id string, email string)) *MockRepository_UpdateEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_UpdateEmail_Call) Return(err error) *MockRepository_UpdateEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_UpdateEmail_Call) RunAndReturn(run func(ctx context.Context, id string, email string) error) *MockRepository_UpdateEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePassword provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdatePassword(ctx context.Context, id string, hashedPassword string) error {
+	ret := _mock.Called(ctx, id, hashedPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, id, hashedPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_UpdatePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePassword'
+type MockRepository_UpdatePassword_Call struct {
+	*mock.Call
+}
+
+// UpdatePassword is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - hashedPassword
+func (_e *MockRepository_Expecter) UpdatePassword(ctx interface{}, id interface{}, hashedPassword interface{}) *MockRepository_UpdatePassword_Call {
+	return &MockRepository_UpdatePassword_Call{Call: _e.mock.On("UpdatePassword", ctx, id, hashedPassword)}
+}
+
+func (_c *MockRepository_UpdatePassword_Call) Run(run func(ctx context.Context, id string, hashedPassword string)) *MockRepository_UpdatePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_UpdatePassword_Call) Return(err error) *MockRepository_UpdatePassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_UpdatePassword_Call) RunAndReturn(run func(ctx context.Context, id string, hashedPassword string) error) *MockRepository_UpdatePassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProfile provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdateProfile(ctx context.Context, user *model.User) error {
+	ret := _mock.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProfile")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.User) error); ok {
+		r0 = returnFunc(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_UpdateProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProfile'
+type MockRepository_UpdateProfile_Call struct {
+	*mock.Call
+}
+
+// UpdateProfile is a helper method to define mock.On call
+//   - ctx
+//   - user
+func (_e *MockRepository_Expecter) UpdateProfile(ctx interface{}, user interface{}) *MockRepository_UpdateProfile_Call {
+	return &MockRepository_UpdateProfile_Call{Call: _e.mock.On("UpdateProfile", ctx, user)}
+}
+
+func (_c *MockRepository_UpdateProfile_Call) Run(run func(ctx context.Context, user *model.User)) *MockRepository_UpdateProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.User))
+	})
+	return _c
+}
+
+func (_c *MockRepository_UpdateProfile_Call) Return(err error) *MockRepository_UpdateProfile_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_UpdateProfile_Call) RunAndReturn(run func(ctx context.Context, user *model.User) error) *MockRepository_UpdateProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateStatus provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdateStatus(ctx context.Context, id string, status model.UserStatus) error {
+	ret := _mock.Called(ctx, id, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, model.UserStatus) error); ok {
+		r0 = returnFunc(ctx, id, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_UpdateStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateStatus'
+type MockRepository_UpdateStatus_Call struct {
+	*mock.Call
+}
+
+// UpdateStatus is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - status
+func (_e *MockRepository_Expecter) UpdateStatus(ctx interface{}, id interface{}, status interface{}) *MockRepository_UpdateStatus_Call {
+	return &MockRepository_UpdateStatus_Call{Call: _e.mock.On("UpdateStatus", ctx, id, status)}
+}
+
+func (_c *MockRepository_UpdateStatus_Call) Run(run func(ctx context.Context, id string, status model.UserStatus)) *MockRepository_UpdateStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(model.UserStatus))
+	})
+	return _c
+}
+
+func (_c *MockRepository_UpdateStatus_Call) Return(err error) *MockRepository_UpdateStatus_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_UpdateStatus_Call) RunAndReturn(run func(ctx context.Context, id string, status model.UserStatus) error) *MockRepository_UpdateStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateTOTP provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpdateTOTP(ctx context.Context, id string, secret string, enabled bool) error {
+	ret := _mock.Called(ctx, id, secret, enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTOTP")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, bool) error); ok {
+		r0 = returnFunc(ctx, id, secret, enabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_UpdateTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateTOTP'
+type MockRepository_UpdateTOTP_Call struct {
+	*mock.Call
+}
+
+// UpdateTOTP is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - secret
+//   - enabled
+func (_e *MockRepository_Expecter) UpdateTOTP(ctx interface{}, id interface{}, secret interface{}, enabled interface{}) *MockRepository_UpdateTOTP_Call {
+	return &MockRepository_UpdateTOTP_Call{Call: _e.mock.On("UpdateTOTP", ctx, id, secret, enabled)}
+}
+
+func (_c *MockRepository_UpdateTOTP_Call) Run(run func(ctx context.Context, id string, secret string, enabled bool)) *MockRepository_UpdateTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockRepository_UpdateTOTP_Call) Return(err error) *MockRepository_UpdateTOTP_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_UpdateTOTP_Call) RunAndReturn(run func(ctx context.Context, id string, secret string, enabled bool) error) *MockRepository_UpdateTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseRecoveryCode provides a mock function for the type MockRepository
+func (_mock *MockRepository) UseRecoveryCode(ctx context.Context, id uuid.UUID) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseRecoveryCode")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_UseRecoveryCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseRecoveryCode'
+type MockRepository_UseRecoveryCode_Call struct {
+	*mock.Call
+}
+
+// UseRecoveryCode is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockRepository_Expecter) UseRecoveryCode(ctx interface{}, id interface{}) *MockRepository_UseRecoveryCode_Call {
+	return &MockRepository_UseRecoveryCode_Call{Call: _e.mock.On("UseRecoveryCode", ctx, id)}
+}
+
+func (_c *MockRepository_UseRecoveryCode_Call) Run(run func(ctx context.Context, id uuid.UUID)) *MockRepository_UseRecoveryCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockRepository_UseRecoveryCode_Call) Return(err error) *MockRepository_UseRecoveryCode_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_UseRecoveryCode_Call) RunAndReturn(run func(ctx context.Context, id uuid.UUID) error) *MockRepository_UseRecoveryCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseTOTPStep provides a mock function for the type MockRepository
+func (_mock *MockRepository) UseTOTPStep(ctx context.Context, id string, step int64) error {
+	ret := _mock.Called(ctx, id, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseTOTPStep")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = returnFunc(ctx, id, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_UseTOTPStep_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseTOTPStep'
+type MockRepository_UseTOTPStep_Call struct {
+	*mock.Call
+}
+
+// UseTOTPStep is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - step
+func (_e *MockRepository_Expecter) UseTOTPStep(ctx interface{}, id interface{}, step interface{}) *MockRepository_UseTOTPStep_Call {
+	return &MockRepository_UseTOTPStep_Call{Call: _e.mock.On("UseTOTPStep", ctx, id, step)}
+}
+
+func (_c *MockRepository_UseTOTPStep_Call) Run(run func(ctx context.Context, id string, step int64)) *MockRepository_UseTOTPStep_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRepository_UseTOTPStep_Call) Return(err error) *MockRepository_UseTOTPStep_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_UseTOTPStep_Call) RunAndReturn(run func(ctx context.Context, id string, step int64) error) *MockRepository_UseTOTPStep_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -2,17 +2,48 @@ package user
 
 import (
 	"context"
+	"net/http"
+	"strings"
+	"time"
 
 	errs "github.com/chai-rs/simple-bookstore/internal/error"
 	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// UserFilter narrows a user search. Empty fields match anything.
+// Query matches part of the email or display name, case-insensitively.
+type UserFilter struct {
+	Query  string
+	Status model.UserStatus
+	Limit  int
+	Offset int
+}
+
 // Repository represents the user repository interface.
 type Repository interface {
 	Create(ctx context.Context, user *model.User) error
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	GetByID(ctx context.Context, id string) (*model.User, error)
+	UpdateTOTP(ctx context.Context, id string, secret string, enabled bool) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codes []model.RecoveryCode) error
+	GetUnusedRecoveryCodes(ctx context.Context, userID string) ([]model.RecoveryCode, error)
+	UseRecoveryCode(ctx context.Context, id uuid.UUID) error
+	UseTOTPStep(ctx context.Context, id string, step int64) error
+	UpdatePassword(ctx context.Context, id string, hashedPassword string) error
+	MarkEmailVerified(ctx context.Context, id string) error
+	GetIdentity(ctx context.Context, provider string, subject string) (*model.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *model.UserIdentity) error
+	UpdateProfile(ctx context.Context, user *model.User) error
+	SetPendingEmail(ctx context.Context, id string, email string) error
+	UpdateEmail(ctx context.Context, id string, email string) error
+	ListAPIKeyIDs(ctx context.Context, userID string) ([]string, error)
+	ListOAuthClientIDs(ctx context.Context, ownerID string) ([]string, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filter *UserFilter) ([]model.User, int64, error)
+	UpdateStatus(ctx context.Context, id string, status model.UserStatus) error
+	SetPasswordResetRequired(ctx context.Context, id string, required bool) error
 }
 
 // repository implements the Repository interface.
@@ -25,7 +56,7 @@ func NewRepository(db *gorm.DB) *repository {
 }
 
 func (r *repository) Create(ctx context.Context, user *model.User) error {
-	if err := r.db.Create(user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
 		return errs.FromGorm(err)
 	}
 	return nil
@@ -33,7 +64,7 @@ func (r *repository) Create(ctx context.Context, user *model.User) error {
 
 func (r *repository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		return nil, errs.FromGorm(err)
 	}
@@ -43,10 +74,247 @@ func (r *repository) GetByEmail(ctx context.Context, email string) (*model.User,
 
 func (r *repository) GetByID(ctx context.Context, id string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("id = ?", id).First(&user).Error
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
 	if err != nil {
 		return nil, errs.FromGorm(err)
 	}
 
 	return &user, nil
 }
+
+func (r *repository) UpdateTOTP(ctx context.Context, id string, secret string, enabled bool) error {
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]any{
+		"totp_secret":    secret,
+		"totp_enabled":   enabled,
+		"totp_last_step": gorm.Expr("CASE WHEN totp_secret = ? THEN totp_last_step ELSE 0 END", secret),
+	}).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+func (r *repository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []model.RecoveryCode) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		if len(codes) == 0 {
+			return nil
+		}
+
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetUnusedRecoveryCodes(ctx context.Context, userID string) ([]model.RecoveryCode, error) {
+	var codes []model.RecoveryCode
+	err := r.db.WithContext(ctx).Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error
+	if err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return codes, nil
+}
+
+func (r *repository) UseRecoveryCode(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return errs.FromGorm(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.New(http.StatusUnauthorized, gorm.ErrRecordNotFound, "recovery code already used")
+	}
+
+	return nil
+}
+
+// UseTOTPStep records step as the last TOTP time step used, refusing it unless it is later than the previous one.
+func (r *repository) UseTOTPStep(ctx context.Context, id string, step int64) error {
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return errs.FromGorm(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.New(http.StatusUnauthorized, gorm.ErrRecordNotFound, "code already used")
+	}
+
+	return nil
+}
+
+func (r *repository) UpdatePassword(ctx context.Context, id string, hashedPassword string) error {
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("hashed_password", hashedPassword).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+func (r *repository) MarkEmailVerified(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ? AND email_verified_at IS NULL", id).Update("email_verified_at", time.Now()).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetIdentity(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	return &identity, nil
+}
+
+func (r *repository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return errs.FromGorm(err)
+	}
+	return nil
+}
+
+// UpdateProfile saves the profile fields of the user: display name, avatar, locale and preferences.
+func (r *repository) UpdateProfile(ctx context.Context, user *model.User) error {
+	err := r.db.WithContext(ctx).Model(user).Select("display_name", "avatar_url", "locale", "preferences").Updates(user).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+func (r *repository) SetPendingEmail(ctx context.Context, id string, email string) error {
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("pending_email", email).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+// UpdateEmail replaces the email with a verified one and clears the pending email.
+func (r *repository) UpdateEmail(ctx context.Context, id string, email string) error {
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]any{
+		"email":             email,
+		"email_verified_at": time.Now(),
+		"pending_email":     "",
+	}).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+// ListAPIKeyIDs returns the IDs of the user's API keys, revoked ones included.
+func (r *repository) ListAPIKeyIDs(ctx context.Context, userID string) ([]string, error) {
+	var ids []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&model.APIKey{}).Where("user_id = ?", userID).Pluck("id", &ids).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	keyIDs := make([]string, len(ids))
+	for i, id := range ids {
+		keyIDs[i] = id.String()
+	}
+
+	return keyIDs, nil
+}
+
+// ListOAuthClientIDs returns the IDs of the OAuth2 clients registered by the user.
+func (r *repository) ListOAuthClientIDs(ctx context.Context, ownerID string) ([]string, error) {
+	var ids []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&model.OAuthClient{}).Where("owner_id = ?", ownerID).Pluck("id", &ids).Error; err != nil {
+		return nil, errs.FromGorm(err)
+	}
+
+	clientIDs := make([]string, len(ids))
+	for i, id := range ids {
+		clientIDs[i] = id.String()
+	}
+
+	return clientIDs, nil
+}
+
+// Delete removes the user. Recovery codes, identities, API keys, OAuth2 clients and consents are removed with it.
+func (r *repository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.User{})
+	if result.Error != nil {
+		return errs.FromGorm(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.New(http.StatusNotFound, gorm.ErrRecordNotFound, "user not found")
+	}
+
+	return nil
+}
+
+// List returns a page of the users matching filter, oldest first, and how many match in total.
+func (r *repository) List(ctx context.Context, filter *UserFilter) ([]model.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.User{})
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR LOWER(display_name) LIKE ?", pattern, pattern)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errs.FromGorm(err)
+	}
+
+	var users []model.User
+	if err := query.Order("created_at, id").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error; err != nil {
+		return nil, 0, errs.FromGorm(err)
+	}
+
+	return users, total, nil
+}
+
+func (r *repository) UpdateStatus(ctx context.Context, id string, status model.UserStatus) error {
+	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("status", status)
+	if result.Error != nil {
+		return errs.FromGorm(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.New(http.StatusNotFound, gorm.ErrRecordNotFound, "user not found")
+	}
+
+	return nil
+}
+
+func (r *repository) SetPasswordResetRequired(ctx context.Context, id string, required bool) error {
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("password_reset_required", required).Error
+	if err != nil {
+		return errs.FromGorm(err)
+	}
+
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards in s, so they match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -2,123 +2,1411 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/infrastructure/mailer"
+	"github.com/chai-rs/simple-bookstore/infrastructure/oidc"
+	"github.com/chai-rs/simple-bookstore/internal/audit"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
 	"github.com/chai-rs/simple-bookstore/internal/model"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
 	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/chai-rs/simple-bookstore/pkg/password"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	// RecoveryCodeCount is the number of recovery codes generated when two-factor authentication is activated.
+	RecoveryCodeCount = 10
+	// PasswordResetTTL is how long a password reset token stays valid.
+	PasswordResetTTL = 30 * time.Minute
+	// EmailVerificationTTL is how long an email verification token stays valid.
+	EmailVerificationTTL = 24 * time.Hour
+	// OIDCStateTTL is how long a social login may take between the redirect and the callback.
+	OIDCStateTTL = 10 * time.Minute
+	// EmailChangeTTL is how long the link confirming a new email address stays valid.
+	EmailChangeTTL = 24 * time.Hour
+	// MaxDisplayNameLength is the longest display name, in characters.
+	MaxDisplayNameLength = 100
+	// MaxAvatarURLLength is the longest avatar URL, in bytes.
+	MaxAvatarURLLength = 2048
+	// MaxPreferencesSize is the largest preferences document, in bytes of JSON.
+	MaxPreferencesSize = 4 << 10
+	// ForcedPasswordResetTTL is how long the link sent when an administrator forces a password reset stays valid.
+	ForcedPasswordResetTTL = 72 * time.Hour
+	// DefaultPageSize is how many users a search returns when no limit is given.
+	DefaultPageSize = 20
+	// MaxPageSize is the most users a single search may return.
+	MaxPageSize = 100
+)
+
+// localePattern matches BCP 47 style language tags such as "en", "pt-BR" or "zh-Hant-TW".
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
 // Service represents the user service interface.
 type Service interface {
-	Login(ctx context.Context, email string, password string) (string, string, error)
-	Register(ctx context.Context, user *model.User) (string, string, error)
+	Login(ctx context.Context, email string, password string) (*LoginResult, error)
+	LoginTOTP(ctx context.Context, challengeToken string, code string) (string, string, error)
+	Register(ctx context.Context, email string, password string) (string, string, error)
 	Logout(ctx context.Context, metadata *auth.AccessProperties) error
 	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
+	EnrollTOTP(ctx context.Context, userID string) (string, string, error)
+	ActivateTOTP(ctx context.Context, userID string, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID string, code string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	SendEmailVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	OIDCAuthURL(ctx context.Context, provider string) (string, error)
+	OIDCCallback(ctx context.Context, provider string, state string, code string) (*LoginResult, error)
+	GetRoles(ctx context.Context, userID string) ([]auth.Role, error)
+	GrantRole(ctx context.Context, userID string, role auth.Role) error
+	RevokeRole(ctx context.Context, actorID string, userID string, role auth.Role) error
+	GetProfile(ctx context.Context, userID string) (*model.User, error)
+	UpdateProfile(ctx context.Context, userID string, update *ProfileUpdate) (*model.User, error)
+	ChangePassword(ctx context.Context, metadata *auth.AccessProperties, currentPassword string, newPassword string) error
+	ChangeEmail(ctx context.Context, userID string, password string, email string) error
+	ConfirmEmailChange(ctx context.Context, token string) error
+	DeleteAccount(ctx context.Context, metadata *auth.AccessProperties, password string) error
+	ListUsers(ctx context.Context, filter *UserFilter) ([]model.User, int64, error)
+	GetUser(ctx context.Context, userID string) (*model.User, error)
+	DisableUser(ctx context.Context, actorID string, userID string) error
+	EnableUser(ctx context.Context, userID string) error
+	ForcePasswordReset(ctx context.Context, userID string) error
+}
+
+// ProfileUpdate holds the profile fields to change. Nil fields are left as they are,
+// empty strings clear a field and a non-nil Preferences replaces all preferences.
+type ProfileUpdate struct {
+	DisplayName *string
+	AvatarURL   *string
+	Locale      *string
+	Preferences map[string]any
+}
+
+// LoginResult represents the outcome of a password login.
+// When the user has two-factor authentication enabled, only ChallengeToken is set.
+type LoginResult struct {
+	AccessToken    string
+	RefreshToken   string
+	ChallengeToken string
+}
+
+// ServiceOpts contains optional collaborators for the user service.
+// Unset fields fall back to in-memory implementations.
+type ServiceOpts struct {
+	Mailer         mailer.Mailer
+	OneTimeTokens  auth.OneTimeTokens
+	LoginAttempts  auth.LoginAttempts
+	PasswordPolicy *password.Policy
+	PasswordHasher *crypto.PasswordHasher
+	OIDCProviders  map[string]*oidc.Provider
+	OIDCStates     oidc.StateStore
+	Recorder       audit.Recorder
+
+	// AppURL is the frontend base URL the links in emails point to.
+	AppURL string
+	// TOTPIssuer names the service in authenticator apps.
+	TOTPIssuer string
+	// RequireEmailVerification refuses password logins until the email address is verified.
+	RequireEmailVerification bool
 }
 
 // service implements the Service interface
 type service struct {
-	repo         Repository
-	auth         auth.Auth
-	tokenManager auth.TokenManager
-	enforcer     auth.AuthEnforcer
+	repo                     Repository
+	auth                     auth.Auth
+	tokenManager             auth.TokenManager
+	enforcer                 auth.AuthEnforcer
+	mailer                   mailer.Mailer
+	oneTimeTokens            auth.OneTimeTokens
+	loginAttempts            auth.LoginAttempts
+	policy                   *password.Policy
+	passwords                *crypto.PasswordHasher
+	oidcProviders            map[string]*oidc.Provider
+	oidcStates               oidc.StateStore
+	recorder                 audit.Recorder
+	appURL                   string
+	totpIssuer               string
+	requireEmailVerification bool
+	// dummyPasswordHash is compared against when the email is unknown, so both failure paths take as long.
+	// It is hashed with the configured hasher, as a hash from another algorithm would not cost the same.
+	dummyPasswordHash string
+}
+
+func NewService(repo Repository, auth auth.Auth, tokenManager auth.TokenManager, enforcer auth.AuthEnforcer, opts ...*ServiceOpts) *service {
+	option := ServiceOpts{}
+	if len(opts) > 0 {
+		option = *opts[0]
+	}
+	option = option.withDefaults()
+
+	return &service{repo, auth, tokenManager, enforcer, option.Mailer, option.OneTimeTokens, option.LoginAttempts, option.PasswordPolicy, option.PasswordHasher, option.OIDCProviders, option.OIDCStates, option.Recorder, option.AppURL, option.TOTPIssuer, option.RequireEmailVerification, option.PasswordHasher.MustHash("dummy-password")}
 }
 
-func NewService(repo Repository, auth auth.Auth, tokenManager auth.TokenManager, enforcer auth.AuthEnforcer) *service {
-	return &service{repo, auth, tokenManager, enforcer}
+// withDefaults fills unset collaborators with in-memory implementations.
+func (o ServiceOpts) withDefaults() ServiceOpts {
+	if o.Mailer == nil {
+		o.Mailer = mailer.NewMemoryMailer()
+	}
+
+	// In-memory tokens never leave the process, so random keys sign them.
+	if o.OneTimeTokens == nil {
+		o.OneTimeTokens = auth.NewMemoryOneTimeTokens(auth.NewKeys(rand.Text(), rand.Text()))
+	}
+
+	if o.LoginAttempts == nil {
+		o.LoginAttempts = auth.NewMemoryLoginAttempts()
+	}
+
+	if o.PasswordPolicy == nil {
+		o.PasswordPolicy = password.DefaultPolicy
+	}
+
+	if o.PasswordHasher == nil {
+		o.PasswordHasher = crypto.NewPasswordHasher(nil)
+	}
+
+	if o.OIDCStates == nil {
+		o.OIDCStates = oidc.NewMemoryStateStore()
+	}
+
+	if o.Recorder == nil {
+		o.Recorder = audit.NewMemoryRecorder()
+	}
+
+	return o
 }
 
-func (s *service) Login(ctx context.Context, email string, password string) (string, string, error) {
+func (s *service) Login(ctx context.Context, email string, password string) (*LoginResult, error) {
+	ip := utils.ClientInfoFromContext(ctx).IP
+	if err := s.checkLoginAttempts(ctx, email, ip); err != nil {
+		s.recordLogin(ctx, audit.Login, "", err, "blocked")
+		return nil, err
+	}
+
 	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil && !errs.IsNotFound(err) {
+		log.Ctx(ctx).Error().Err(err).Str("email", email).Msg("🚨 failed to get user by email")
+		return nil, err
+	}
+
 	if err != nil {
-		log.Error().Err(err).Str("email", email).Msg("🚨 failed to get user by email")
-		return "", "", err
+		s.passwords.Compare(ctx, password, s.dummyPasswordHash)
+		log.Ctx(ctx).Error().Err(err).Str("email", email).Msg("🚨 failed to get user by email")
+		err = s.failLogin(ctx, email, ip)
+		s.recordLogin(ctx, audit.Login, "", err, "unknown email")
+		return nil, err
 	}
 
-	if !crypto.ComparePassword(password, user.HashedPassword) {
-		log.Error().Str("email", email).Msg("🚨 invalid password")
-		return "", "", fmt.Errorf("invalid password")
+	ok, needsRehash := s.passwords.Compare(ctx, password, user.HashedPassword)
+	if !ok {
+		log.Ctx(ctx).Error().Str("email", email).Msg("🚨 invalid password")
+		err = s.failLogin(ctx, email, ip)
+		s.recordLogin(ctx, audit.Login, user.ID.String(), err, "invalid password")
+		return nil, err
 	}
 
-	ts, err := s.tokenManager.CreateToken(user.ID.String(), user.Email)
+	if needsRehash {
+		s.rehashPassword(ctx, user, password)
+	}
+
+	if err := s.loginAttempts.Reset(ctx, email); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to reset login attempts")
+		return nil, err
+	}
+
+	if s.requireEmailVerification && user.EmailVerifiedAt == nil {
+		log.Ctx(ctx).Error().Str("email", email).Msg("🚨 email is not verified")
+		err := errs.New(http.StatusForbidden, fmt.Errorf("email is not verified"), "email is not verified")
+		s.recordLogin(ctx, audit.Login, user.ID.String(), err, "email not verified")
+		return nil, err
+	}
+
+	if err := ensureActive(ctx, user); err != nil {
+		s.recordLogin(ctx, audit.Login, user.ID.String(), err, "account disabled")
+		return nil, err
+	}
+
+	if user.PasswordResetRequired {
+		log.Ctx(ctx).Error().Str("email", email).Msg("🚨 password reset is required")
+		err := errs.New(http.StatusForbidden, fmt.Errorf("password reset is required"), "you must reset your password before signing in, check your email")
+		s.recordLogin(ctx, audit.Login, user.ID.String(), err, "password reset required")
+		return nil, err
+	}
+
+	result, err := s.completeLogin(ctx, user)
+	s.recordLogin(ctx, audit.Login, user.ID.String(), err, secondFactorDetails(result))
+	return result, err
+}
+
+func (s *service) LoginTOTP(ctx context.Context, challengeToken string, code string) (string, string, error) {
+	userID, err := s.oneTimeTokens.Lookup(ctx, auth.LoginChallenge, challengeToken)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 invalid challenge token")
+		return "", "", errs.New(http.StatusUnauthorized, err, "invalid challenge token")
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Msg("🚨 failed to create token")
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
 		return "", "", err
 	}
 
-	if err := s.auth.CreateAuth(ctx, user.ID.String(), ts); err != nil {
-		log.Error().Err(err).Msg("🚨 failed to create auth")
+	if !user.TOTPEnabled {
+		return "", "", errs.New(http.StatusBadRequest, fmt.Errorf("totp is not enabled"), "two-factor authentication is not enabled")
+	}
+
+	// The account may have been disabled since the challenge was issued.
+	if err := ensureActive(ctx, user); err != nil {
+		s.recordLogin(ctx, audit.LoginTOTP, userID, err, "account disabled")
 		return "", "", err
 	}
 
-	return ts.AccessToken, ts.RefreshToken, nil
-}
+	ip := utils.ClientInfoFromContext(ctx).IP
+	if err := s.checkLoginAttempts(ctx, user.Email, ip); err != nil {
+		s.recordLogin(ctx, audit.LoginTOTP, userID, err, "blocked")
+		return "", "", err
+	}
 
-func (s *service) Register(ctx context.Context, user *model.User) (string, string, error) {
-	user.ID = uuid.New()
-	err := s.repo.Create(ctx, user)
-	if err != nil {
-		log.Error().Err(err).Str("email", user.Email).Msg("🚨 failed to create user")
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		if failErr := s.loginAttempts.Fail(ctx, user.Email, ip); failErr != nil {
+			log.Ctx(ctx).Error().Err(failErr).Msg("🚨 failed to record login attempt")
+		}
+		s.recordLogin(ctx, audit.LoginTOTP, userID, err, "invalid code")
 		return "", "", err
 	}
 
-	if err := s.enforcer.AddPolicy(user.ID.String(), auth.Resource, auth.Read); err != nil {
-		log.Error().Err(err).Str("email", user.Email).Msg("🚨 failed to add policy")
+	if err := s.loginAttempts.Reset(ctx, user.Email); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to reset login attempts")
 		return "", "", err
 	}
 
-	if err := s.enforcer.AddPolicy(user.ID.String(), auth.Resource, auth.Write); err != nil {
-		log.Error().Err(err).Str("email", user.Email).Msg("🚨 failed to add policy")
+	// The challenge is only consumed once the code is verified, so a mistyped code can be retried,
+	// but a challenge completed once, or concurrently, can never mint another token pair.
+	if _, err := s.oneTimeTokens.Consume(ctx, auth.LoginChallenge, challengeToken); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 challenge token already used")
+		err = errs.New(http.StatusUnauthorized, err, "invalid challenge token")
+		s.recordLogin(ctx, audit.LoginTOTP, userID, err, "challenge already used")
 		return "", "", err
 	}
 
-	ts, err := s.tokenManager.CreateToken(user.ID.String(), user.Email)
+	accessToken, refreshToken, err := s.issueTokens(ctx, user)
+	s.recordLogin(ctx, audit.LoginTOTP, userID, err, "")
+	return accessToken, refreshToken, err
+}
+
+// Register creates an account with the given credentials once the password passes the policy.
+func (s *service) Register(ctx context.Context, email string, password string) (string, string, error) {
+	if err := s.ValidatePassword(email, password); err != nil {
+		return "", "", err
+	}
+
+	hashedPassword, err := s.passwords.Hash(ctx, password)
 	if err != nil {
-		log.Error().Err(err).Msg("🚨 failed to create token")
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to hash password")
 		return "", "", err
 	}
 
-	if err := s.auth.CreateAuth(ctx, user.ID.String(), ts); err != nil {
-		log.Error().Err(err).Msg("🚨 failed to create auth")
+	user := &model.User{Email: email, HashedPassword: hashedPassword}
+	err = s.createUser(ctx, user)
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.Register, Outcome: audit.OutcomeOf(err), ActorID: actorOf(user, err), Target: actorOf(user, err)})
+	if err != nil {
 		return "", "", err
 	}
 
-	return ts.AccessToken, ts.RefreshToken, nil
+	if err := s.sendEmailVerification(ctx, user); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("email", user.Email).Msg("⚠️ failed to send verification email")
+	}
+
+	return s.issueTokens(ctx, user)
 }
 
 func (s *service) Logout(ctx context.Context, metadata *auth.AccessProperties) error {
 	if err := s.auth.DeleteAccessToken(ctx, metadata); err != nil {
-		log.Error().Err(err).Msg("🚨 failed to delete access token")
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to delete access token")
 		return err
 	}
 
 	if err := s.auth.DeleteRefreshToken(ctx, auth.ToRefreshUUID(metadata.TokenUUID, metadata.UserID)); err != nil {
-		log.Error().Err(err).Msg("🚨 failed to delete refresh token")
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to delete refresh token")
 		return err
 	}
 
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.Logout, Outcome: audit.Success, ActorID: metadata.UserID, Target: metadata.UserID})
 	return nil
 }
 
 func (s *service) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
-	userID, err := s.auth.FetchAuth(ctx, refreshToken)
+	refreshUUID, err := s.tokenManager.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to verify refresh token")
+		s.recorder.Record(ctx, &model.AuditEvent{Type: audit.TokenRefresh, Outcome: audit.Failure, Details: "invalid refresh token"})
+		return "", "", errs.New(http.StatusUnauthorized, err, "invalid refresh token")
+	}
+
+	userID, err := s.auth.FetchAuth(ctx, refreshUUID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to fetch auth")
+		s.recorder.Record(ctx, &model.AuditEvent{Type: audit.TokenRefresh, Outcome: audit.Failure, Details: "revoked refresh token"})
+		return "", "", errs.New(http.StatusUnauthorized, err, "invalid refresh token")
+	}
+
+	// A refresh token is used once: the new pair replaces it.
+	if err := s.auth.DeleteRefreshToken(ctx, refreshUUID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to delete refresh token")
+		return "", "", errs.New(http.StatusUnauthorized, err, "invalid refresh token")
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Msg("🚨 failed to fetch auth")
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
 		return "", "", err
 	}
 
+	if err := ensureActive(ctx, user); err != nil {
+		s.recorder.Record(ctx, &model.AuditEvent{Type: audit.TokenRefresh, Outcome: audit.Failure, ActorID: userID, Target: userID, Details: "account disabled"})
+		return "", "", err
+	}
+
+	accessToken, newRefreshToken, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.TokenRefresh, Outcome: audit.Success, ActorID: userID, Target: userID})
+	return accessToken, newRefreshToken, nil
+}
+
+func (s *service) EnrollTOTP(ctx context.Context, userID string) (string, string, error) {
 	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Msg("🚨 failed to get user by id")
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
 		return "", "", err
 	}
 
-	ts, err := s.tokenManager.CreateToken(user.ID.String(), user.Email)
+	if user.TOTPEnabled {
+		return "", "", errs.New(http.StatusConflict, fmt.Errorf("totp is already enabled"), "two-factor authentication is already enabled")
+	}
+
+	secret, err := crypto.GenerateTOTPSecret()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to generate totp secret")
+		return "", "", err
+	}
+
+	if err := s.repo.UpdateTOTP(ctx, userID, secret, false); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to update totp")
+		return "", "", err
+	}
+
+	return secret, crypto.TOTPURI(s.totpIssuer, user.Email, secret), nil
+}
+
+func (s *service) ActivateTOTP(ctx context.Context, userID string, code string) ([]string, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, errs.New(http.StatusConflict, fmt.Errorf("totp is already enabled"), "two-factor authentication is already enabled")
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, errs.New(http.StatusBadRequest, fmt.Errorf("totp is not enrolled"), "two-factor authentication is not enrolled")
+	}
+
+	step, ok := crypto.MatchTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, errs.New(http.StatusUnauthorized, fmt.Errorf("invalid totp code"), "invalid code")
+	}
+
+	if err := s.repo.UseTOTPStep(ctx, userID, step); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 totp code already used")
+		return nil, err
+	}
+
+	codes, err := crypto.GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to generate recovery codes")
+		return nil, err
+	}
+
+	recoveryCodes := make([]model.RecoveryCode, len(codes))
+	for i, code := range codes {
+		hashedCode, err := s.passwords.Hash(ctx, code)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to hash recovery code")
+			return nil, err
+		}
+
+		recoveryCodes[i] = model.RecoveryCode{
+			ID:         uuid.New(),
+			UserID:     user.ID,
+			HashedCode: hashedCode,
+		}
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, recoveryCodes); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to replace recovery codes")
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTOTP(ctx, userID, user.TOTPSecret, true); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to update totp")
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *service) DisableTOTP(ctx context.Context, userID string, code string) error {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return err
+	}
+
+	if !user.TOTPEnabled {
+		return errs.New(http.StatusBadRequest, fmt.Errorf("totp is not enabled"), "two-factor authentication is not enabled")
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, nil); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to replace recovery codes")
+		return err
+	}
+
+	if err := s.repo.UpdateTOTP(ctx, userID, "", false); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to update totp")
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		// Respond the same way for unknown emails so accounts can't be enumerated.
+		log.Ctx(ctx).Warn().Err(err).Msg("⚠️ password reset requested for unknown email")
+		return nil
+	}
+
+	token, err := s.oneTimeTokens.Issue(ctx, auth.PasswordReset, user.ID.String(), PasswordResetTTL)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to issue password reset token")
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, passwordResetMessage(s.appURL, user.Email, token)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to send password reset email")
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	userID, err := s.oneTimeTokens.Lookup(ctx, auth.PasswordReset, token)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 invalid password reset token")
+		return errs.New(http.StatusBadRequest, err, "invalid or expired token")
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return err
+	}
+
+	// Validate before consuming the token so a rejected password doesn't burn it.
+	if err := s.ValidatePassword(user.Email, newPassword); err != nil {
+		return err
+	}
+
+	if _, err := s.oneTimeTokens.Consume(ctx, auth.PasswordReset, token); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 invalid password reset token")
+		return errs.New(http.StatusBadRequest, err, "invalid or expired token")
+	}
+
+	hashedPassword, err := s.passwords.Hash(ctx, newPassword)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to hash password")
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to update password")
+		return err
+	}
+
+	if user.PasswordResetRequired {
+		if err := s.repo.SetPasswordResetRequired(ctx, userID, false); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to clear password reset requirement")
+			return err
+		}
+	}
+
+	if err := s.loginAttempts.Reset(ctx, user.Email); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to reset login attempts")
+		return err
+	}
+
+	// A reset usually means the old password leaked, so every session signed in with it ends.
+	if err := s.auth.DeleteUserTokens(ctx, userID, ""); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to revoke sessions after password reset")
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) SendEmailVerification(ctx context.Context, email string) error {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		// Respond the same way for unknown emails so accounts can't be enumerated.
+		log.Ctx(ctx).Warn().Err(err).Msg("⚠️ email verification requested for unknown email")
+		return nil
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := s.sendEmailVerification(ctx, user); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to send verification email")
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.oneTimeTokens.Consume(ctx, auth.EmailVerification, token)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 invalid email verification token")
+		return errs.New(http.StatusBadRequest, err, "invalid or expired token")
+	}
+
+	if err := s.repo.MarkEmailVerified(ctx, userID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to mark email verified")
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) ValidatePassword(email string, newPassword string) error {
+	if err := s.policy.Validate(newPassword, email); err != nil {
+		return errs.New(http.StatusBadRequest, err, err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) OIDCAuthURL(ctx context.Context, name string) (string, error) {
+	provider, ok := s.oidcProviders[name]
+	if !ok {
+		return "", errs.New(http.StatusNotFound, fmt.Errorf("unknown provider %q", name), "unknown provider")
+	}
+
+	state := oidc.NewLoginState(name)
+	if err := s.oidcStates.Save(ctx, state, OIDCStateTTL); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to save oidc state")
+		return "", err
+	}
+
+	return provider.AuthCodeURL(state), nil
+}
+
+func (s *service) OIDCCallback(ctx context.Context, name string, state string, code string) (*LoginResult, error) {
+	provider, ok := s.oidcProviders[name]
+	if !ok {
+		return nil, errs.New(http.StatusNotFound, fmt.Errorf("unknown provider %q", name), "unknown provider")
+	}
+
+	loginState, err := s.oidcStates.Take(ctx, state)
+	if err != nil || loginState.Provider != name {
+		log.Ctx(ctx).Error().Err(err).Str("provider", name).Msg("🚨 invalid oidc state")
+		err := errs.New(http.StatusBadRequest, fmt.Errorf("invalid or expired state"), "invalid or expired state")
+		s.recordLogin(ctx, audit.LoginOIDC, "", err, name+": invalid state")
+		return nil, err
+	}
+
+	claims, err := provider.Exchange(ctx, code, loginState)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("provider", name).Msg("🚨 failed to exchange oidc code")
+		err := errs.New(http.StatusUnauthorized, err, "failed to verify identity")
+		s.recordLogin(ctx, audit.LoginOIDC, "", err, name+": invalid code")
+		return nil, err
+	}
+
+	user, err := s.userForIdentity(ctx, name, claims)
+	if err != nil {
+		s.recordLogin(ctx, audit.LoginOIDC, "", err, name)
+		return nil, err
+	}
+
+	if err := ensureActive(ctx, user); err != nil {
+		s.recordLogin(ctx, audit.LoginOIDC, user.ID.String(), err, name+": account disabled")
+		return nil, err
+	}
+
+	result, err := s.completeLogin(ctx, user)
+	s.recordLogin(ctx, audit.LoginOIDC, user.ID.String(), err, strings.TrimSpace(name+" "+secondFactorDetails(result)))
+	return result, err
+}
+
+// userForIdentity returns the user linked to an external identity. Unknown identities are linked
+// to the user with the same email, or to a new user, but only when the provider verified the email.
+func (s *service) userForIdentity(ctx context.Context, provider string, claims *oidc.Claims) (*model.User, error) {
+	identity, err := s.repo.GetIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		return s.repo.GetByID(ctx, identity.UserID.String())
+	}
+
+	if !errs.IsNotFound(err) {
+		log.Ctx(ctx).Error().Err(err).Str("provider", provider).Msg("🚨 failed to get identity")
+		return nil, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		log.Ctx(ctx).Error().Str("provider", provider).Msg("🚨 provider did not return a verified email")
+		return nil, errs.New(http.StatusForbidden, fmt.Errorf("email is not verified by %s", provider), "email is not verified by provider")
+	}
+
+	user, err := s.repo.GetByEmail(ctx, claims.Email)
+	switch {
+	case errs.IsNotFound(err):
+		now := time.Now()
+		user = &model.User{Email: claims.Email, EmailVerifiedAt: &now}
+		if err := s.createUser(ctx, user); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		log.Ctx(ctx).Error().Err(err).Str("email", claims.Email).Msg("🚨 failed to get user by email")
+		return nil, err
+	case user.EmailVerifiedAt == nil:
+		// Linking to an unverified account would hand it to whoever registered the address first.
+		log.Ctx(ctx).Error().Str("email", claims.Email).Msg("🚨 refusing to link unverified account")
+		return nil, errs.New(http.StatusConflict, fmt.Errorf("account email is not verified"), "verify your email before signing in with "+provider)
+	}
+
+	err = s.repo.CreateIdentity(ctx, &model.UserIdentity{
+		ID:       uuid.New(),
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("provider", provider).Msg("🚨 failed to link identity")
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *service) GetRoles(ctx context.Context, userID string) ([]auth.Role, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.enforcer.GetRolesForUser(userID)
 	if err != nil {
-		log.Error().Err(err).Msg("🚨 failed to create token")
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to get roles")
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (s *service) GrantRole(ctx context.Context, userID string, role auth.Role) error {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.enforcer.AddRoleForUser(ctx, userID, role); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Str("role", role.String()).Msg("🚨 failed to grant role")
+		return err
+	}
+
+	log.Ctx(ctx).Info().Str("user_id", userID).Str("role", role.String()).Msg("🔐 granted role")
+	return nil
+}
+
+func (s *service) RevokeRole(ctx context.Context, actorID string, userID string, role auth.Role) error {
+	// Admins revoking their own role could leave nobody able to grant it back.
+	if actorID == userID && role == auth.Admin {
+		return errs.New(http.StatusConflict, fmt.Errorf("admin %s tried to revoke own admin role", actorID), "you cannot revoke your own admin role")
+	}
+
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.enforcer.DeleteRoleForUser(ctx, userID, role); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Str("role", role.String()).Msg("🚨 failed to revoke role")
+		return err
+	}
+
+	log.Ctx(ctx).Info().Str("user_id", userID).Str("role", role.String()).Msg("🔐 revoked role")
+	return nil
+}
+
+func (s *service) GetProfile(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *service) UpdateProfile(ctx context.Context, userID string, update *ProfileUpdate) (*model.User, error) {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return nil, err
+	}
+
+	if update.DisplayName != nil {
+		name := strings.TrimSpace(*update.DisplayName)
+		if utf8.RuneCountInString(name) > MaxDisplayNameLength || strings.ContainsFunc(name, unicode.IsControl) {
+			return nil, errs.New(http.StatusBadRequest, fmt.Errorf("invalid display name"), fmt.Sprintf("display name must be at most %d printable characters", MaxDisplayNameLength))
+		}
+		user.DisplayName = name
+	}
+
+	if update.AvatarURL != nil {
+		if err := validateAvatarURL(*update.AvatarURL); err != nil {
+			return nil, errs.New(http.StatusBadRequest, err, err.Error())
+		}
+		user.AvatarURL = *update.AvatarURL
+	}
+
+	if update.Locale != nil {
+		locale := strings.ReplaceAll(strings.TrimSpace(*update.Locale), "_", "-")
+		if locale != "" && !localePattern.MatchString(locale) {
+			return nil, errs.New(http.StatusBadRequest, fmt.Errorf("invalid locale %q", locale), "locale must be a language tag such as en or pt-BR")
+		}
+		user.Locale = locale
+	}
+
+	if update.Preferences != nil {
+		raw, err := json.Marshal(update.Preferences)
+		if err != nil || len(raw) > MaxPreferencesSize {
+			return nil, errs.New(http.StatusBadRequest, fmt.Errorf("invalid preferences"), fmt.Sprintf("preferences must be a JSON object of at most %d bytes", MaxPreferencesSize))
+		}
+		user.Preferences = update.Preferences
+	}
+
+	if err := s.repo.UpdateProfile(ctx, user); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to update profile")
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces the password and signs out every other session of the user.
+func (s *service) ChangePassword(ctx context.Context, metadata *auth.AccessProperties, currentPassword string, newPassword string) error {
+	user, err := s.repo.GetByID(ctx, metadata.UserID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return err
+	}
+
+	if err := s.confirmPassword(ctx, user, currentPassword); err != nil {
+		s.recorder.Record(ctx, &model.AuditEvent{Type: audit.PasswordChange, Outcome: audit.Failure, Target: user.ID.String(), Details: "invalid current password"})
+		return err
+	}
+
+	if err := s.ValidatePassword(user.Email, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwords.Hash(ctx, newPassword)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to hash password")
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, metadata.UserID, hashedPassword); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to update password")
+		return err
+	}
+
+	if err := s.auth.DeleteUserTokens(ctx, metadata.UserID, metadata.TokenUUID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to revoke other sessions")
+		return err
+	}
+
+	if err := s.loginAttempts.Reset(ctx, user.Email); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to reset login attempts")
+	}
+
+	if err := s.mailer.Send(ctx, passwordChangedMessage(user.Email)); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("⚠️ failed to send password changed email")
+	}
+
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.PasswordChange, Outcome: audit.Success, Target: user.ID.String()})
+	return nil
+}
+
+// ChangeEmail sends a confirmation link to the new address. The email only changes once it is confirmed.
+func (s *service) ChangeEmail(ctx context.Context, userID string, password string, email string) error {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return err
+	}
+
+	if err := s.confirmPassword(ctx, user, password); err != nil {
+		s.recorder.Record(ctx, &model.AuditEvent{Type: audit.EmailChange, Outcome: audit.Failure, Target: user.ID.String(), Details: "invalid password"})
+		return err
+	}
+
+	if email == user.Email {
+		return errs.New(http.StatusBadRequest, fmt.Errorf("email is unchanged"), "new email must differ from the current one")
+	}
+
+	if err := s.ensureEmailAvailable(ctx, email); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetPendingEmail(ctx, userID, email); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to set pending email")
+		return err
+	}
+
+	token, err := s.oneTimeTokens.Issue(ctx, auth.EmailChange, userID, EmailChangeTTL)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to issue email change token")
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, emailChangeMessage(s.appURL, email, token)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to send email change email")
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, emailChangeNoticeMessage(user.Email, email)); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("⚠️ failed to send email change notice")
+	}
+
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.EmailChange, Outcome: audit.Success, Target: user.ID.String(), Details: "requested"})
+	return nil
+}
+
+// ConfirmEmailChange replaces the email with the pending one the token was sent to.
+func (s *service) ConfirmEmailChange(ctx context.Context, token string) error {
+	userID, err := s.oneTimeTokens.Consume(ctx, auth.EmailChange, token)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 invalid email change token")
+		return errs.New(http.StatusBadRequest, err, "invalid or expired token")
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return err
+	}
+
+	if user.PendingEmail == "" {
+		return errs.New(http.StatusBadRequest, fmt.Errorf("no pending email"), "invalid or expired token")
+	}
+
+	// The address may have been taken by a registration since the change was requested.
+	if err := s.ensureEmailAvailable(ctx, user.PendingEmail); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateEmail(ctx, userID, user.PendingEmail); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to update email")
+		return err
+	}
+
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.EmailChange, Outcome: audit.Success, ActorID: userID, Target: userID, Details: "confirmed"})
+	return nil
+}
+
+// DeleteAccount removes the user with their roles, credentials and sessions, the policies of their API keys,
+// and the policies and tokens of their OAuth2 clients.
+// The store has no reviews or orders yet; once it does they must be anonymised here, not deleted.
+func (s *service) DeleteAccount(ctx context.Context, metadata *auth.AccessProperties, password string) error {
+	user, err := s.repo.GetByID(ctx, metadata.UserID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by id")
+		return err
+	}
+
+	if err := s.confirmPassword(ctx, user, password); err != nil {
+		s.recorder.Record(ctx, &model.AuditEvent{Type: audit.AccountDelete, Outcome: audit.Failure, Details: "invalid password"})
+		return err
+	}
+
+	// The key and client rows go with the user, so their IDs are needed beforehand to revoke their access.
+	keyIDs, err := s.repo.ListAPIKeyIDs(ctx, metadata.UserID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list api keys")
+		return err
+	}
+
+	clientIDs, err := s.repo.ListOAuthClientIDs(ctx, metadata.UserID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list oauth clients")
+		return err
+	}
+
+	// Delete the user before revoking anything, so a failed delete leaves the account working.
+	if err := s.repo.Delete(ctx, metadata.UserID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to delete user")
+		return err
+	}
+
+	s.revokeAccess(ctx, metadata.UserID, keyIDs, clientIDs)
+
+	if err := s.auth.DeleteUserTokens(ctx, metadata.UserID, ""); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to revoke sessions of deleted user")
+	}
+
+	// The email is personal data, so the event only keeps the pseudonymous user ID.
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.AccountDelete, Outcome: audit.Success})
+	log.Ctx(ctx).Info().Str("user_id", metadata.UserID).Msg("🔐 deleted account")
+	return nil
+}
+
+// revokeAccess removes the roles and policies of a deleted user, the policies of their API keys, and the
+// policies and tokens of their OAuth2 clients. The account is already gone, so failures are logged and the
+// remaining rules still revoked.
+func (s *service) revokeAccess(ctx context.Context, userID string, keyIDs []string, clientIDs []string) {
+	roles, err := s.enforcer.GetRolesForUser(userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get roles of deleted user")
+	}
+
+	for _, role := range roles {
+		if err := s.enforcer.DeleteRoleForUser(ctx, userID, role); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("role", role.String()).Msg("🚨 failed to revoke role of deleted user")
+		}
+	}
+
+	subjects := []string{userID}
+	for _, id := range keyIDs {
+		subjects = append(subjects, auth.APIKeySubject(id))
+	}
+	for _, id := range clientIDs {
+		subjects = append(subjects, auth.ClientSubject(id))
+
+		// Tokens issued to the client, on behalf of users or its own, are linked to its subject.
+		if err := s.auth.DeleteUserTokens(ctx, auth.ClientSubject(id), ""); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("client_id", id).Msg("🚨 failed to revoke client tokens of deleted user")
+		}
+	}
+
+	for _, subject := range subjects {
+		policies, err := s.enforcer.GetPolicies(auth.Policy{Subject: subject})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("subject", subject).Msg("🚨 failed to get policies of deleted user")
+			continue
+		}
+
+		for _, policy := range policies {
+			if err := s.enforcer.RemovePolicy(ctx, policy.Subject, policy.Object, policy.Action); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("subject", subject).Msg("🚨 failed to revoke policy of deleted user")
+			}
+		}
+	}
+}
+
+// ListUsers returns a page of the users matching filter and how many match in total.
+func (s *service) ListUsers(ctx context.Context, filter *UserFilter) ([]model.User, int64, error) {
+	if filter.Status != "" && filter.Status != model.UserActive && filter.Status != model.UserDisabled {
+		return nil, 0, errs.New(http.StatusBadRequest, fmt.Errorf("unknown status %q", filter.Status), "status must be active or disabled")
+	}
+
+	if filter.Limit < 0 || filter.Limit > MaxPageSize {
+		return nil, 0, errs.New(http.StatusBadRequest, fmt.Errorf("invalid limit %d", filter.Limit), fmt.Sprintf("limit must be between 1 and %d", MaxPageSize))
+	}
+
+	if filter.Offset < 0 {
+		return nil, 0, errs.New(http.StatusBadRequest, fmt.Errorf("invalid offset %d", filter.Offset), "offset must not be negative")
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	users, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to list users")
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (s *service) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, errs.New(http.StatusNotFound, err, "user not found")
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to get user")
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DisableUser blocks the user from signing in and signs out all of their sessions, revoking the tokens of
+// their OAuth2 clients too. The clients stay registered but cannot authenticate while the user is disabled.
+func (s *service) DisableUser(ctx context.Context, actorID string, userID string) error {
+	// Admins disabling themselves could leave nobody able to enable them back.
+	if actorID == userID {
+		return errs.New(http.StatusConflict, fmt.Errorf("admin %s tried to disable own account", actorID), "you cannot disable your own account")
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateStatus(ctx, userID, model.UserDisabled); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to disable user")
+		return err
+	}
+
+	if err := s.auth.DeleteUserTokens(ctx, userID, ""); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to revoke sessions of disabled user")
+		return err
+	}
+
+	clientIDs, err := s.repo.ListOAuthClientIDs(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to list oauth clients of disabled user")
+		return err
+	}
+
+	for _, id := range clientIDs {
+		if err := s.auth.DeleteUserTokens(ctx, auth.ClientSubject(id), ""); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("client_id", id).Msg("🚨 failed to revoke client tokens of disabled user")
+			return err
+		}
+	}
+
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.AccountDisable, Outcome: audit.Success, Target: user.ID.String()})
+	log.Ctx(ctx).Info().Str("user_id", userID).Msg("🔐 disabled account")
+	return nil
+}
+
+func (s *service) EnableUser(ctx context.Context, userID string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateStatus(ctx, userID, model.UserActive); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to enable user")
+		return err
+	}
+
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.AccountEnable, Outcome: audit.Success, Target: user.ID.String()})
+	log.Ctx(ctx).Info().Str("user_id", userID).Msg("🔐 enabled account")
+	return nil
+}
+
+// ForcePasswordReset refuses password logins until the user resets their password with the
+// link mailed to them, and signs out all of their sessions.
+func (s *service) ForcePasswordReset(ctx context.Context, userID string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SetPasswordResetRequired(ctx, userID, true); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to require password reset")
+		return err
+	}
+
+	if err := s.auth.DeleteUserTokens(ctx, userID, ""); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to revoke sessions")
+		return err
+	}
+
+	token, err := s.oneTimeTokens.Issue(ctx, auth.PasswordReset, userID, ForcedPasswordResetTTL)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to issue password reset token")
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, forcedPasswordResetMessage(s.appURL, user.Email, token)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to send password reset email")
+		return err
+	}
+
+	s.recorder.Record(ctx, &model.AuditEvent{Type: audit.PasswordForce, Outcome: audit.Success, Target: user.ID.String()})
+	return nil
+}
+
+// confirmPassword re-authenticates the user before a sensitive change. Users who only sign in
+// with a social login have no password, they are trusted on their current session alone.
+func (s *service) confirmPassword(ctx context.Context, user *model.User, password string) error {
+	if user.HashedPassword == "" {
+		return nil
+	}
+
+	if ok, _ := s.passwords.Compare(ctx, password, user.HashedPassword); !ok {
+		log.Ctx(ctx).Error().Str("user_id", user.ID.String()).Msg("🚨 invalid password")
+		return errs.New(http.StatusUnauthorized, fmt.Errorf("invalid password"), "password is incorrect")
+	}
+
+	return nil
+}
+
+// ensureEmailAvailable returns a conflict error when another user already has the email.
+func (s *service) ensureEmailAvailable(ctx context.Context, email string) error {
+	_, err := s.repo.GetByEmail(ctx, email)
+	if err == nil {
+		return errs.New(http.StatusConflict, fmt.Errorf("email is already in use"), "email is already in use")
+	}
+
+	if !errs.IsNotFound(err) {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get user by email")
+		return err
+	}
+
+	return nil
+}
+
+// validateAvatarURL accepts an empty string, to clear the avatar, or an absolute http(s) URL.
+func validateAvatarURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || len(raw) > MaxAvatarURLLength || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("avatar url must be an http or https URL of at most %d characters", MaxAvatarURLLength)
+	}
+
+	return nil
+}
+
+// ensureActive refuses users disabled by an administrator.
+func ensureActive(ctx context.Context, user *model.User) error {
+	if user.Disabled() {
+		log.Ctx(ctx).Error().Str("user_id", user.ID.String()).Msg("🚨 account is disabled")
+		return errs.New(http.StatusForbidden, fmt.Errorf("account is disabled"), "account is disabled")
+	}
+
+	return nil
+}
+
+// ensureUser returns a not found error unless the user exists.
+func (s *service) ensureUser(ctx context.Context, userID string) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		return errs.New(http.StatusNotFound, err, "user not found")
+	}
+
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		if !errs.IsNotFound(err) {
+			log.Ctx(ctx).Error().Err(err).Str("user_id", userID).Msg("🚨 failed to get user")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// sendEmailVerification issues a verification token and mails it to the user.
+func (s *service) sendEmailVerification(ctx context.Context, user *model.User) error {
+	token, err := s.oneTimeTokens.Issue(ctx, auth.EmailVerification, user.ID.String(), EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, emailVerificationMessage(s.appURL, user.Email, token))
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code. A TOTP code is
+// refused when it, or a later one, was accepted before, so a captured code cannot be replayed.
+func (s *service) verifySecondFactor(ctx context.Context, user *model.User, code string) error {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if step, ok := crypto.MatchTOTP(user.TOTPSecret, code, time.Now()); ok {
+		if err := s.repo.UseTOTPStep(ctx, user.ID.String(), step); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("user_id", user.ID.String()).Msg("🚨 totp code already used")
+			return err
+		}
+		return nil
+	}
+
+	recoveryCodes, err := s.repo.GetUnusedRecoveryCodes(ctx, user.ID.String())
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to get recovery codes")
+		return err
+	}
+
+	for _, recoveryCode := range recoveryCodes {
+		if ok, _ := s.passwords.Compare(ctx, code, recoveryCode.HashedCode); ok {
+			return s.repo.UseRecoveryCode(ctx, recoveryCode.ID)
+		}
+	}
+
+	log.Ctx(ctx).Error().Str("user_id", user.ID.String()).Msg("🚨 invalid totp code")
+	return errs.New(http.StatusUnauthorized, fmt.Errorf("invalid totp code"), "invalid code")
+}
+
+// rehashPassword upgrades a hash made with an outdated algorithm or parameters. Failures are
+// only logged, since the old hash still verifies and the next login will try again.
+func (s *service) rehashPassword(ctx context.Context, user *model.User, password string) {
+	hashedPassword, err := s.passwords.Hash(ctx, password)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to rehash password")
+		return
+	}
+
+	if err := s.repo.UpdatePassword(ctx, user.ID.String(), hashedPassword); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to persist rehashed password")
+		return
+	}
+
+	user.HashedPassword = hashedPassword
+	log.Ctx(ctx).Info().Str("user_id", user.ID.String()).Msg("🔐 upgraded password hash")
+}
+
+// checkLoginAttempts refuses the login while the account or client IP is backing off or locked.
+func (s *service) checkLoginAttempts(ctx context.Context, email, ip string) error {
+	err := s.loginAttempts.Check(ctx, email, ip)
+	if err == nil {
+		return nil
+	}
+
+	var blocked *auth.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to check login attempts")
+		return err
+	}
+
+	log.Ctx(ctx).Warn().Str("email", email).Str("ip", ip).Bool("locked", blocked.Locked).Msg("⚠️ login blocked")
+	if blocked.Locked {
+		return errs.New(http.StatusLocked, blocked, "too many failed attempts, login is temporarily locked")
+	}
+
+	return errs.New(http.StatusTooManyRequests, blocked, "too many failed attempts, try again later")
+}
+
+// failLogin records a failed login and returns the error for invalid credentials.
+// The same error is returned for unknown emails and wrong passwords.
+func (s *service) failLogin(ctx context.Context, email, ip string) error {
+	if err := s.loginAttempts.Fail(ctx, email, ip); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to record login attempt")
+	}
+
+	return errs.New(http.StatusUnauthorized, fmt.Errorf("invalid credentials"), "invalid email or password")
+}
+
+// createUser stores a new user and assigns it the default role.
+func (s *service) createUser(ctx context.Context, user *model.User) error {
+	user.ID = uuid.New()
+	err := s.repo.Create(ctx, user)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("email", user.Email).Msg("🚨 failed to create user")
+		return err
+	}
+
+	if err := s.enforcer.AddRoleForUser(ctx, user.ID.String(), auth.DefaultRole); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("email", user.Email).Msg("🚨 failed to add role")
+		return err
+	}
+
+	return nil
+}
+
+// recordLogin records a login attempt. userID is empty when the user is not known; the attempted email
+// is personal data and never recorded.
+func (s *service) recordLogin(ctx context.Context, eventType string, userID string, err error, details string) {
+	s.recorder.Record(ctx, &model.AuditEvent{
+		Type:    eventType,
+		Outcome: audit.OutcomeOf(err),
+		ActorID: userID,
+		Target:  userID,
+		Details: details,
+	})
+}
+
+// secondFactorDetails notes when a successful password step still awaits the second factor.
+func secondFactorDetails(result *LoginResult) string {
+	if result != nil && result.ChallengeToken != "" {
+		return "second factor required"
+	}
+
+	return ""
+}
+
+// actorOf returns the ID of a user created by a successful registration.
+func actorOf(user *model.User, err error) string {
+	if err != nil {
+		return ""
+	}
+
+	return user.ID.String()
+}
+
+// completeLogin issues tokens for an authenticated user, or a challenge token when a second factor is required.
+func (s *service) completeLogin(ctx context.Context, user *model.User) (*LoginResult, error) {
+	if user.TOTPEnabled {
+		challengeToken, err := s.oneTimeTokens.Issue(ctx, auth.LoginChallenge, user.ID.String(), auth.ChallengeTokenTTL)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to create challenge token")
+			return nil, err
+		}
+
+		return &LoginResult{ChallengeToken: challengeToken}, nil
+	}
+
+	accessToken, refreshToken, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// issueTokens creates and stores a new access/refresh token pair for the user.
+func (s *service) issueTokens(ctx context.Context, user *model.User) (string, string, error) {
+	ts, err := s.tokenManager.CreateToken(user.ID.String(), user.Email)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to create token")
+		return "", "", err
+	}
+
+	if err := s.auth.CreateAuth(ctx, user.ID.String(), ts); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("🚨 failed to create auth")
 		return "", "", err
 	}
 
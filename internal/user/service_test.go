@@ -2,19 +2,37 @@ package user_test
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	"github.com/chai-rs/simple-bookstore/infrastructure/mailer"
+	"github.com/chai-rs/simple-bookstore/infrastructure/oidc"
+	"github.com/chai-rs/simple-bookstore/infrastructure/oidc/oidctest"
+	"github.com/chai-rs/simple-bookstore/internal/audit"
 	errs "github.com/chai-rs/simple-bookstore/internal/error"
+	"github.com/chai-rs/simple-bookstore/internal/middleware"
 	"github.com/chai-rs/simple-bookstore/internal/model"
 	"github.com/chai-rs/simple-bookstore/internal/user"
+	"github.com/chai-rs/simple-bookstore/internal/utils"
 	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.openly.dev/pointy"
 	"gorm.io/gorm"
 )
 
+// tokenManager signs and verifies the tokens of the tests.
+var tokenManager = auth.NewTokenManager(auth.NewKeys("access-secret", "refresh-secret"))
+
 func TestService_Login(t *testing.T) {
 	type TestcaseIn struct {
 		Email    string
@@ -22,9 +40,10 @@ func TestService_Login(t *testing.T) {
 	}
 
 	type Testcase struct {
-		Name      string
-		In        TestcaseIn
-		WantError bool
+		Name        string
+		In          TestcaseIn
+		WantError   bool
+		WantDetails string
 	}
 
 	testcases := []Testcase{
@@ -41,7 +60,8 @@ func TestService_Login(t *testing.T) {
 				Email:    "invalid@example.com",
 				Password: "password",
 			},
-			WantError: true,
+			WantError:   true,
+			WantDetails: "unknown email",
 		},
 		{
 			Name: "invalid-password",
@@ -49,7 +69,8 @@ func TestService_Login(t *testing.T) {
 				Email:    "one@example.com",
 				Password: "invalid-password",
 			},
-			WantError: true,
+			WantError:   true,
+			WantDetails: "invalid password",
 		},
 	}
 
@@ -60,26 +81,41 @@ func TestService_Login(t *testing.T) {
 		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
 	}, nil)
 	repo.EXPECT().GetByEmail(mock.Anything, "invalid@example.com").Return(nil, errs.FromGorm(gorm.ErrRecordNotFound))
+	// The bcrypt fixture is upgraded to the default Argon2id hasher on the first successful login.
+	repo.EXPECT().UpdatePassword(mock.Anything, "123e4567-e89b-12d3-a456-426614174000", mock.Anything).RunAndReturn(func(ctx context.Context, id string, hashedPassword string) error {
+		assert.True(t, strings.HasPrefix(hashedPassword, "$argon2id$"))
+		return nil
+	}).Once()
 
 	for _, tc := range testcases {
 		t.Run(tc.Name, func(t *testing.T) {
 			ctx := context.Background()
 
-			tokenManager := auth.NewTokenManager()
 			enforcer := auth.NewMockAuthEnforcer(t)
 			memoryAuth := auth.NewMemoryAuth()
 
-			svc := user.NewService(repo, memoryAuth, tokenManager, enforcer)
-			accessToken, _, err := svc.Login(ctx, tc.In.Email, tc.In.Password)
+			recorder := audit.NewMemoryRecorder()
+			ctx = utils.WithClientInfo(ctx, utils.ClientInfo{IP: "203.0.113.1", RequestID: "request-" + tc.Name})
+
+			svc := user.NewService(repo, memoryAuth, tokenManager, enforcer, &user.ServiceOpts{Recorder: recorder})
+			result, err := svc.Login(ctx, tc.In.Email, tc.In.Password)
+
+			events := recorder.Events()
+			if assert.Len(t, events, 1) {
+				assert.Equal(t, audit.Login, events[0].Type)
+				assert.Equal(t, audit.OutcomeOf(err), events[0].Outcome)
+				assert.Equal(t, events[0].ActorID, events[0].Target, "the target is the user ID, never the email")
+				assert.Equal(t, tc.WantDetails, events[0].Details)
+				assert.Equal(t, "203.0.113.1", events[0].IP)
+				assert.Equal(t, "request-"+tc.Name, events[0].RequestID)
+			}
 
 			if tc.WantError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				token, err := auth.VerifyToken(accessToken)
-				assert.NoError(t, err)
-
-				tokenProperties, err := auth.Extract(token)
+				assert.Empty(t, result.ChallengeToken)
+				tokenProperties, err := tokenManager.VerifyAccessToken(result.AccessToken)
 				assert.NoError(t, err)
 
 				_, err = memoryAuth.FetchAuth(ctx, tokenProperties.TokenUUID)
@@ -89,37 +125,69 @@ func TestService_Login(t *testing.T) {
 	}
 }
 
+// countingHasher counts the passwords verified by the hasher it wraps.
+type countingHasher struct {
+	crypto.Hasher
+	verified int
+}
+
+func (h *countingHasher) Verify(password, encoded string) (bool, error) {
+	h.verified++
+	return h.Hasher.Verify(password, encoded)
+}
+
+func TestService_Login_UnknownEmail(t *testing.T) {
+	hasher := &countingHasher{Hasher: crypto.NewBcryptHasher(4)}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "invalid@example.com").Return(nil, errs.FromGorm(gorm.ErrRecordNotFound))
+
+	// The dummy password compared against for unknown emails is hashed with the configured hasher,
+	// so it costs as much as a real one.
+	svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, auth.NewMockAuthEnforcer(t), &user.ServiceOpts{
+		PasswordHasher: crypto.NewPasswordHasher(hasher),
+	})
+	_, err := svc.Login(context.Background(), "invalid@example.com", "password")
+	assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(err))
+	assert.Equal(t, 1, hasher.verified)
+}
+
 func TestService_Register(t *testing.T) {
 	type Testcase struct {
-		Name      string
-		In        *model.User
-		WantError bool
+		Name       string
+		Email      string
+		Password   string
+		WantStatus int
 	}
 
 	testcases := []Testcase{
 		{
-			Name: "success",
-			In: &model.User{
-				Email:          "one@example.com",
-				HashedPassword: crypto.MustHashPassword("password"),
-			},
+			Name:     "success",
+			Email:    "one@example.com",
+			Password: "Correct-Horse-Battery-42",
 		},
 		{
-			Name: "duplicate",
-			In: &model.User{
-				Email:          "duplicate@example.com",
-				HashedPassword: crypto.MustHashPassword("password"),
-			},
-			WantError: true,
+			Name:       "duplicate",
+			Email:      "duplicate@example.com",
+			Password:   "Correct-Horse-Battery-42",
+			WantStatus: http.StatusConflict,
+		},
+		{
+			Name:       "weak-password",
+			Email:      "two@example.com",
+			Password:   "password",
+			WantStatus: http.StatusBadRequest,
 		},
 	}
 
+	created := map[string]*model.User{}
 	repo := user.NewMockRepository(t)
 	repo.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, user *model.User) error {
 		switch user.Email {
 		case "duplicate@example.com":
 			return errs.FromGorm(gorm.ErrDuplicatedKey)
 		default:
+			created[user.Email] = user
 			return nil
 		}
 	})
@@ -129,28 +197,28 @@ func TestService_Register(t *testing.T) {
 			ctx := context.Background()
 
 			enforcer := auth.NewMockAuthEnforcer(t)
-			enforcer.EXPECT().AddPolicy(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+			enforcer.EXPECT().AddRoleForUser(mock.Anything, mock.Anything, auth.DefaultRole).Return(nil).Maybe()
 
-			tokenManager := auth.NewTokenManager()
 			memoryAuth := auth.NewMemoryAuth()
 
 			svc := user.NewService(repo, memoryAuth, tokenManager, enforcer)
-			accessToken, _, err := svc.Register(ctx, tc.In)
+			accessToken, _, err := svc.Register(ctx, tc.Email, tc.Password)
 
-			if tc.WantError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				token, err := auth.VerifyToken(accessToken)
-				assert.NoError(t, err)
+			if tc.WantStatus != 0 {
+				assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+				return
+			}
 
-				tokenProperties, err := auth.Extract(token)
-				assert.NoError(t, err)
+			assert.NoError(t, err)
+			ok, _ := crypto.ComparePassword(tc.Password, created[tc.Email].HashedPassword)
+			assert.True(t, ok)
 
-				userId, err := memoryAuth.FetchAuth(ctx, tokenProperties.TokenUUID)
-				assert.NoError(t, err)
-				assert.Equal(t, tc.In.ID.String(), userId)
-			}
+			tokenProperties, err := tokenManager.VerifyAccessToken(accessToken)
+			assert.NoError(t, err)
+
+			userId, err := memoryAuth.FetchAuth(ctx, tokenProperties.TokenUUID)
+			assert.NoError(t, err)
+			assert.Equal(t, created[tc.Email].ID.String(), userId)
 		})
 	}
 }
@@ -164,21 +232,18 @@ func TestService_Logout(t *testing.T) {
 		Email:          "one@example.com",
 		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
 	}, nil)
+	repo.EXPECT().UpdatePassword(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 
-	tokenManager := auth.NewTokenManager()
 	memoryAuth := auth.NewMemoryAuth()
 
 	enforcer := auth.NewMockAuthEnforcer(t)
-	enforcer.EXPECT().AddPolicy(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	enforcer.EXPECT().AddRoleForUser(mock.Anything, mock.Anything, auth.DefaultRole).Return(nil).Maybe()
 
 	svc := user.NewService(repo, memoryAuth, tokenManager, enforcer)
-	accessToken, _, err := svc.Login(ctx, "one@example.com", "password")
-	assert.NoError(t, err)
-
-	token, err := auth.VerifyToken(accessToken)
+	result, err := svc.Login(ctx, "one@example.com", "password")
 	assert.NoError(t, err)
 
-	tokenProperties, err := auth.Extract(token)
+	tokenProperties, err := tokenManager.VerifyAccessToken(result.AccessToken)
 	assert.NoError(t, err)
 
 	assert.NoError(t, svc.Logout(ctx, tokenProperties))
@@ -186,3 +251,953 @@ func TestService_Logout(t *testing.T) {
 	_, err = memoryAuth.FetchAuth(ctx, tokenProperties.TokenUUID)
 	assert.Error(t, err)
 }
+
+func TestService_LoginTOTP(t *testing.T) {
+	type Testcase struct {
+		Name      string
+		Code      func() string
+		WantError bool
+	}
+
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	recoveryCodeID := uuid.New()
+
+	testcases := []Testcase{
+		{
+			Name: "totp",
+			Code: func() string {
+				code, _ := crypto.GenerateTOTP(secret, time.Now())
+				return code
+			},
+		},
+		{
+			Name: "recovery-code",
+			Code: func() string { return "abcd-efgh" },
+		},
+		{
+			Name:      "invalid-code",
+			Code:      func() string { return "000000" },
+			WantError: true,
+		},
+	}
+
+	u := &model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+		TOTPSecret:     secret,
+		TOTPEnabled:    true,
+	}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(u, nil)
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(u, nil)
+	repo.EXPECT().GetUnusedRecoveryCodes(mock.Anything, userID.String()).Return([]model.RecoveryCode{
+		{ID: recoveryCodeID, UserID: userID, HashedCode: crypto.MustHashPassword("abcd-efgh")},
+	}, nil).Maybe()
+	repo.EXPECT().UseRecoveryCode(mock.Anything, recoveryCodeID).Return(nil).Maybe()
+	repo.EXPECT().UpdatePassword(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	var lastStep int64
+	repo.EXPECT().UseTOTPStep(mock.Anything, userID.String(), mock.Anything).RunAndReturn(func(ctx context.Context, id string, step int64) error {
+		if step <= lastStep {
+			return errs.New(http.StatusUnauthorized, gorm.ErrRecordNotFound, "code already used")
+		}
+		lastStep = step
+		return nil
+	}).Maybe()
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := context.Background()
+
+			enforcer := auth.NewMockAuthEnforcer(t)
+			memoryAuth := auth.NewMemoryAuth()
+
+			svc := user.NewService(repo, memoryAuth, tokenManager, enforcer)
+			result, err := svc.Login(ctx, "one@example.com", "password")
+			assert.NoError(t, err)
+			assert.Empty(t, result.AccessToken)
+			assert.NotEmpty(t, result.ChallengeToken)
+
+			_, err = tokenManager.VerifyAccessToken(result.ChallengeToken)
+			assert.Error(t, err)
+
+			accessToken, _, err := svc.LoginTOTP(ctx, result.ChallengeToken, tc.Code())
+			if tc.WantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				_, err := tokenManager.VerifyAccessToken(accessToken)
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// Each case starts a service of its own without backoff, so a wrong code can be followed by the right one.
+	login := func(t *testing.T) (user.Service, string) {
+		svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, auth.NewMockAuthEnforcer(t), &user.ServiceOpts{
+			LoginAttempts: auth.NewMemoryLoginAttempts(&auth.LockoutPolicy{MaxAttempts: 5, IPMaxAttempts: 20, Window: time.Minute}),
+		})
+		result, err := svc.Login(context.Background(), "one@example.com", "password")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return svc, result.ChallengeToken
+	}
+
+	t.Run("replayed-totp", func(t *testing.T) {
+		svc, challengeToken := login(t)
+		code, _ := crypto.GenerateTOTP(secret, time.Now())
+		_, _, err := svc.LoginTOTP(context.Background(), challengeToken, code)
+		assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(err), "a code accepted once must not be accepted again")
+	})
+
+	t.Run("reused-challenge", func(t *testing.T) {
+		svc, challengeToken := login(t)
+
+		_, _, err := svc.LoginTOTP(context.Background(), challengeToken, "000000")
+		assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(err))
+
+		_, _, err = svc.LoginTOTP(context.Background(), challengeToken, "abcd-efgh")
+		assert.NoError(t, err, "a mistyped code must not consume the challenge")
+
+		_, _, err = svc.LoginTOTP(context.Background(), challengeToken, "abcd-efgh")
+		assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(err), "a completed challenge must not be used again")
+	})
+}
+
+func TestService_ResetPassword(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(&model.User{
+		ID:    userID,
+		Email: "one@example.com",
+	}, nil)
+	repo.EXPECT().GetByEmail(mock.Anything, "invalid@example.com").Return(nil, errs.FromGorm(gorm.ErrRecordNotFound))
+	repo.EXPECT().UpdatePassword(mock.Anything, userID.String(), mock.Anything).RunAndReturn(func(ctx context.Context, id string, hashedPassword string) error {
+		ok, needsRehash := crypto.ComparePassword("Correct-Horse-Battery-42", hashedPassword)
+		assert.True(t, ok)
+		assert.False(t, needsRehash)
+		return nil
+	}).Once()
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(&model.User{
+		ID:    userID,
+		Email: "one@example.com",
+	}, nil).Times(2)
+
+	memoryAuth := auth.NewMemoryAuth()
+	memoryMailer := mailer.NewMemoryMailer()
+	svc := user.NewService(repo, memoryAuth, tokenManager, auth.NewMockAuthEnforcer(t), &user.ServiceOpts{
+		Mailer: memoryMailer,
+	})
+
+	// A session signed in with the old password.
+	session, err := tokenManager.CreateToken(userID.String(), "one@example.com")
+	assert.NoError(t, err)
+	assert.NoError(t, memoryAuth.CreateAuth(ctx, userID.String(), session))
+
+	assert.NoError(t, svc.ForgotPassword(ctx, "invalid@example.com"))
+	assert.Empty(t, memoryMailer.Messages())
+
+	assert.NoError(t, svc.ForgotPassword(ctx, "one@example.com"))
+	token := tokenFromMessage(t, memoryMailer.Last())
+
+	assert.Error(t, svc.ResetPassword(ctx, token+"x", "Correct-Horse-Battery-42"))
+	assert.Error(t, svc.ResetPassword(ctx, token, "password"), "weak passwords must be rejected")
+	assert.NoError(t, svc.ResetPassword(ctx, token, "Correct-Horse-Battery-42"))
+	assert.Error(t, svc.ResetPassword(ctx, token, "Correct-Horse-Battery-42"), "token must be single-use")
+
+	_, err = memoryAuth.FetchAuth(ctx, session.AccessTokenUUID)
+	assert.Error(t, err, "sessions must be revoked by a password reset")
+}
+
+func TestService_VerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	u := &model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+	}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().Create(mock.Anything, mock.Anything).Return(nil)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(u, nil)
+	repo.EXPECT().MarkEmailVerified(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, id string) error {
+		u.EmailVerifiedAt = pointy.Pointer(time.Now())
+		return nil
+	}).Once()
+	repo.EXPECT().UpdatePassword(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	enforcer := auth.NewMockAuthEnforcer(t)
+	enforcer.EXPECT().AddRoleForUser(mock.Anything, mock.Anything, auth.DefaultRole).Return(nil)
+
+	memoryMailer := mailer.NewMemoryMailer()
+	svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, enforcer, &user.ServiceOpts{
+		Mailer:                   memoryMailer,
+		RequireEmailVerification: true,
+	})
+
+	_, _, err := svc.Register(ctx, "one@example.com", "Correct-Horse-Battery-42")
+	assert.NoError(t, err)
+	token := tokenFromMessage(t, memoryMailer.Last())
+
+	_, err = svc.Login(ctx, "one@example.com", "password")
+	assert.Error(t, err)
+
+	assert.NoError(t, svc.VerifyEmail(ctx, token))
+	assert.Error(t, svc.VerifyEmail(ctx, token), "token must be single-use")
+
+	_, err = svc.Login(ctx, "one@example.com", "password")
+	assert.NoError(t, err)
+}
+
+func TestService_LoginLockout(t *testing.T) {
+	ctx := utils.WithClientInfo(context.Background(), utils.ClientInfo{IP: "10.0.0.1"})
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	u := &model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+	}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(u, nil)
+	repo.EXPECT().GetByEmail(mock.Anything, "invalid@example.com").Return(nil, errs.FromGorm(gorm.ErrRecordNotFound))
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(u, nil)
+	repo.EXPECT().UpdatePassword(mock.Anything, userID.String(), mock.Anything).RunAndReturn(func(ctx context.Context, id string, hashedPassword string) error {
+		u.HashedPassword = hashedPassword
+		return nil
+	})
+
+	memoryMailer := mailer.NewMemoryMailer()
+	svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, auth.NewMockAuthEnforcer(t), &user.ServiceOpts{
+		Mailer: memoryMailer,
+		LoginAttempts: auth.NewMemoryLoginAttempts(&auth.LockoutPolicy{
+			MaxAttempts:     3,
+			IPMaxAttempts:   10,
+			LockoutDuration: time.Minute,
+			Window:          time.Minute,
+		}),
+	})
+
+	// Unknown emails and wrong passwords must be indistinguishable.
+	_, unknownErr := svc.Login(ctx, "invalid@example.com", "password")
+	_, wrongErr := svc.Login(ctx, "one@example.com", "wrong-password")
+	assert.Equal(t, errs.StatusOf(unknownErr), errs.StatusOf(wrongErr))
+	assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(wrongErr))
+
+	for range 2 {
+		_, err := svc.Login(ctx, "one@example.com", "wrong-password")
+		assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(err))
+	}
+
+	_, err := svc.Login(ctx, "one@example.com", "password")
+	assert.Equal(t, http.StatusLocked, errs.StatusOf(err))
+
+	var blocked *auth.LoginBlockedError
+	assert.True(t, errors.As(err, &blocked))
+	assert.True(t, blocked.Locked)
+
+	assert.NoError(t, svc.ForgotPassword(ctx, "one@example.com"))
+	assert.NoError(t, svc.ResetPassword(ctx, tokenFromMessage(t, memoryMailer.Last()), "Correct-Horse-Battery-42"))
+
+	_, err = svc.Login(ctx, "one@example.com", "Correct-Horse-Battery-42")
+	assert.NoError(t, err)
+}
+
+func TestService_OIDCCallback(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		Identity   oidctest.Identity
+		WantStatus int
+	}
+
+	existingID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	linkedID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+	unverifiedID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174002")
+
+	testcases := []Testcase{
+		{
+			Name:     "new-user",
+			Identity: oidctest.Identity{Subject: "new", Email: "new@example.com", EmailVerified: true},
+		},
+		{
+			Name:     "link-by-verified-email",
+			Identity: oidctest.Identity{Subject: "existing", Email: "existing@example.com", EmailVerified: true},
+		},
+		{
+			Name:     "linked-identity",
+			Identity: oidctest.Identity{Subject: "linked", Email: "changed@example.com"},
+		},
+		{
+			Name:       "unverified-provider-email",
+			Identity:   oidctest.Identity{Subject: "unverified", Email: "existing@example.com"},
+			WantStatus: http.StatusForbidden,
+		},
+		{
+			Name:       "unverified-local-account",
+			Identity:   oidctest.Identity{Subject: "takeover", Email: "unverified@example.com", EmailVerified: true},
+			WantStatus: http.StatusConflict,
+		},
+	}
+
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+
+	provider, err := oidc.NewProvider(context.Background(), &oidc.ProviderConfig{
+		Name:         "stub",
+		IssuerURL:    server.URL,
+		ClientID:     server.ClientID,
+		ClientSecret: func() string { return server.ClientSecret },
+		RedirectURL:  "http://localhost/api/users/oauth/stub/callback",
+	})
+	assert.NoError(t, err)
+
+	notFound := errs.FromGorm(gorm.ErrRecordNotFound)
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetIdentity(mock.Anything, "stub", "linked").Return(&model.UserIdentity{UserID: linkedID, Provider: "stub", Subject: "linked"}, nil)
+	repo.EXPECT().GetIdentity(mock.Anything, "stub", mock.Anything).Return(nil, notFound)
+	repo.EXPECT().GetByID(mock.Anything, linkedID.String()).Return(&model.User{ID: linkedID, Email: "linked@example.com"}, nil)
+	repo.EXPECT().GetByEmail(mock.Anything, "new@example.com").Return(nil, notFound)
+	repo.EXPECT().GetByEmail(mock.Anything, "existing@example.com").Return(&model.User{
+		ID:              existingID,
+		Email:           "existing@example.com",
+		EmailVerifiedAt: pointy.Pointer(time.Now()),
+	}, nil)
+	repo.EXPECT().GetByEmail(mock.Anything, "unverified@example.com").Return(&model.User{ID: unverifiedID, Email: "unverified@example.com"}, nil)
+	repo.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, u *model.User) error {
+		assert.Equal(t, "new@example.com", u.Email)
+		assert.NotNil(t, u.EmailVerifiedAt)
+		return nil
+	}).Once()
+	repo.EXPECT().CreateIdentity(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, identity *model.UserIdentity) error {
+		assert.Contains(t, []string{"new", "existing"}, identity.Subject)
+		if identity.Subject == "existing" {
+			assert.Equal(t, existingID, identity.UserID)
+		}
+		return nil
+	}).Times(2)
+
+	enforcer := auth.NewMockAuthEnforcer(t)
+	enforcer.EXPECT().AddRoleForUser(mock.Anything, mock.Anything, auth.DefaultRole).Return(nil).Once()
+
+	svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, enforcer, &user.ServiceOpts{
+		OIDCProviders: map[string]*oidc.Provider{"stub": provider},
+	})
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := context.Background()
+			server.SetIdentity(tc.Identity)
+
+			authURL, err := svc.OIDCAuthURL(ctx, "stub")
+			assert.NoError(t, err)
+
+			code, state, err := server.Authorize(authURL)
+			assert.NoError(t, err)
+
+			result, err := svc.OIDCCallback(ctx, "stub", state, code)
+			if tc.WantStatus != 0 {
+				assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			_, err = tokenManager.VerifyAccessToken(result.AccessToken)
+			assert.NoError(t, err)
+
+			// The state is single use.
+			_, err = svc.OIDCCallback(ctx, "stub", state, code)
+			assert.Equal(t, http.StatusBadRequest, errs.StatusOf(err))
+		})
+	}
+
+	_, err = svc.OIDCAuthURL(context.Background(), "unknown")
+	assert.Equal(t, http.StatusNotFound, errs.StatusOf(err))
+}
+
+// tokenFromMessage extracts the token query parameter from the link in an email.
+func tokenFromMessage(t *testing.T, message *mailer.Message) string {
+	t.Helper()
+
+	if !assert.NotNil(t, message) {
+		t.FailNow()
+	}
+
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(message.Body)
+	if !assert.Len(t, match, 2) {
+		t.FailNow()
+	}
+
+	token, err := url.QueryUnescape(match[1])
+	assert.NoError(t, err)
+	return token
+}
+
+func TestService_RevokeRole(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		ActorID    string
+		UserID     string
+		Role       auth.Role
+		WantStatus int
+	}
+
+	adminID := "123e4567-e89b-12d3-a456-426614174000"
+	editorID := "123e4567-e89b-12d3-a456-426614174001"
+
+	testcases := []Testcase{
+		{
+			Name:    "success",
+			ActorID: adminID,
+			UserID:  editorID,
+			Role:    auth.Editor,
+		},
+		{
+			Name:       "own-admin-role",
+			ActorID:    adminID,
+			UserID:     adminID,
+			Role:       auth.Admin,
+			WantStatus: http.StatusConflict,
+		},
+		{
+			Name:       "unknown-user",
+			ActorID:    adminID,
+			UserID:     uuid.NewString(),
+			Role:       auth.Editor,
+			WantStatus: http.StatusNotFound,
+		},
+		{
+			Name:       "invalid-user-id",
+			ActorID:    adminID,
+			UserID:     "not-a-uuid",
+			Role:       auth.Editor,
+			WantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			repo := user.NewMockRepository(t)
+			repo.EXPECT().GetByID(mock.Anything, editorID).Return(&model.User{ID: uuid.MustParse(editorID)}, nil).Maybe()
+			repo.EXPECT().GetByID(mock.Anything, mock.Anything).Return(nil, errs.FromGorm(gorm.ErrRecordNotFound)).Maybe()
+
+			enforcer := auth.NewMockAuthEnforcer(t)
+			if tc.WantStatus == 0 {
+				enforcer.EXPECT().DeleteRoleForUser(mock.Anything, tc.UserID, tc.Role).Return(nil).Once()
+			}
+
+			svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, enforcer)
+			err := svc.RevokeRole(context.Background(), tc.ActorID, tc.UserID, tc.Role)
+
+			if tc.WantStatus != 0 {
+				var appErr *errs.AppError
+				assert.True(t, errors.As(err, &appErr))
+				assert.Equal(t, tc.WantStatus, appErr.Code)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_UpdateProfile(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		In         *user.ProfileUpdate
+		WantStatus int
+	}
+
+	testcases := []Testcase{
+		{
+			Name: "success",
+			In: &user.ProfileUpdate{
+				DisplayName: pointy.Pointer("  One  "),
+				AvatarURL:   pointy.Pointer("https://example.com/one.png"),
+				Locale:      pointy.Pointer("pt_BR"),
+				Preferences: map[string]any{"newsletter": true},
+			},
+		},
+		{
+			Name: "clear-fields",
+			In:   &user.ProfileUpdate{AvatarURL: pointy.Pointer(""), Locale: pointy.Pointer("")},
+		},
+		{
+			Name:       "display-name-too-long",
+			In:         &user.ProfileUpdate{DisplayName: pointy.Pointer(strings.Repeat("a", user.MaxDisplayNameLength+1))},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "display-name-control-character",
+			In:         &user.ProfileUpdate{DisplayName: pointy.Pointer("one\ntwo")},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "avatar-not-https",
+			In:         &user.ProfileUpdate{AvatarURL: pointy.Pointer("javascript:alert(1)")},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "invalid-locale",
+			In:         &user.ProfileUpdate{Locale: pointy.Pointer("not a locale")},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "preferences-too-large",
+			In:         &user.ProfileUpdate{Preferences: map[string]any{"blob": strings.Repeat("a", user.MaxPreferencesSize)}},
+			WantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+			repo := user.NewMockRepository(t)
+			repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(&model.User{
+				ID:        userID,
+				Email:     "one@example.com",
+				AvatarURL: "https://example.com/old.png",
+				Locale:    "en",
+			}, nil)
+			repo.EXPECT().UpdateProfile(mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, auth.NewMockAuthEnforcer(t))
+			u, err := svc.UpdateProfile(context.Background(), userID.String(), tc.In)
+
+			if tc.WantStatus != 0 {
+				assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			if tc.In.DisplayName != nil {
+				assert.Equal(t, strings.TrimSpace(*tc.In.DisplayName), u.DisplayName)
+			}
+			if tc.In.AvatarURL != nil {
+				assert.Equal(t, *tc.In.AvatarURL, u.AvatarURL)
+			}
+			if tc.In.Locale != nil {
+				assert.Equal(t, strings.ReplaceAll(*tc.In.Locale, "_", "-"), u.Locale)
+			}
+			if tc.In.Preferences != nil {
+				assert.Equal(t, tc.In.Preferences, u.Preferences)
+			}
+		})
+	}
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	u := &model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+	}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(u, nil)
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(u, nil)
+	repo.EXPECT().UpdatePassword(mock.Anything, userID.String(), mock.Anything).RunAndReturn(func(ctx context.Context, id string, hashedPassword string) error {
+		u.HashedPassword = hashedPassword
+		return nil
+	})
+
+	memoryAuth := auth.NewMemoryAuth()
+	memoryMailer := mailer.NewMemoryMailer()
+	recorder := audit.NewMemoryRecorder()
+	svc := user.NewService(repo, memoryAuth, tokenManager, auth.NewMockAuthEnforcer(t), &user.ServiceOpts{
+		Mailer:   memoryMailer,
+		Recorder: recorder,
+	})
+
+	sessions := make([]*auth.AccessProperties, 2)
+	for i := range sessions {
+		result, err := svc.Login(ctx, "one@example.com", "password")
+		assert.NoError(t, err)
+
+		sessions[i], err = tokenManager.VerifyAccessToken(result.AccessToken)
+		assert.NoError(t, err)
+	}
+	current, other := sessions[0], sessions[1]
+
+	assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(svc.ChangePassword(ctx, current, "wrong", "Correct-Horse-Battery-42")))
+	assert.Equal(t, http.StatusBadRequest, errs.StatusOf(svc.ChangePassword(ctx, current, "password", "password")), "weak passwords must be rejected")
+	assert.NoError(t, svc.ChangePassword(ctx, current, "password", "Correct-Horse-Battery-42"))
+
+	_, err := memoryAuth.FetchAuth(ctx, current.TokenUUID)
+	assert.NoError(t, err, "the current session must stay signed in")
+
+	_, err = memoryAuth.FetchAuth(ctx, other.TokenUUID)
+	assert.Error(t, err, "other sessions must be signed out")
+
+	assert.Equal(t, "one@example.com", memoryMailer.Last().To)
+
+	_, err = svc.Login(ctx, "one@example.com", "Correct-Horse-Battery-42")
+	assert.NoError(t, err)
+
+	var outcomes []string
+	for _, event := range recorder.Events() {
+		if event.Type == audit.PasswordChange {
+			outcomes = append(outcomes, event.Outcome)
+		}
+	}
+	assert.Equal(t, []string{audit.Failure, audit.Success}, outcomes)
+}
+
+func TestService_ChangeEmail(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	u := &model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+	}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(u, nil)
+	repo.EXPECT().GetByEmail(mock.Anything, "taken@example.com").Return(&model.User{ID: uuid.New(), Email: "taken@example.com"}, nil)
+	repo.EXPECT().GetByEmail(mock.Anything, "two@example.com").Return(nil, errs.FromGorm(gorm.ErrRecordNotFound))
+	repo.EXPECT().SetPendingEmail(mock.Anything, userID.String(), "two@example.com").RunAndReturn(func(ctx context.Context, id string, email string) error {
+		u.PendingEmail = email
+		return nil
+	}).Once()
+	repo.EXPECT().UpdateEmail(mock.Anything, userID.String(), "two@example.com").RunAndReturn(func(ctx context.Context, id string, email string) error {
+		u.Email, u.PendingEmail = email, ""
+		return nil
+	}).Once()
+
+	memoryMailer := mailer.NewMemoryMailer()
+	svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, auth.NewMockAuthEnforcer(t), &user.ServiceOpts{
+		Mailer: memoryMailer,
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(svc.ChangeEmail(ctx, userID.String(), "wrong", "two@example.com")))
+	assert.Equal(t, http.StatusBadRequest, errs.StatusOf(svc.ChangeEmail(ctx, userID.String(), "password", "one@example.com")))
+	assert.Equal(t, http.StatusConflict, errs.StatusOf(svc.ChangeEmail(ctx, userID.String(), "password", "taken@example.com")))
+	assert.Empty(t, memoryMailer.Messages())
+
+	assert.NoError(t, svc.ChangeEmail(ctx, userID.String(), "password", "two@example.com"))
+	assert.Equal(t, "one@example.com", u.Email, "email must not change before it is confirmed")
+
+	messages := memoryMailer.Messages()
+	if !assert.Len(t, messages, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "two@example.com", messages[0].To)
+	assert.Equal(t, "one@example.com", messages[1].To, "the old address must be notified")
+
+	token := tokenFromMessage(t, &messages[0])
+	assert.Equal(t, http.StatusBadRequest, errs.StatusOf(svc.ConfirmEmailChange(ctx, token+"x")))
+	assert.NoError(t, svc.ConfirmEmailChange(ctx, token))
+	assert.Equal(t, "two@example.com", u.Email)
+	assert.Error(t, svc.ConfirmEmailChange(ctx, token), "token must be single-use")
+}
+
+func TestService_DeleteAccount(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	clientID := uuid.NewString()
+	u := &model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+	}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(u, nil)
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(u, nil)
+	repo.EXPECT().UpdatePassword(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.EXPECT().ListAPIKeyIDs(mock.Anything, userID.String()).Return([]string{"key-1"}, nil)
+	repo.EXPECT().ListOAuthClientIDs(mock.Anything, userID.String()).Return([]string{clientID}, nil)
+	// The first attempt fails to delete the user, so nothing may be revoked yet.
+	repo.EXPECT().Delete(mock.Anything, userID.String()).Return(errs.New(http.StatusInternalServerError, errors.New("connection reset"), "failed")).Once()
+	repo.EXPECT().Delete(mock.Anything, userID.String()).Return(nil).Once()
+
+	keyPolicy := auth.Policy{Subject: auth.APIKeySubject("key-1"), Object: auth.Resource, Action: auth.Read}
+	clientPolicy := auth.Policy{Subject: auth.ClientSubject(clientID), Object: auth.Resource, Action: auth.Read}
+	enforcer := auth.NewMockAuthEnforcer(t)
+	enforcer.EXPECT().GetRolesForUser(userID.String()).Return([]auth.Role{auth.Customer, auth.Editor}, nil).Once()
+	enforcer.EXPECT().DeleteRoleForUser(mock.Anything, userID.String(), auth.Customer).Return(nil).Once()
+	enforcer.EXPECT().DeleteRoleForUser(mock.Anything, userID.String(), auth.Editor).Return(nil).Once()
+	enforcer.EXPECT().GetPolicies(auth.Policy{Subject: userID.String()}).Return(nil, nil).Once()
+	enforcer.EXPECT().GetPolicies(auth.Policy{Subject: keyPolicy.Subject}).Return([]auth.Policy{keyPolicy}, nil).Once()
+	enforcer.EXPECT().RemovePolicy(mock.Anything, keyPolicy.Subject, keyPolicy.Object, keyPolicy.Action).Return(nil).Once()
+	enforcer.EXPECT().GetPolicies(auth.Policy{Subject: clientPolicy.Subject}).Return([]auth.Policy{clientPolicy}, nil).Once()
+	enforcer.EXPECT().RemovePolicy(mock.Anything, clientPolicy.Subject, clientPolicy.Object, clientPolicy.Action).Return(nil).Once()
+
+	memoryAuth := auth.NewMemoryAuth()
+
+	// The user's client holds a token of its own and one issued on behalf of another user.
+	scopes := []auth.Scope{auth.NewScope(auth.Resource, auth.Read)}
+	own, err := tokenManager.CreateClientToken(clientPolicy.Subject, "", clientID, scopes, false)
+	assert.NoError(t, err)
+	assert.NoError(t, memoryAuth.CreateAuth(ctx, clientPolicy.Subject, own))
+
+	delegated, err := tokenManager.CreateClientToken("123e4567-e89b-12d3-a456-426614174002", "two@example.com", clientID, scopes, true)
+	assert.NoError(t, err)
+	assert.NoError(t, memoryAuth.CreateAuth(ctx, "123e4567-e89b-12d3-a456-426614174002", delegated))
+	assert.NoError(t, memoryAuth.LinkTokens(ctx, clientPolicy.Subject, delegated))
+	recorder := audit.NewMemoryRecorder()
+	svc := user.NewService(repo, memoryAuth, tokenManager, enforcer, &user.ServiceOpts{Recorder: recorder})
+
+	result, err := svc.Login(ctx, "one@example.com", "password")
+	assert.NoError(t, err)
+
+	metadata, err := tokenManager.VerifyAccessToken(result.AccessToken)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(svc.DeleteAccount(ctx, metadata, "wrong")))
+	assert.Equal(t, http.StatusInternalServerError, errs.StatusOf(svc.DeleteAccount(ctx, metadata, "password")))
+	enforcer.AssertNotCalled(t, "DeleteRoleForUser", mock.Anything, mock.Anything, mock.Anything)
+
+	assert.NoError(t, svc.DeleteAccount(ctx, metadata, "password"))
+
+	_, err = memoryAuth.FetchAuth(ctx, metadata.TokenUUID)
+	assert.Error(t, err, "sessions of a deleted account must be revoked")
+
+	for _, tokenUUID := range []string{own.AccessTokenUUID, delegated.AccessTokenUUID, delegated.RefreshTokenUUID} {
+		_, err = memoryAuth.FetchAuth(ctx, tokenUUID)
+		assert.Error(t, err, "tokens of the deleted account's clients must be revoked")
+	}
+
+	for _, event := range recorder.Events() {
+		if event.Type == audit.AccountDelete {
+			assert.NotContains(t, event.Target+event.Details, "one@example.com", "deletion events must not keep the email")
+		}
+	}
+}
+
+func TestService_ListUsers(t *testing.T) {
+	type Testcase struct {
+		Name       string
+		In         *user.UserFilter
+		WantLimit  int
+		WantStatus int
+	}
+
+	testcases := []Testcase{
+		{
+			Name:      "default-limit",
+			In:        &user.UserFilter{Query: " one "},
+			WantLimit: user.DefaultPageSize,
+		},
+		{
+			Name:      "by-status",
+			In:        &user.UserFilter{Status: model.UserDisabled, Limit: 5, Offset: 10},
+			WantLimit: 5,
+		},
+		{
+			Name:       "unknown-status",
+			In:         &user.UserFilter{Status: "banned"},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "limit-too-large",
+			In:         &user.UserFilter{Limit: user.MaxPageSize + 1},
+			WantStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "negative-offset",
+			In:         &user.UserFilter{Offset: -1},
+			WantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			repo := user.NewMockRepository(t)
+			repo.EXPECT().List(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, filter *user.UserFilter) ([]model.User, int64, error) {
+				assert.Equal(t, strings.TrimSpace(filter.Query), filter.Query)
+				return []model.User{{ID: uuid.New(), Email: "one@example.com"}}, 42, nil
+			}).Maybe()
+
+			svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, auth.NewMockAuthEnforcer(t))
+			users, total, err := svc.ListUsers(context.Background(), tc.In)
+
+			if tc.WantStatus != 0 {
+				assert.Equal(t, tc.WantStatus, errs.StatusOf(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, users, 1)
+			assert.Equal(t, int64(42), total)
+			assert.Equal(t, tc.WantLimit, tc.In.Limit)
+		})
+	}
+}
+
+func TestService_DisableUser(t *testing.T) {
+	ctx := context.Background()
+	adminID := uuid.New().String()
+	clientID := uuid.NewString()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	u := &model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+		Status:         model.UserActive,
+	}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(u, nil)
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(u, nil)
+	repo.EXPECT().UpdatePassword(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.EXPECT().UpdateStatus(mock.Anything, userID.String(), mock.Anything).RunAndReturn(func(ctx context.Context, id string, status model.UserStatus) error {
+		u.Status = status
+		return nil
+	})
+	repo.EXPECT().ListOAuthClientIDs(mock.Anything, userID.String()).Return([]string{clientID}, nil)
+
+	memoryAuth := auth.NewMemoryAuth()
+	svc := user.NewService(repo, memoryAuth, tokenManager, auth.NewMockAuthEnforcer(t))
+
+	// The user's client holds a token of its own.
+	clientToken, err := tokenManager.CreateClientToken(auth.ClientSubject(clientID), "", clientID, []auth.Scope{auth.NewScope(auth.Resource, auth.Read)}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, memoryAuth.CreateAuth(ctx, auth.ClientSubject(clientID), clientToken))
+
+	result, err := svc.Login(ctx, "one@example.com", "password")
+	assert.NoError(t, err)
+
+	metadata, err := tokenManager.VerifyAccessToken(result.AccessToken)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusConflict, errs.StatusOf(svc.DisableUser(ctx, userID.String(), userID.String())), "admins must not disable themselves")
+	assert.Equal(t, http.StatusNotFound, errs.StatusOf(svc.DisableUser(ctx, adminID, "not-a-uuid")))
+	assert.NoError(t, svc.DisableUser(ctx, adminID, userID.String()))
+
+	_, err = memoryAuth.FetchAuth(ctx, metadata.TokenUUID)
+	assert.Error(t, err, "sessions of a disabled user must be revoked")
+
+	_, err = memoryAuth.FetchAuth(ctx, clientToken.AccessTokenUUID)
+	assert.Error(t, err, "tokens of a disabled user's clients must be revoked")
+
+	_, err = svc.Login(ctx, "one@example.com", "password")
+	assert.Equal(t, http.StatusForbidden, errs.StatusOf(err))
+
+	assert.NoError(t, svc.EnableUser(ctx, userID.String()))
+	_, err = svc.Login(ctx, "one@example.com", "password")
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.DisableUser(ctx, adminID, userID.String()))
+	_, err = svc.Login(ctx, "one@example.com", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(err), "a disabled account must not be revealed without the password")
+}
+
+func TestService_RefreshToken_Disabled(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(&model.User{
+		ID:     userID,
+		Email:  "one@example.com",
+		Status: model.UserDisabled,
+	}, nil)
+
+	memoryAuth := auth.NewMemoryAuth()
+	ts, err := tokenManager.CreateToken(userID.String(), "one@example.com")
+	assert.NoError(t, err)
+	assert.NoError(t, memoryAuth.CreateAuth(ctx, userID.String(), ts))
+
+	svc := user.NewService(repo, memoryAuth, tokenManager, auth.NewMockAuthEnforcer(t))
+	_, _, err = svc.RefreshToken(ctx, ts.RefreshToken)
+	assert.Equal(t, http.StatusForbidden, errs.StatusOf(err))
+}
+
+func TestService_RefreshToken(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(&model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+	}, nil)
+	repo.EXPECT().UpdatePassword(mock.Anything, userID.String(), mock.Anything).Return(nil).Maybe()
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(&model.User{ID: userID, Email: "one@example.com"}, nil)
+
+	memoryAuth := auth.NewMemoryAuth()
+	svc := user.NewService(repo, memoryAuth, tokenManager, auth.NewMockAuthEnforcer(t))
+
+	router := gin.New()
+	router.GET("/me", middleware.AuthMiddleware(tokenManager, &middleware.AuthOpts{Tokens: memoryAuth}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	get := func(accessToken string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	login, err := svc.Login(ctx, "one@example.com", "password")
+	assert.NoError(t, err)
+
+	accessToken, refreshToken, err := svc.RefreshToken(ctx, login.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, get(accessToken), "the refreshed access token must be accepted")
+
+	_, _, err = svc.RefreshToken(ctx, login.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(err), "a refresh token must not be used twice")
+
+	_, _, err = svc.RefreshToken(ctx, accessToken)
+	assert.Equal(t, http.StatusUnauthorized, errs.StatusOf(err), "an access token must not refresh")
+
+	_, _, err = svc.RefreshToken(ctx, refreshToken)
+	assert.NoError(t, err)
+}
+
+func TestService_ForcePasswordReset(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	u := &model.User{
+		ID:             userID,
+		Email:          "one@example.com",
+		HashedPassword: "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+		Status:         model.UserActive,
+	}
+
+	repo := user.NewMockRepository(t)
+	repo.EXPECT().GetByEmail(mock.Anything, "one@example.com").Return(u, nil)
+	repo.EXPECT().GetByID(mock.Anything, userID.String()).Return(u, nil)
+	repo.EXPECT().UpdatePassword(mock.Anything, userID.String(), mock.Anything).RunAndReturn(func(ctx context.Context, id string, hashedPassword string) error {
+		u.HashedPassword = hashedPassword
+		return nil
+	})
+	repo.EXPECT().SetPasswordResetRequired(mock.Anything, userID.String(), mock.Anything).RunAndReturn(func(ctx context.Context, id string, required bool) error {
+		u.PasswordResetRequired = required
+		return nil
+	}).Times(2)
+
+	memoryMailer := mailer.NewMemoryMailer()
+	recorder := audit.NewMemoryRecorder()
+	svc := user.NewService(repo, auth.NewMemoryAuth(), tokenManager, auth.NewMockAuthEnforcer(t), &user.ServiceOpts{
+		Mailer:   memoryMailer,
+		Recorder: recorder,
+	})
+
+	assert.NoError(t, svc.ForcePasswordReset(ctx, userID.String()))
+	token := tokenFromMessage(t, memoryMailer.Last())
+
+	_, err := svc.Login(ctx, "one@example.com", "password")
+	assert.Equal(t, http.StatusForbidden, errs.StatusOf(err), "password logins must be refused until the reset")
+
+	assert.NoError(t, svc.ResetPassword(ctx, token, "Correct-Horse-Battery-42"))
+	assert.False(t, u.PasswordResetRequired)
+
+	_, err = svc.Login(ctx, "one@example.com", "Correct-Horse-Battery-42")
+	assert.NoError(t, err)
+	assert.Equal(t, audit.PasswordForce, recorder.Events()[0].Type)
+}
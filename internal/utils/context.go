@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
+	errs "github.com/chai-rs/simple-bookstore/internal/error"
+)
+
+// ClientInfo describes the client that issued a request and the ID the request is tracked by.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo returns a copy of ctx carrying the given client information.
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// ClientInfoFromContext returns the client information stored in ctx, if any.
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request being handled.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// AccessProperties returns the caller the auth middleware authenticated the request as, or an
+// unauthorized error when the request went through no auth middleware.
+func AccessProperties(ctx context.Context) (*auth.AccessProperties, error) {
+	properties, ok := auth.AccessPropertiesFromContext(ctx)
+	if !ok {
+		return nil, errs.New(http.StatusUnauthorized, errors.New("request is not authenticated"), "unauthorized")
+	}
+
+	return properties, nil
+}
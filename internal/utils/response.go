@@ -3,6 +3,7 @@ package utils
 import (
 	"net/http"
 
+	"github.com/chai-rs/simple-bookstore/infrastructure/tracing"
 	errs "github.com/chai-rs/simple-bookstore/internal/error"
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +13,8 @@ type Response struct {
 	Success bool `json:"success"`
 	Error   any  `json:"error,omitempty"`
 	Result  any  `json:"result,omitempty"`
+	// TraceID identifies the trace of a failed request, to find it in the tracing backend and logs.
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 // ResponseOk sends a success response.
@@ -32,7 +35,7 @@ func ResponseCreated(c *gin.Context, data any) {
 
 // ResponseErrorWithStatus sends an error response with a specific status.
 func ResponseErrorWithStatus(c *gin.Context, status int, errorMessage string) {
-	c.JSON(status, Response{Success: false, Error: errorMessage})
+	c.JSON(status, Response{Success: false, Error: errorMessage, TraceID: tracing.TraceID(c.Request.Context())})
 }
 
 // ResponseError sends an error response.
@@ -1,22 +1,80 @@
 package crypto
 
 import (
-	"golang.org/x/crypto/bcrypt"
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// HashPassword hashes a password.
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+// Hasher defines methods for hashing and verifying passwords.
+type Hasher interface {
+	// Hash hashes a password into an encoded string.
+	Hash(password string) (string, error)
+	// Verify compares a password with an encoded hash produced by this hasher's algorithm.
+	Verify(password, encoded string) (bool, error)
+	// Supports reports whether the encoded hash uses this hasher's algorithm.
+	Supports(encoded string) bool
+	// Current reports whether the encoded hash uses this hasher's algorithm and parameters.
+	Current(encoded string) bool
+}
+
+// tracer returns the tracer of password hashing, which is deliberately slow.
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/chai-rs/simple-bookstore/pkg/crypto")
+}
+
+// hashers are the known hashers, able to verify hashes of their algorithm whatever their parameters.
+var hashers = []Hasher{NewArgon2idHasher(), NewBcryptHasher()}
+
+// NewHasher creates a hasher for the given algorithm name, defaulting to Argon2id when empty.
+func NewHasher(algorithm string, argon2Params *Argon2idParams, bcryptCost int) (Hasher, error) {
+	switch algorithm {
+	case "", Argon2id:
+		return NewArgon2idHasher(argon2Params), nil
+	case Bcrypt:
+		return NewBcryptHasher(bcryptCost), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", algorithm)
+	}
+}
+
+// PasswordHasher hashes new passwords with one Hasher and verifies hashes made by any known hasher.
+// Hashes produced by any other algorithm or parameters are reported as needing a rehash.
+type PasswordHasher struct {
+	hasher Hasher
+}
+
+// NewPasswordHasher creates a PasswordHasher hashing new passwords with hasher, Argon2id with the
+// default parameters when nil.
+func NewPasswordHasher(hasher Hasher) *PasswordHasher {
+	if hasher == nil {
+		hasher = hashers[0]
 	}
 
-	return string(hashedPassword), nil
+	return &PasswordHasher{hasher}
 }
 
-// MustHashPassword hashes a password and panics if it fails.
-func MustHashPassword(password string) string {
-	hashedPassword, err := HashPassword(password)
+// Hash hashes a password, tracing the hashing as a span of ctx, since it is deliberately among the
+// slowest steps of a request.
+func (p *PasswordHasher) Hash(ctx context.Context, password string) (string, error) {
+	_, span := tracer().Start(ctx, "crypto.HashPassword", trace.WithAttributes(attribute.String("password.algorithm", algorithmOf(p.hasher))))
+	defer span.End()
+
+	return p.hasher.Hash(password)
+}
+
+// MustHash hashes a password and panics if it fails.
+func (p *PasswordHasher) MustHash(password string) string {
+	hashedPassword, err := p.hasher.Hash(password)
 	if err != nil {
 		panic(err)
 	}
@@ -24,8 +82,72 @@ func MustHashPassword(password string) string {
 	return hashedPassword
 }
 
-// ComparePassword compares a password with a hashed password.
-func ComparePassword(password, hashedPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
+// Compare compares a password with a hashed password, tracing the comparison as a span of ctx. It also
+// reports whether the hash was produced with an outdated algorithm or parameters and should be rehashed.
+func (p *PasswordHasher) Compare(ctx context.Context, password, hashedPassword string) (bool, bool) {
+	hasher := p.hasher
+	if !hasher.Supports(hashedPassword) {
+		hasher = findHasher(hashedPassword)
+	}
+
+	if hasher == nil {
+		return false, false
+	}
+
+	_, span := tracer().Start(ctx, "crypto.ComparePassword", trace.WithAttributes(attribute.String("password.algorithm", algorithmOf(hasher))))
+	defer span.End()
+
+	ok, err := hasher.Verify(password, hashedPassword)
+	if err != nil || !ok {
+		return false, false
+	}
+
+	return true, !p.hasher.Current(hashedPassword)
+}
+
+// defaultPasswordHasher backs the package-level helpers, hashing with Argon2id and the default parameters.
+var defaultPasswordHasher = NewPasswordHasher(nil)
+
+// HashPassword hashes a password with Argon2id and the default parameters.
+func HashPassword(password string) (string, error) {
+	return defaultPasswordHasher.hasher.Hash(password)
+}
+
+// MustHashPassword hashes a password like HashPassword and panics if it fails.
+func MustHashPassword(password string) string {
+	return defaultPasswordHasher.MustHash(password)
+}
+
+// ComparePassword compares a password with a hashed password, reporting hashes other than Argon2id
+// with the default parameters as needing a rehash.
+func ComparePassword(password, hashedPassword string) (bool, bool) {
+	return defaultPasswordHasher.Compare(context.Background(), password, hashedPassword)
+}
+
+// algorithmOf names the algorithm of hasher for traces, or returns "unknown".
+func algorithmOf(hasher Hasher) string {
+	switch hasher.(type) {
+	case *Argon2idHasher:
+		return Argon2id
+	case *BcryptHasher:
+		return Bcrypt
+	default:
+		return "unknown"
+	}
+}
+
+// findHasher returns the known hasher able to verify the encoded hash.
+func findHasher(encoded string) Hasher {
+	for _, hasher := range hashers {
+		if hasher.Supports(encoded) {
+			return hasher
+		}
+	}
+
+	return nil
+}
+
+// phcFields splits a PHC string ($id$v=..$params$salt$hash) into its fields.
+func phcFields(encoded string) []string {
+	return strings.Split(strings.TrimPrefix(encoded, "$"), "$")
 }
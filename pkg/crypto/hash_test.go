@@ -0,0 +1,104 @@
+package crypto_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComparePassword(t *testing.T) {
+	type TestcaseWant struct {
+		Ok          bool
+		NeedsRehash bool
+	}
+
+	type Testcase struct {
+		Name     string
+		Password string
+		Hash     string
+		Want     TestcaseWant
+	}
+
+	lowMemory := crypto.NewArgon2idHasher(&crypto.Argon2idParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+
+	testcases := []Testcase{
+		{
+			Name:     "argon2id-current",
+			Password: "password",
+			Hash:     crypto.MustHashPassword("password"),
+			Want:     TestcaseWant{Ok: true},
+		},
+		{
+			Name:     "argon2id-outdated-params",
+			Password: "password",
+			Hash:     mustHash(t, lowMemory, "password"),
+			Want:     TestcaseWant{Ok: true, NeedsRehash: true},
+		},
+		{
+			Name:     "bcrypt-legacy",
+			Password: "password",
+			Hash:     "$2a$10$oiLJvjZFetwKPC5Gr9lBjuWuNdCYxorIsGJlSZtuhlnKmm4FxAoV6",
+			Want:     TestcaseWant{Ok: true, NeedsRehash: true},
+		},
+		{
+			Name:     "wrong-password",
+			Password: "wrong-password",
+			Hash:     crypto.MustHashPassword("password"),
+		},
+		{
+			Name:     "unknown-format",
+			Password: "password",
+			Hash:     "$md5$password",
+		},
+		{
+			Name:     "malformed-argon2id",
+			Password: "password",
+			Hash:     "$argon2id$v=19$m=abc$salt$key",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, needsRehash := crypto.ComparePassword(tc.Password, tc.Hash)
+			assert.Equal(t, tc.Want.Ok, ok)
+			assert.Equal(t, tc.Want.NeedsRehash, needsRehash)
+		})
+	}
+}
+
+func TestPasswordHasher(t *testing.T) {
+	ctx := context.Background()
+
+	argon2Hash := crypto.MustHashPassword("password")
+	assert.True(t, strings.HasPrefix(argon2Hash, "$argon2id$v=19$m=19456,t=2,p=1$"))
+
+	hasher, err := crypto.NewHasher(crypto.Bcrypt, nil, 4)
+	assert.NoError(t, err)
+	passwords := crypto.NewPasswordHasher(hasher)
+
+	bcryptHash, err := passwords.Hash(ctx, "password")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(bcryptHash, "$2a$04$"))
+
+	ok, needsRehash := passwords.Compare(ctx, "password", argon2Hash)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+
+	ok, needsRehash = passwords.Compare(ctx, "password", bcryptHash)
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+
+	_, err = crypto.NewHasher("scrypt", nil, 0)
+	assert.Error(t, err)
+}
+
+func mustHash(t *testing.T, hasher crypto.Hasher, password string) string {
+	t.Helper()
+
+	hash, err := hasher.Hash(password)
+	assert.NoError(t, err)
+	return hash
+}
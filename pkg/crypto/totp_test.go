@@ -0,0 +1,63 @@
+package crypto_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chai-rs/simple-bookstore/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// secret is the RFC 6238 SHA1 test seed "12345678901234567890" in base32.
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTP(t *testing.T) {
+	type Testcase struct {
+		Name string
+		In   int64
+		Want string
+	}
+
+	// Expected values are the last 6 digits of the RFC 6238 appendix B vectors.
+	testcases := []Testcase{
+		{Name: "59", In: 59, Want: "287082"},
+		{Name: "1111111109", In: 1111111109, Want: "081804"},
+		{Name: "1111111111", In: 1111111111, Want: "050471"},
+		{Name: "1234567890", In: 1234567890, Want: "005924"},
+		{Name: "2000000000", In: 2000000000, Want: "279037"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			code, err := crypto.GenerateTOTP(secret, time.Unix(tc.In, 0))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Want, code)
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	assert.True(t, crypto.ValidateTOTP(secret, "050471", now))
+	assert.True(t, crypto.ValidateTOTP(secret, "050471", now.Add(crypto.TOTPPeriod*time.Second)))
+	assert.False(t, crypto.ValidateTOTP(secret, "050471", now.Add(3*crypto.TOTPPeriod*time.Second)))
+	assert.False(t, crypto.ValidateTOTP(secret, "000000", now))
+	assert.False(t, crypto.ValidateTOTP("not-base32!", "050471", now))
+}
+
+func TestMatchTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	step, ok := crypto.MatchTOTP(secret, "050471", now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1111111111/crypto.TOTPPeriod), step)
+
+	// The same code matched one step later still reports the step it was generated for.
+	later, ok := crypto.MatchTOTP(secret, "050471", now.Add(crypto.TOTPPeriod*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, step, later)
+
+	_, ok = crypto.MatchTOTP(secret, "000000", now)
+	assert.False(t, ok)
+}
@@ -1,5 +1,12 @@
 package migration
 
+import (
+	"errors"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4/source/file"
+)
+
 // DatabaseMigration is the interface for database migrations.
 type DatabaseMigration interface {
 	Up() error
@@ -11,3 +18,28 @@ type Input interface {
 	URL() string
 	MigrationFile() string
 }
+
+// LatestVersion returns the version of the newest migration in dir, which a fully migrated database is at.
+func LatestVersion(dir string) (uint, error) {
+	source, err := (&file.File{}).Open("file://" + dir)
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
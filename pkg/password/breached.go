@@ -0,0 +1,83 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefixLength is the number of hex characters used to bucket hashes, as in the k-anonymity range API of Have I Been Pwned.
+const prefixLength = 5
+
+// BreachedList is an in-memory set of breached password SHA-1 hashes grouped by hash prefix.
+type BreachedList struct {
+	buckets map[string]map[string]struct{}
+	size    int
+}
+
+// LoadBreachedList loads a breached password file. Each line holds an upper or lower
+// case hex SHA-1 hash, optionally followed by ":count" as in the Have I Been Pwned dumps.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &BreachedList{buckets: map[string]map[string]struct{}{}}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(text, ":")
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("invalid hash on line %d", line)
+		}
+
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("invalid hash on line %d: %w", line, err)
+		}
+
+		list.add(strings.ToUpper(hash))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Contains reports whether the password's hash is in the list.
+func (l *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, ok := l.buckets[hash[:prefixLength]][hash[prefixLength:]]
+	return ok
+}
+
+// Len returns the number of hashes in the list.
+func (l *BreachedList) Len() int {
+	return l.size
+}
+
+func (l *BreachedList) add(hash string) {
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+	bucket, ok := l.buckets[prefix]
+	if !ok {
+		bucket = map[string]struct{}{}
+		l.buckets[prefix] = bucket
+	}
+
+	if _, ok := bucket[suffix]; !ok {
+		bucket[suffix] = struct{}{}
+		l.size++
+	}
+}
@@ -0,0 +1,120 @@
+package password
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ccojocar/zxcvbn-go"
+)
+
+// Policy describes the rules a password must satisfy.
+type Policy struct {
+	// MinLength is the minimum number of characters.
+	MinLength int
+	// MaxLength is the maximum number of characters.
+	MaxLength int
+	// MaxBytes is the maximum length in bytes, for hashes with a byte limit such as bcrypt's 72.
+	// Characters outside ASCII take several bytes, so it can be reached before MaxLength. 0 means no limit.
+	MaxBytes int
+	// MinClasses is the minimum number of character classes (lower, upper, digit, symbol).
+	MinClasses int
+	// MinScore is the minimum zxcvbn strength score, from 0 (weakest) to 4 (strongest).
+	MinScore int
+	// Breached optionally rejects passwords found in a list of breached passwords.
+	Breached Checker
+}
+
+// DefaultPolicy provides the default password policy.
+var DefaultPolicy = &Policy{
+	MinLength:  8,
+	MaxLength:  72,
+	MinClasses: 2,
+	MinScore:   2,
+}
+
+// Checker reports whether a password is known to be compromised.
+type Checker interface {
+	Contains(password string) bool
+}
+
+// PolicyError lists every rule a password violates.
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return "password " + strings.Join(e.Violations, ", ")
+}
+
+// Validate checks a password against the policy. The email is used to reject
+// passwords that equal or are derived from the account's email address.
+func (p *Policy) Validate(password, email string) error {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, "must be at least "+strconv.Itoa(p.MinLength)+" characters")
+	}
+
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, "must be at most "+strconv.Itoa(p.MaxLength)+" characters")
+	}
+
+	if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		violations = append(violations, "must be at most "+strconv.Itoa(p.MaxBytes)+" bytes, accented letters and symbols taking several")
+	}
+
+	if classes(password) < p.MinClasses {
+		violations = append(violations, "must mix at least "+strconv.Itoa(p.MinClasses)+" of lowercase, uppercase, digits and symbols")
+	}
+
+	if email != "" && strings.EqualFold(password, email) {
+		violations = append(violations, "must not be the same as the email")
+	}
+
+	if p.MinScore > 0 && Score(password, email) < p.MinScore {
+		violations = append(violations, "is too easy to guess")
+	}
+
+	if p.Breached != nil && p.Breached.Contains(password) {
+		violations = append(violations, "has appeared in a data breach")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{violations}
+	}
+
+	return nil
+}
+
+// Score estimates password strength from 0 to 4 using zxcvbn, penalising reuse of the email.
+func Score(password, email string) int {
+	var userInputs []string
+	if email != "" {
+		local, domain, _ := strings.Cut(email, "@")
+		userInputs = append(userInputs, email, local, domain)
+	}
+
+	return zxcvbn.PasswordStrength(password, userInputs).Score
+}
+
+// classes counts the character classes used by a password.
+func classes(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+
+	return lower + upper + digit + symbol
+}
@@ -0,0 +1,96 @@
+package password_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chai-rs/simple-bookstore/pkg/password"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	type TestcaseIn struct {
+		Password string
+		Email    string
+	}
+
+	type Testcase struct {
+		Name      string
+		In        TestcaseIn
+		WantError bool
+	}
+
+	testcases := []Testcase{
+		{
+			Name: "success",
+			In:   TestcaseIn{Password: "Correct-Horse-Battery-42", Email: "one@example.com"},
+		},
+		{
+			Name:      "too-short",
+			In:        TestcaseIn{Password: "aB3$", Email: "one@example.com"},
+			WantError: true,
+		},
+		{
+			Name:      "single-class",
+			In:        TestcaseIn{Password: "correcthorsebatterystaple", Email: "one@example.com"},
+			WantError: true,
+		},
+		{
+			Name:      "common",
+			In:        TestcaseIn{Password: "Password1", Email: "one@example.com"},
+			WantError: true,
+		},
+		{
+			Name:      "same-as-email",
+			In:        TestcaseIn{Password: "Long.Email-42@example.com", Email: "long.email-42@example.com"},
+			WantError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := password.DefaultPolicy.Validate(tc.In.Password, tc.In.Email)
+			if tc.WantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Validate_MaxBytes(t *testing.T) {
+	policy := *password.DefaultPolicy
+	policy.MaxBytes = 72
+
+	// 59 characters, but 76 bytes: within MaxLength, past what bcrypt hashes.
+	long := "Été-à-Genève-où-l'Œuvre-déçoit-Noël-Ærø-Ünïcödé-Pässwörd-42"
+	assert.NoError(t, password.DefaultPolicy.Validate(long, "one@example.com"))
+	assert.ErrorContains(t, policy.Validate(long, "one@example.com"), "must be at most 72 bytes")
+	assert.NoError(t, policy.Validate("Correct-Horse-Battery-42", "one@example.com"))
+}
+
+func TestBreachedList(t *testing.T) {
+	sum := sha1.Sum([]byte("Correct-Horse-Battery-42"))
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := "# breached hashes\n" + strings.ToUpper(hex.EncodeToString(sum[:])) + ":12\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	breached, err := password.LoadBreachedList(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, breached.Len())
+	assert.True(t, breached.Contains("Correct-Horse-Battery-42"))
+	assert.False(t, breached.Contains("Another-Horse-Battery-42"))
+
+	policy := *password.DefaultPolicy
+	policy.Breached = breached
+	assert.Error(t, policy.Validate("Correct-Horse-Battery-42", "one@example.com"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("not-a-hash\n"), 0o600))
+	_, err = password.LoadBreachedList(path)
+	assert.Error(t, err)
+}
@@ -1,7 +1,7 @@
 package test
 
 import (
-	"github.com/chai-rs/simple-bookstore/config"
+	"github.com/chai-rs/simple-bookstore/infrastructure/auth"
 	"github.com/chai-rs/simple-bookstore/infrastructure/docker"
 	"github.com/ory/dockertest/v3"
 	"github.com/rs/zerolog/log"
@@ -40,7 +40,6 @@ func (s *BaseSuite) SetupSuite() {
 		s.T().FailNow()
 	}
 
-	setupConfig()
 	// log.Info().Msg("🚀 Before Suite executed")
 }
 
@@ -55,7 +54,5 @@ func (s *BaseSuite) TearDownSuite() {
 	// log.Info().Msg("👋 After Suite executed")
 }
 
-func setupConfig() {
-	config.ACCESS_SECRET = "secret"
-	config.REFRESH_SECRET = "secret"
-}
+// tokenManager signs and verifies the tokens of the suites.
+var tokenManager = auth.NewTokenManager(auth.NewKeys("secret", "secret"))
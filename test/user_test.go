@@ -36,7 +36,6 @@ func (s *UserSuite) BeforeTest(suiteName, testName string) {
 	enforcer := auth.NewAuthEnforcer(auth.GormAdapter(s.db), &auth.AuthEnforcerOpts{
 		ModelPath: "../auth_model.conf",
 	})
-	tokenManager := auth.NewTokenManager()
 	memoryAuth := auth.NewMemoryAuth()
 	s.service = user.NewService(repo, memoryAuth, tokenManager, enforcer)
 	hdl := user.NewHandler(s.service)
@@ -53,7 +52,7 @@ type TestUserRegister_Successful struct{ UserSuite }
 func (s *TestUserRegister_Successful) TestUserRegister_Successful() {
 	body := user.RegisterRequestDTO{
 		Email:    "test@example.com",
-		Password: "password",
+		Password: "Correct-Horse-Battery-42",
 	}
 
 	jsonBody, err := json.Marshal(body)
@@ -77,13 +76,10 @@ func (s *TestUserLogin_Successful) BeforeTest(suiteName, testName string) {
 
 	body := user.RegisterRequestDTO{
 		Email:    "test@example.com",
-		Password: "password",
+		Password: "Correct-Horse-Battery-42",
 	}
 
-	user, err := body.ToUser()
-	assert.NoError(s.T(), err)
-
-	_, _, err = s.service.Register(context.Background(), user)
+	_, _, err := s.service.Register(context.Background(), body.Email, body.Password)
 	assert.NoError(s.T(), err)
 }
 
@@ -91,7 +87,7 @@ func (s *TestUserLogin_Successful) BeforeTest(suiteName, testName string) {
 func (s *TestUserLogin_Successful) TestUserLogin_Successful() {
 	body := user.LoginRequestDTO{
 		Email:    "test@example.com",
-		Password: "password",
+		Password: "Correct-Horse-Battery-42",
 	}
 
 	jsonBody, err := json.Marshal(body)
@@ -119,6 +115,6 @@ func (s *TestUserLogin_Successful) TestUserLogin_Successful() {
 	err = json.Unmarshal(resultBuf, &result)
 	assert.NoError(s.T(), err)
 
-	_, err = auth.VerifyToken(result.AccessToken)
+	_, err = tokenManager.VerifyAccessToken(result.AccessToken)
 	assert.NoError(s.T(), err)
 }